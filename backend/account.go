@@ -20,6 +20,7 @@ type UserAddressRow struct {
 	PostalCode        string
 	Country           string
 	Phone             string
+	VatID             string
 	IsShipping        bool
 	IsShippingDefault bool
 	IsBilling         bool
@@ -72,6 +73,7 @@ func (cfg *apiConfig) handleManageAddressesList(w http.ResponseWriter, r *http.R
 			City:              row.City.String,
 			PostalCode:        row.PostalCode.String,
 			Country:           row.Country,
+			VatID:             row.VatID.String,
 			IsShipping:        row.IsShipping,
 			IsShippingDefault: row.IsShippingDefault,
 			IsBilling:         row.IsBilling,
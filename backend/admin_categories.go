@@ -199,12 +199,21 @@ func (cfg *apiConfig) handleAdminCategoryUpdate(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	// The form has no tax class field, so keep whatever the API set.
+	current, err := cfg.db.GetCategoryById(r.Context(), id)
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusNotFound, "Category not found")
+		log.Printf("failed to get category: %v", err)
+		return
+	}
+
 	_, err = cfg.db.UpdateCategoryById(r.Context(), database.UpdateCategoryByIdParams{
 		ID:          id,
 		Name:        r.FormValue("name"),
 		Slug:        r.FormValue("slug"),
 		Description: sql.NullString{String: r.FormValue("description"), Valid: r.FormValue("description") != ""},
 		ParentID:    parsedParent,
+		TaxClassID:  current.TaxClassID,
 	})
 
 	if err != nil {
@@ -2,11 +2,15 @@ package main
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
 	"github.com/google/uuid"
 )
 
@@ -36,13 +40,38 @@ func (cfg *apiConfig) handleAdminPaymentOptionsList(w http.ResponseWriter, r *ht
 
 type AdminPaymentFormPageData struct {
 	PaymentOption database.PaymentOption
+	Providers     []string
 	IsEdit        bool
 	Breadcrumbs   []Breadcrumb
 }
 
+// parsePaymentProviderForm reads the provider key and JSON config from the form
+// and checks that the provider can be built from them.
+func (cfg *apiConfig) parsePaymentProviderForm(r *http.Request) (string, json.RawMessage, error) {
+	provider := strings.TrimSpace(r.FormValue("provider"))
+	if provider == "" {
+		provider = payments.ManualKey
+	}
+
+	config := json.RawMessage(strings.TrimSpace(r.FormValue("provider_config")))
+	if len(config) == 0 {
+		config = json.RawMessage("{}")
+	}
+	if !json.Valid(config) {
+		return "", nil, fmt.Errorf("provider config must be valid JSON")
+	}
+
+	if _, err := cfg.payments.New(provider, config); err != nil {
+		return "", nil, err
+	}
+
+	return provider, config, nil
+}
+
 func (cfg *apiConfig) handleAdminPaymentOptionsNew(w http.ResponseWriter, r *http.Request) {
 	data := AdminPaymentFormPageData{
-		PaymentOption: database.PaymentOption{},
+		PaymentOption: database.PaymentOption{Provider: payments.ManualKey, ProviderConfig: json.RawMessage("{}")},
+		Providers:     cfg.payments.Keys(),
 		IsEdit:        false,
 		Breadcrumbs: NewBreadcrumbTrail(
 			Breadcrumb{Label: "Payment Options", URL: "/admin/payment"},
@@ -76,14 +105,23 @@ func (cfg *apiConfig) handleAdminPaymentOptionsCreate(w http.ResponseWriter, r *
 		sortOrder = sql.NullInt32{Int32: int32(sortOrderInt64), Valid: true}
 	}
 
-	_, err := cfg.db.CreatePaymentOption(r.Context(), database.CreatePaymentOptionParams{
+	provider, providerConfig, err := cfg.parsePaymentProviderForm(r)
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusBadRequest, "Invalid payment provider settings")
+		log.Printf("invalid payment provider settings: %v", err)
+		return
+	}
+
+	_, err = cfg.db.CreatePaymentOption(r.Context(), database.CreatePaymentOptionParams{
 		Name: name,
 		Description: sql.NullString{
 			String: description,
 			Valid:  description != "",
 		},
-		SortOrder: sortOrder,
-		IsActive:  isActive,
+		SortOrder:      sortOrder,
+		IsActive:       isActive,
+		Provider:       provider,
+		ProviderConfig: providerConfig,
 	})
 
 	if err != nil {
@@ -113,6 +151,7 @@ func (cfg *apiConfig) handleAdminPaymentOptionEdit(w http.ResponseWriter, r *htt
 
 	data := AdminPaymentFormPageData{
 		PaymentOption: paymentOption,
+		Providers:     cfg.payments.Keys(),
 		IsEdit:        true,
 		Breadcrumbs: NewBreadcrumbTrail(
 			Breadcrumb{Label: "Payment Options", URL: "/admin/payment"},
@@ -153,6 +192,13 @@ func (cfg *apiConfig) handleAdminPaymentOptionUpdate(w http.ResponseWriter, r *h
 		sortOrder = sql.NullInt32{Int32: int32(sortOrderInt64), Valid: true}
 	}
 
+	provider, providerConfig, err := cfg.parsePaymentProviderForm(r)
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusBadRequest, "Invalid payment provider settings")
+		log.Printf("invalid payment provider settings: %v", err)
+		return
+	}
+
 	err = cfg.db.UpdatePaymentOption(r.Context(), database.UpdatePaymentOptionParams{
 		ID:   id,
 		Name: name,
@@ -160,8 +206,10 @@ func (cfg *apiConfig) handleAdminPaymentOptionUpdate(w http.ResponseWriter, r *h
 			String: description,
 			Valid:  description != "",
 		},
-		SortOrder: sortOrder,
-		IsActive:  isActive,
+		SortOrder:      sortOrder,
+		IsActive:       isActive,
+		Provider:       provider,
+		ProviderConfig: providerConfig,
 	})
 
 	if err != nil {
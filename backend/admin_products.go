@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -116,7 +117,7 @@ func (cfg *apiConfig) handleAdminProductCreate(w http.ResponseWriter, r *http.Re
 	}
 
 	variantName := r.FormValue("variant_name")
-	variantPrice, err := strconv.Atoi(r.FormValue("variant_price"))
+	variantPrice, err := money.Parse(r.FormValue("variant_price"))
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusInternalServerError, "Error converting price")
 	}
@@ -133,7 +134,7 @@ func (cfg *apiConfig) handleAdminProductCreate(w http.ResponseWriter, r *http.Re
 		ProductID:     product.ID,
 		Name:          sql.NullString{String: variantName, Valid: variantName != ""},
 		Sku:           variantSku,
-		Price:         float64(variantPrice),
+		Price:         variantPrice,
 		StockQuantity: int32(variantStock64),
 		ImageUrl:      sql.NullString{String: variantImage, Valid: variantImage != ""},
 	})
@@ -215,6 +216,14 @@ func (cfg *apiConfig) handleAdminProductUpdate(w http.ResponseWriter, r *http.Re
 	imageURL := r.FormValue("image_url")
 	categoryID := r.FormValue("category_id")
 
+	// The form has no tax class field, so keep whatever the API set.
+	current, err := cfg.db.GetProductById(r.Context(), id)
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusNotFound, "Product not found")
+		log.Printf("failed to get product (id %s): %v", id, err)
+		return
+	}
+
 	_, err = cfg.db.UpdateProduct(r.Context(), database.UpdateProductParams{
 		ID:          id,
 		Name:        name,
@@ -222,6 +231,7 @@ func (cfg *apiConfig) handleAdminProductUpdate(w http.ResponseWriter, r *http.Re
 		Description: sql.NullString{String: description, Valid: description != ""},
 		ImageUrl:    sql.NullString{String: imageURL, Valid: imageURL != ""},
 		CategoryID:  uuid.MustParse(categoryID),
+		TaxClassID:  current.TaxClassID,
 	})
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusInternalServerError, "Failed to update product")
@@ -334,7 +344,7 @@ func (cfg *apiConfig) handleAdminVariantCreate(w http.ResponseWriter, r *http.Re
 	}
 
 	sku := r.FormValue("sku")
-	price, err := strconv.Atoi(r.FormValue("price"))
+	price, err := money.Parse(r.FormValue("price"))
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusInternalServerError, "Error converting price")
 	}
@@ -350,7 +360,7 @@ func (cfg *apiConfig) handleAdminVariantCreate(w http.ResponseWriter, r *http.Re
 	_, err = cfg.db.CreateVariant(r.Context(), database.CreateVariantParams{
 		ProductID:     productID,
 		Sku:           sku,
-		Price:         float64(price),
+		Price:         price,
 		StockQuantity: int32(stockQty64),
 		ImageUrl:      sql.NullString{String: imageURL, Valid: imageURL != ""},
 		VariantName:   sql.NullString{String: variantName, Valid: variantName != ""},
@@ -384,7 +394,7 @@ func (cfg *apiConfig) handleAdminVariantEditForm(w http.ResponseWriter, r *http.
 		FormAction string
 		Variant    struct {
 			Sku           string
-			Price         float64
+			Price         money.Amount
 			StockQuantity int32
 			ImageUrl      string
 			VariantName   string
@@ -394,7 +404,7 @@ func (cfg *apiConfig) handleAdminVariantEditForm(w http.ResponseWriter, r *http.
 		FormAction: fmt.Sprintf("/admin/variants/%s", id),
 		Variant: struct {
 			Sku           string
-			Price         float64
+			Price         money.Amount
 			StockQuantity int32
 			ImageUrl      string
 			VariantName   string
@@ -424,7 +434,7 @@ func (cfg *apiConfig) handleAdminVariantUpdate(w http.ResponseWriter, r *http.Re
 	}
 
 	sku := r.FormValue("sku")
-	price, err := strconv.Atoi(r.FormValue("price"))
+	price, err := money.Parse(r.FormValue("price"))
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusInternalServerError, "Error converting price")
 	}
@@ -440,7 +450,7 @@ func (cfg *apiConfig) handleAdminVariantUpdate(w http.ResponseWriter, r *http.Re
 	_, err = cfg.db.UpdateVariant(r.Context(), database.UpdateVariantParams{
 		ID:            id,
 		Sku:           sku,
-		Price:         float64(price),
+		Price:         price,
 		StockQuantity: int32(stockQty64),
 		ImageUrl:      sql.NullString{String: imageURL, Valid: imageURL != ""},
 		VariantName:   sql.NullString{String: variantName, Valid: variantName != ""},
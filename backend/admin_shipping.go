@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -61,11 +62,17 @@ func (cfg *apiConfig) handleAdminShippingOptionCreate(w http.ResponseWriter, r *
 
 	name := r.FormValue("name")
 	description := r.FormValue("description")
-	price := r.FormValue("price")
 	estimatedDays := r.FormValue("estimated_days")
 	isActive := r.FormValue("is_active") != ""
 
-	_, err := cfg.db.CreateShippingOption(r.Context(), database.CreateShippingOptionParams{
+	price, err := money.Parse(r.FormValue("price"))
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusBadRequest, "Invalid price")
+		log.Printf("invalid shipping price: %v", err)
+		return
+	}
+
+	_, err = cfg.db.CreateShippingOption(r.Context(), database.CreateShippingOptionParams{
 		Name: name,
 		Description: sql.NullString{
 			String: description,
@@ -130,11 +137,17 @@ func (cfg *apiConfig) handleAdminShippingOptionUpdate(w http.ResponseWriter, r *
 
 	name := r.FormValue("name")
 	description := r.FormValue("description")
-	price := r.FormValue("price")
 	estimatedDays := r.FormValue("estimated_days")
 	sortOrder := r.FormValue("sort_order")
 	isActive := r.PostFormValue("is_active") != ""
 
+	price, err := money.Parse(r.FormValue("price"))
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusBadRequest, "Invalid price")
+		log.Printf("invalid shipping price: %v", err)
+		return
+	}
+
 	sortOrderInt64, err := strconv.ParseInt(sortOrder, 10, 32)
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusBadRequest, "Invalid sort order")
@@ -2,26 +2,35 @@ package main
 
 import (
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
-func calculateCartTotal(cartId uuid.UUID, cartItems []database.GetCartDetailsWithSnapshotPriceRow, shippingFee float64) CartResponse {
-	subtotal := 0.0
+func calculateCartTotal(cartId uuid.UUID, currency string, cartItems []database.GetCartDetailsWithSnapshotPriceRow, shippingFee money.Amount, discount discountQuote, tax taxQuote) CartResponse {
+	var subtotal money.Amount
 	for _, item := range cartItems {
-		subtotal += float64(item.Quantity) * item.PricePerItem
+		subtotal += item.PricePerItem.Mul(int64(item.Quantity))
 	}
 	itemCount := len(cartItems)
-	total := subtotal + shippingFee
+	total := subtotal + shippingFee - discount.total() + tax.added()
 
 	if cartItems == nil {
 		cartItems = make([]database.GetCartDetailsWithSnapshotPriceRow, 0)
 	}
 	return CartResponse{
-		CartID:      cartId,
-		ItemCount:   itemCount,
-		Items:       cartItems,
-		Subtotal:    subtotal,
-		ShippingFee: shippingFee,
-		Total:       total,
+		CartID:           cartId,
+		Currency:         currency,
+		ItemCount:        itemCount,
+		Items:            cartItems,
+		Subtotal:         subtotal,
+		ShippingFee:      shippingFee,
+		Coupon:           discount.Coupon,
+		Discounts:        discount.lines(),
+		Discount:         discount.total(),
+		Tax:              tax.Total,
+		TaxLines:         tax.Lines,
+		PricesIncludeTax: tax.PricesIncludeTax,
+		ReverseCharge:    tax.ReverseCharge,
+		Total:            total,
 	}
 }
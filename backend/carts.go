@@ -10,6 +10,7 @@ import (
 
 	"github.com/bzelaznicki/bzCommerce/internal/auth"
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -49,11 +50,17 @@ func (cfg *apiConfig) handleAddToCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rate, err := cfg.getCartExchangeRate(r.Context(), cartID)
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
 	variant := database.UpsertVariantToCartParams{
 		CartID:           cartID,
 		ProductVariantID: variantID,
 		Quantity:         int32(quantityInt),
-		PricePerItem:     v.Price,
+		PricePerItem:     rate.fromBase(v.Price),
 	}
 	_, err = cfg.db.UpsertVariantToCart(r.Context(), variant)
 	if err != nil {
@@ -132,9 +139,11 @@ func (cfg *apiConfig) getOrCreateCartID(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	newCart, err := cfg.db.CreateCart(ctx, uuid.NullUUID{
-		UUID:  userID,
-		Valid: userID != uuid.Nil,
+	base := cfg.baseExchangeRate()
+	newCart, err := cfg.db.CreateCart(ctx, database.CreateCartParams{
+		UserID:       uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		Currency:     base.nullCurrency(),
+		ExchangeRate: base.Rate,
 	})
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to create cart: %w", err)
@@ -162,9 +171,11 @@ func (cfg *apiConfig) mergeAnonymousCart(ctx context.Context, anonCartID, userID
 	}
 
 	var userCartID uuid.UUID
+	var userRate exchangeRate
 	for _, cart := range userCarts {
 		if cart.Status == "new" {
 			userCartID = cart.ID
+			userRate = cfg.cartExchangeRate(cart)
 			break
 		}
 	}
@@ -180,17 +191,29 @@ func (cfg *apiConfig) mergeAnonymousCart(ctx context.Context, anonCartID, userID
 		return nil
 	}
 
-	items, err := cfg.db.GetCartProductsByCartId(ctx, anonCartID)
+	anonRate, err := cfg.getCartExchangeRate(ctx, anonCartID)
+	if err != nil {
+		return err
+	}
+
+	items, err := cfg.db.GetCartDetailsWithSnapshotPrice(ctx, anonCartID)
 	if err != nil {
 		return fmt.Errorf("failed to get anonymous cart items: %w", err)
 	}
 
 	for _, item := range items {
+		// Snapshot prices only carry over within the same currency; otherwise the
+		// item is repriced in the user's cart currency.
+		price := item.PricePerItem
+		if anonRate.Currency != userRate.Currency {
+			price = userRate.fromBase(item.VariantPrice)
+		}
+
 		_, err := cfg.db.UpsertVariantToCart(ctx, database.UpsertVariantToCartParams{
 			CartID:           userCartID,
 			ProductVariantID: item.ProductVariantID,
 			Quantity:         item.Quantity,
-			PricePerItem:     item.PricePerItem,
+			PricePerItem:     price,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to upsert item during cart merge: %w", err)
@@ -226,9 +249,11 @@ func (cfg *apiConfig) getOrCreateCartIDForUser(ctx context.Context, userID uuid.
 		}
 	}
 
-	newCart, err := cfg.db.CreateCart(ctx, uuid.NullUUID{
-		UUID:  userID,
-		Valid: true,
+	base := cfg.baseExchangeRate()
+	newCart, err := cfg.db.CreateCart(ctx, database.CreateCartParams{
+		UserID:       uuid.NullUUID{UUID: userID, Valid: true},
+		Currency:     base.nullCurrency(),
+		ExchangeRate: base.Rate,
 	})
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to create cart: %w", err)
@@ -242,7 +267,7 @@ func (cfg *apiConfig) handleViewCart(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		cfg.Render(w, r, "templates/pages/cart.html", struct {
 			Items []database.GetCartDetailsWithSnapshotPriceRow
-			Total float64
+			Total money.Amount
 		}{})
 		return
 	}
@@ -253,14 +278,14 @@ func (cfg *apiConfig) handleViewCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var total float64
+	var total money.Amount
 	for _, item := range items {
-		total += float64(item.Quantity) * item.PricePerItem
+		total += item.PricePerItem.Mul(int64(item.Quantity))
 	}
 
 	data := struct {
 		Items []database.GetCartDetailsWithSnapshotPriceRow
-		Total float64
+		Total money.Amount
 	}{
 		Items: items,
 		Total: total,
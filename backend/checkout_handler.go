@@ -1,18 +1,30 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
 	"github.com/google/uuid"
 )
 
 type CheckoutPageData struct {
 	Items           []database.GetCartDetailsWithSnapshotPriceRow
-	Total           float64
+	Total           money.Amount
 	ShippingOptions []database.ShippingOption
-	PaymentOptions  []database.PaymentOption
+	PaymentOptions  []database.GetActivePaymentOptionsRow
+	Countries       []database.Country
+}
+
+type CheckoutPaymentPageData struct {
+	Order    database.Order
+	Items    []database.GetOrderItemsByOrderIdWithVariantsRow
+	Currency string
+	Payment  CheckoutPayment
+	OrderURL string
 }
 
 func (cfg *apiConfig) handleViewCheckout(w http.ResponseWriter, r *http.Request) {
@@ -21,11 +33,18 @@ func (cfg *apiConfig) handleViewCheckout(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		cfg.Render(w, r, "templates/pages/cart.html", struct {
 			Items []database.GetCartDetailsWithSnapshotPriceRow
-			Total float64
+			Total money.Amount
 		}{})
 		return
 	}
 
+	cart, err := cfg.db.GetCartById(r.Context(), cartID)
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load cart")
+		log.Printf("Error loading cart: %v", err)
+		return
+	}
+
 	items, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load cart")
@@ -33,23 +52,36 @@ func (cfg *apiConfig) handleViewCheckout(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var total float64
+	var total money.Amount
 	for _, item := range items {
-		total += float64(item.Quantity) * item.PricePerItem
+		total += item.PricePerItem.Mul(int64(item.Quantity))
 	}
 
-	shippingOptions, err := cfg.db.GetShippingOptions(r.Context())
+	shippingOptions, err := cfg.db.GetActiveShippingOptions(r.Context())
 	if err != nil {
 		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load shipping options")
 		log.Printf("Error loading shipping options: %v", err)
 		return
 	}
 
-	paymentOptions, err := cfg.db.GetPaymentOptions(r.Context())
+	// Shipping is charged in the cart's currency, like its items.
+	rate := cfg.cartExchangeRate(cart)
+	for i := range shippingOptions {
+		shippingOptions[i].Price = rate.fromBase(shippingOptions[i].Price)
+	}
 
+	paymentOptions, err := cfg.db.GetActivePaymentOptions(r.Context())
 	if err != nil {
-		cfg.RenderError(w, r, http.StatusBadRequest, "Could not load payment options")
+		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load payment options")
 		log.Printf("Error loading payment options: %v", err)
+		return
+	}
+
+	countries, err := cfg.db.GetActiveCountries(r.Context())
+	if err != nil {
+		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load countries")
+		log.Printf("Error loading countries: %v", err)
+		return
 	}
 
 	data := CheckoutPageData{
@@ -57,11 +89,14 @@ func (cfg *apiConfig) handleViewCheckout(w http.ResponseWriter, r *http.Request)
 		Total:           total,
 		ShippingOptions: shippingOptions,
 		PaymentOptions:  paymentOptions,
+		Countries:       countries,
 	}
 	cfg.Render(w, r, "templates/pages/checkout.html", data)
 
 }
 
+// handleCheckout places an order from the checkout form through the same path
+// as the API checkout, then sends the customer on to pay for it.
 func (cfg *apiConfig) handleCheckout(w http.ResponseWriter, r *http.Request) {
 	cartId, err := cfg.getOrCreateCartID(w, r)
 	if err != nil {
@@ -70,92 +105,70 @@ func (cfg *apiConfig) handleCheckout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	customerEmail := r.FormValue("customer_email")
-	shippingName := r.FormValue("shipping_name")
-	shippingAddress := r.FormValue("shipping_address")
-	shippingCity := r.FormValue("shipping_city")
-	shippingPostalCode := r.FormValue("shipping_postal_code")
-	shippingPhone := r.FormValue("shipping_phone")
-	shippingOptionID := r.FormValue("shipping_method_id")
-	paymentOptionID := r.FormValue("payment_method_id")
-
-	sameAsShipping := r.PostFormValue("same_as_shipping") != ""
-
-	billingName := r.PostFormValue("billing_name")
-	billingAddress := r.PostFormValue("billing_address")
-	billingCity := r.PostFormValue("billing_city")
-	billingPostalCode := r.PostFormValue("billing_postal_code")
-
-	if sameAsShipping {
-		billingName = r.PostFormValue("shipping_name")
-		billingAddress = r.PostFormValue("shipping_address")
-		billingCity = r.PostFormValue("shipping_city")
-		billingPostalCode = r.PostFormValue("shipping_postal_code")
+	params := CheckoutParams{
+		CustomerEmail:      r.PostFormValue("customer_email"),
+		ShippingName:       r.PostFormValue("shipping_name"),
+		ShippingAddress:    r.PostFormValue("shipping_address"),
+		ShippingCity:       r.PostFormValue("shipping_city"),
+		ShippingPostalCode: r.PostFormValue("shipping_postal_code"),
+		ShippingPhone:      r.PostFormValue("shipping_phone"),
+		ShippingCountryID:  formUUID(r, "shipping_country_id"),
+		ShippingMethodID:   formUUID(r, "shipping_method_id"),
+		PaymentMethodID:    formUUID(r, "payment_method_id"),
+		CustomerNote:       r.PostFormValue("customer_note"),
+		VatID:              r.PostFormValue("vat_id"),
+		BillingName:        r.PostFormValue("billing_name"),
+		BillingAddress:     r.PostFormValue("billing_address"),
+		BillingCity:        r.PostFormValue("billing_city"),
+		BillingPostalCode:  r.PostFormValue("billing_postal_code"),
+		BillingCountryID:   formUUID(r, "billing_country_id"),
+	}
 
+	if r.PostFormValue("same_as_shipping") != "" {
+		params.BillingName = params.ShippingName
+		params.BillingAddress = params.ShippingAddress
+		params.BillingCity = params.ShippingCity
+		params.BillingPostalCode = params.ShippingPostalCode
+		params.BillingCountryID = params.ShippingCountryID
 	}
 
-	cart, err := cfg.db.GetCartById(r.Context(), cartId)
+	placed, err := cfg.placeOrder(r.Context(), cartId, getUserIDFromContext(r.Context()), params)
 	if err != nil {
-		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load cart")
-		log.Printf("Error loading cart: %v", err)
+		var cErr checkoutError
+		if errors.As(err, &cErr) {
+			cfg.RenderError(w, r, cErr.status, cErr.message)
+			return
+		}
+		log.Printf("Checkout error: %v", err)
+		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not create order")
 		return
 	}
 
-	items, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartId)
-	if err != nil {
-		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not load cart items")
-		log.Printf("Error loading cart items: %v", err)
+	if placed.Payment.NextAction.Type == payments.ActionRedirect {
+		http.Redirect(w, r, placed.Payment.NextAction.RedirectURL, http.StatusSeeOther)
 		return
 	}
 
-	var subtotal float64
-	for _, item := range items {
-		subtotal += float64(item.Quantity) * item.PricePerItem
+	items, err := cfg.db.GetOrderItemsByOrderIdWithVariants(r.Context(), placed.Order.ID)
+	if err != nil {
+		log.Printf("Error loading order items: %v", err)
 	}
 
-	var shippingPrice float64
-	if shippingOptionID != "" {
-		shippingUUID, err := uuid.Parse(shippingOptionID)
-		if err != nil {
-			cfg.RenderError(w, r, http.StatusBadRequest, "Invalid shipping method selected")
-			return
-		}
-
-		option, err := cfg.db.SelectShippingOptionById(r.Context(), shippingUUID)
-		if err != nil {
-			cfg.RenderError(w, r, http.StatusInternalServerError, "Could not find shipping option")
-			log.Printf("Error finding shipping option: %v", err)
-			return
-		}
-
-		shippingPrice = option.Price
-	}
-
-	totalPrice := subtotal + shippingPrice
-
-	_, err = cfg.db.CreateOrder(r.Context(), database.CreateOrderParams{
-		UserID:             cart.UserID,
-		CustomerEmail:      customerEmail,
-		TotalPrice:         totalPrice,
-		ShippingPrice:      shippingPrice,
-		ShippingName:       shippingName,
-		ShippingAddress:    shippingAddress,
-		ShippingCity:       shippingCity,
-		ShippingPostalCode: shippingPostalCode,
-		ShippingCountryID:  uuid.UUID{},
-		ShippingPhone:      shippingPhone,
-		ShippingOptionID:   uuid.MustParse(shippingOptionID),
-		PaymentOptionID:    uuid.MustParse(paymentOptionID),
-		BillingName:        billingName,
-		BillingAddress:     billingAddress,
-		BillingCity:        billingCity,
-		BillingPostalCode:  billingPostalCode,
-		BillingCountryID:   uuid.UUID{},
+	cfg.Render(w, r, "templates/pages/checkout_payment.html", CheckoutPaymentPageData{
+		Order:    placed.Order,
+		Items:    items,
+		Currency: placed.Currency,
+		Payment:  placed.Payment,
+		OrderURL: placed.OrderURL,
 	})
+}
+
+// formUUID parses an ID posted by a form, leaving it nil when it is missing or
+// invalid so placeOrder reports the field as required.
+func formUUID(r *http.Request, field string) uuid.UUID {
+	id, err := uuid.Parse(r.PostFormValue(field))
 	if err != nil {
-		cfg.RenderError(w, r, http.StatusInternalServerError, "Could not create order")
-		log.Printf("Error creating order: %v", err)
-		return
+		return uuid.Nil
 	}
-	http.Redirect(w, r, "/checkout/payment", http.StatusSeeOther)
+	return id
 }
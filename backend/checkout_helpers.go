@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// checkoutError is returned by placeOrder when the checkout cannot go ahead; its
+// message is safe to show to the customer.
+type checkoutError struct {
+	status  int
+	message string
+}
+
+func (e checkoutError) Error() string {
+	return e.message
+}
+
+// placedOrder is an order placed by placeOrder, with what the checkout pages
+// need to show it and continue to payment.
+type placedOrder struct {
+	Order           database.Order
+	Currency        string
+	CartItems       []database.OrdersVariant
+	Discount        discountQuote
+	Tax             taxQuote
+	AccessToken     string
+	AccessExpiresAt time.Time
+	OrderURL        string
+	Payment         CheckoutPayment
+}
+
+// placeOrder turns a cart into an order. Coupons, tax and VAT are applied, stock
+// is reserved and the cart completed in one transaction, and the payment is
+// started once it has committed. Both the API and the template shop check out
+// through it.
+func (cfg *apiConfig) placeOrder(ctx context.Context, cartID, userID uuid.UUID, params CheckoutParams) (placedOrder, error) {
+	email := params.CustomerEmail
+	if userID != uuid.Nil {
+		user, err := cfg.db.GetUserById(ctx, userID)
+		if err != nil {
+			return placedOrder{}, checkoutError{http.StatusInternalServerError, "Unable to load user"}
+		}
+		email = user.Email
+	} else if !isValidEmail(email) {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, "Invalid email"}
+	}
+
+	if params.ShippingName == "" || params.ShippingAddress == "" ||
+		params.ShippingCity == "" || params.ShippingPostalCode == "" ||
+		params.ShippingCountryID == uuid.Nil || params.ShippingPhone == "" ||
+		params.ShippingMethodID == uuid.Nil || params.PaymentMethodID == uuid.Nil ||
+		params.BillingName == "" || params.BillingAddress == "" ||
+		params.BillingCity == "" || params.BillingPostalCode == "" ||
+		params.BillingCountryID == uuid.Nil {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, "Missing required fields"}
+	}
+
+	params.CustomerNote = strings.TrimSpace(params.CustomerNote)
+	if utf8.RuneCountInString(params.CustomerNote) > maxCustomerNoteLength {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, fmt.Sprintf("Customer note cannot exceed %d characters", maxCustomerNoteLength)}
+	}
+
+	shippingCountry, err := cfg.db.GetCountryById(ctx, params.ShippingCountryID)
+	if err != nil || !shippingCountry.IsActive {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, "Invalid shipping country"}
+	}
+	billingCountry, err := cfg.db.GetCountryById(ctx, params.BillingCountryID)
+	if err != nil || !billingCountry.IsActive {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, "Invalid billing country"}
+	}
+
+	vatID := params.VatID
+	if vatID == "" && userID != uuid.Nil {
+		vatID = cfg.defaultBillingVATID(ctx, userID, billingCountry.ID)
+	}
+
+	buyer, err := cfg.checkBuyerVAT(ctx, billingCountry, vatID)
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, err.Error()}
+	}
+
+	cart, err := cfg.db.GetCartById(ctx, cartID)
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Unable to load cart"}
+	}
+	rate := cfg.cartExchangeRate(cart)
+
+	items, err := cfg.db.GetCartDetailsWithSnapshotPrice(ctx, cartID)
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to load cart details"}
+	}
+	if len(items) == 0 {
+		return placedOrder{}, checkoutError{http.StatusBadRequest, "Cart is empty"}
+	}
+
+	var subtotal money.Amount
+	for _, item := range items {
+		subtotal += item.PricePerItem.Mul(int64(item.Quantity))
+	}
+
+	shippingMethod, err := cfg.db.SelectShippingOptionById(ctx, params.ShippingMethodID)
+	if err != nil || !shippingMethod.IsActive {
+		return placedOrder{}, checkoutError{http.StatusNotFound, "Shipping method not found"}
+	}
+
+	paymentMethod, err := cfg.db.GetPaymentOptionById(ctx, params.PaymentMethodID)
+	if err != nil || !paymentMethod.IsActive {
+		return placedOrder{}, checkoutError{http.StatusNotFound, "Payment method not found"}
+	}
+
+	provider, err := cfg.paymentProvider(paymentMethod)
+	if err != nil {
+		log.Printf("Payment method %s has an unusable provider: %v", paymentMethod.ID, err)
+		return placedOrder{}, checkoutError{http.StatusServiceUnavailable, "Payment method is not available"}
+	}
+
+	shippingPrice := rate.fromBase(shippingMethod.Price)
+
+	lines, err := loadCartLines(ctx, cfg.db, cartID)
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to load cart details"}
+	}
+
+	coupon, discount, err := cfg.checkoutDiscount(ctx, cfg.db, cart, lines, shippingPrice, userID, email)
+	if err != nil {
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			return placedOrder{}, checkoutError{http.StatusBadRequest, vErr.Error()}
+		}
+		log.Printf("Checkout coupon error: %v", err)
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to apply coupon"}
+	}
+
+	// Tax follows the goods, so it is charged at the shipping country's rates.
+	tax, err := cfg.quoteCartTax(ctx, cfg.db, shippingCountry.ID, lines, shippingPrice, discount)
+	if err != nil {
+		log.Printf("Checkout tax error: %v", err)
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to calculate tax"}
+	}
+	tax = buyer.applyTo(tax)
+
+	totalPrice := subtotal + shippingPrice - discount.total() + tax.added()
+	tx, err := cfg.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to create order"}
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+
+	for _, item := range items {
+		_, err := qtx.DecreaseVariantStock(ctx, database.DecreaseVariantStockParams{
+			Quantity:  item.Quantity,
+			VariantID: item.ProductVariantID,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return placedOrder{}, checkoutError{http.StatusBadRequest, fmt.Sprintf("Insufficient stock for SKU %s", item.Sku)}
+			}
+			return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to reserve stock"}
+		}
+	}
+
+	var couponID uuid.NullUUID
+	var couponCode sql.NullString
+	if coupon != nil {
+		couponID = uuid.NullUUID{UUID: coupon.ID, Valid: true}
+		couponCode = sql.NullString{String: coupon.Code, Valid: true}
+	}
+
+	// CreateOrder takes the next order number from a locked counter row, so the
+	// number is only consumed if this transaction commits.
+	order, err := qtx.CreateOrder(ctx, database.CreateOrderParams{
+		OrderNumberFormat:  cfg.orderNumberFormat,
+		OrderNumberDigits:  cfg.orderNumberDigits,
+		UserID:             uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		TotalPrice:         totalPrice,
+		CustomerEmail:      email,
+		ShippingName:       params.ShippingName,
+		ShippingAddress:    params.ShippingAddress,
+		ShippingCity:       params.ShippingCity,
+		ShippingPostalCode: params.ShippingPostalCode,
+		ShippingCountryID:  params.ShippingCountryID,
+		ShippingPhone:      params.ShippingPhone,
+		BillingName:        params.BillingName,
+		BillingAddress:     params.BillingAddress,
+		BillingCity:        params.BillingCity,
+		BillingPostalCode:  params.BillingPostalCode,
+		BillingCountryID:   params.BillingCountryID,
+		ShippingOptionID:   params.ShippingMethodID,
+		PaymentOptionID:    params.PaymentMethodID,
+		ShippingPrice:      shippingPrice,
+		CustomerNote:       sql.NullString{String: params.CustomerNote, Valid: params.CustomerNote != ""},
+		Currency:           rate.nullCurrency(),
+		ExchangeRate:       rate.Rate,
+		TaxTotal:           tax.Total,
+		PricesIncludeTax:   tax.PricesIncludeTax,
+		VatID:              sql.NullString{String: buyer.ID, Valid: buyer.ID != ""},
+		ReverseCharge:      tax.ReverseCharge,
+		CouponID:           couponID,
+		CouponCode:         couponCode,
+		DiscountTotal:      discount.total(),
+	})
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot create order"}
+	}
+
+	if coupon != nil {
+		if err := redeemCoupon(ctx, qtx, *coupon, order.ID, userID, email); err != nil {
+			var vErr validationError
+			if errors.As(err, &vErr) {
+				return placedOrder{}, checkoutError{http.StatusConflict, vErr.Error()}
+			}
+			log.Printf("Checkout coupon redemption error: %v", err)
+			return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot create order"}
+		}
+	}
+
+	if err := saveOrderTaxLines(ctx, qtx, order.ID, tax); err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot create order"}
+	}
+
+	if err := saveOrderDiscountLines(ctx, qtx, order.ID, discount); err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot create order"}
+	}
+
+	cartItems, err := qtx.CopyCartDataIntoOrder(ctx, database.CopyCartDataIntoOrderParams{OrderID: order.ID, CartID: cartID})
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot copy cart items into order"}
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeCreated, userActor(userID), map[string]any{
+		"order_number":   order.OrderNumber,
+		"total_price":    order.TotalPrice,
+		"shipping_price": order.ShippingPrice,
+		"tax_total":      order.TaxTotal,
+		"discount_total": order.DiscountTotal,
+		"coupon_code":    order.CouponCode.String,
+		"reverse_charge": order.ReverseCharge,
+		"currency":       rate.Currency,
+		"exchange_rate":  rate.Rate,
+		"item_count":     len(cartItems),
+	})
+	if err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot create order"}
+	}
+
+	if _, err := qtx.UpdateCartStatus(ctx, database.UpdateCartStatusParams{
+		Status: "completed",
+		CartID: cartID,
+	}); err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Failed to update cart"}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return placedOrder{}, checkoutError{http.StatusInternalServerError, "Cannot finalize order"}
+	}
+
+	accessToken, accessExpiresAt := cfg.orderAccessToken(order.ID)
+	orderURL := cfg.orderAccessURL(order.ID, accessToken)
+
+	order, payment := cfg.startPayment(ctx, order, provider, orderURL)
+
+	return placedOrder{
+		Order:           order,
+		Currency:        rate.Currency,
+		CartItems:       cartItems,
+		Discount:        discount,
+		Tax:             tax,
+		AccessToken:     accessToken,
+		AccessExpiresAt: accessExpiresAt,
+		OrderURL:        orderURL,
+		Payment:         payment,
+	}, nil
+}
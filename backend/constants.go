@@ -10,6 +10,26 @@ const MinPasswordLength = 2
 
 const defaultMaxCartSize = 1000
 
+const defaultStoreCurrency = "USD"
+
+const maxCustomerNoteLength = 1000
+const maxOrderNoteLength = 5000
+
+// Order numbers are built from the format by replacing {year} with the current
+// year and {seq} with the zero-padded sequence, e.g. BZ-2026-000123.
+const defaultOrderNumberFormat = "BZ-{year}-{seq}"
+const defaultOrderNumberDigits = 6
+
+const defaultOrderAccessTTLHours = 30 * 24
+
+// Guest order lookups are throttled so order numbers cannot be walked for a
+// known email address.
+const orderLookupWindow = 15 * time.Minute
+const orderLookupsPerIP = 20
+const orderLookupsPerEmail = 5
+
+const defaultUnpaidOrderTimeoutMinutes = 72 * 60
+
 const (
 	minInt32 = -2147483648
 	maxInt32 = 2147483647
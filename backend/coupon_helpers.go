@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// CartCoupon is the coupon attached to a cart. A coupon that stops applying,
+// for example because items were removed, stays on the cart with the reason
+// and applies again once the cart qualifies.
+type CartCoupon struct {
+	Code    string              `json:"code"`
+	Type    database.CouponType `json:"type"`
+	Applied bool                `json:"applied"`
+	Reason  string              `json:"reason,omitempty"`
+}
+
+// couponRules is a coupon with the products and categories it is limited to.
+type couponRules struct {
+	coupon     database.Coupon
+	products   map[uuid.UUID]bool
+	categories map[uuid.UUID]bool
+}
+
+func loadCouponRules(ctx context.Context, q *database.Queries, coupon database.Coupon) (couponRules, error) {
+	productIDs, err := q.GetCouponProductIds(ctx, coupon.ID)
+	if err != nil {
+		return couponRules{}, fmt.Errorf("failed to load coupon products: %w", err)
+	}
+	categoryIDs, err := q.GetCouponCategoryIds(ctx, coupon.ID)
+	if err != nil {
+		return couponRules{}, fmt.Errorf("failed to load coupon categories: %w", err)
+	}
+
+	rules := couponRules{
+		coupon:     coupon,
+		products:   make(map[uuid.UUID]bool, len(productIDs)),
+		categories: make(map[uuid.UUID]bool, len(categoryIDs)),
+	}
+	for _, id := range productIDs {
+		rules.products[id] = true
+	}
+	for _, id := range categoryIDs {
+		rules.categories[id] = true
+	}
+	return rules, nil
+}
+
+// covers reports whether a line is eligible. Coupons without products or
+// categories cover every line.
+func (r couponRules) covers(line pricedLine) bool {
+	if len(r.products) == 0 && len(r.categories) == 0 {
+		return true
+	}
+	return r.products[line.ProductID] || r.categories[line.CategoryID]
+}
+
+// checkCouponAvailable checks a coupon's status, dates and overall usage.
+func checkCouponAvailable(coupon database.Coupon, now time.Time) error {
+	if !coupon.IsActive {
+		return validationError("coupon is not active")
+	}
+	if coupon.StartsAt.Valid && now.Before(coupon.StartsAt.Time) {
+		return validationError("coupon is not valid yet")
+	}
+	if coupon.EndsAt.Valid && !now.Before(coupon.EndsAt.Time) {
+		return validationError("coupon has expired")
+	}
+	if coupon.UsageLimit.Valid && coupon.TimesUsed >= coupon.UsageLimit.Int32 {
+		return validationError("coupon has been used up")
+	}
+	return nil
+}
+
+// checkCouponCustomerUsage enforces the per-customer limit. Customers are
+// matched by account or email, so guest orders count as well.
+func checkCouponCustomerUsage(ctx context.Context, q *database.Queries, coupon database.Coupon, userID uuid.UUID, email string) error {
+	if !coupon.UsageLimitPerCustomer.Valid {
+		return nil
+	}
+
+	used, err := q.CountCouponRedemptionsByCustomer(ctx, database.CountCouponRedemptionsByCustomerParams{
+		CouponID:      coupon.ID,
+		UserID:        uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		CustomerEmail: email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+	if used >= int64(coupon.UsageLimitPerCustomer.Int32) {
+		return validationError("coupon has already been used the maximum number of times")
+	}
+	return nil
+}
+
+// calculateDiscount works out a coupon's discount on lines and shipping. The
+// coupon's amounts are in the store currency and are converted at rate.
+func calculateDiscount(rules couponRules, lines []pricedLine, shipping money.Amount, rate exchangeRate) (discountQuote, error) {
+	coupon := rules.coupon
+
+	var subtotal, eligible money.Amount
+	for _, line := range lines {
+		subtotal += line.Amount
+		if rules.covers(line) {
+			eligible += line.Amount
+		}
+	}
+
+	if minSubtotal := rate.fromBase(coupon.MinSubtotal); subtotal < minSubtotal {
+		return discountQuote{}, validationErrorf("coupon requires a subtotal of at least %s %s", minSubtotal, rate.Currency)
+	}
+	if !eligible.IsPositive() {
+		return discountQuote{}, validationError("coupon does not apply to any item in the cart")
+	}
+
+	discount := discountQuote{Items: map[uuid.UUID]money.Amount{}}
+	switch coupon.Type {
+	case database.CouponTypePercentage:
+		discount.Goods = eligible.Percent(coupon.PercentOff)
+	case database.CouponTypeFixedAmount:
+		discount.Goods = money.Min(rate.fromBase(coupon.AmountOff), eligible)
+	case database.CouponTypeFreeShipping:
+		discount.Shipping = shipping
+	}
+
+	var covered []pricedLine
+	var weights []money.Amount
+	for _, line := range lines {
+		if rules.covers(line) {
+			covered = append(covered, line)
+			weights = append(weights, line.Amount)
+		}
+	}
+	for i, share := range spreadDiscount(discount.Goods, weights) {
+		discount.Items[covered[i].VariantID] += share
+	}
+
+	discount.Lines = []DiscountLine{{
+		Name:        "Coupon " + coupon.Code,
+		Explanation: explainCoupon(coupon, rate),
+		Amount:      discount.total(),
+	}}
+	return discount, nil
+}
+
+func explainCoupon(coupon database.Coupon, rate exchangeRate) string {
+	switch coupon.Type {
+	case database.CouponTypePercentage:
+		return fmt.Sprintf("%s%% off", coupon.PercentOff)
+	case database.CouponTypeFixedAmount:
+		return fmt.Sprintf("%s %s off", rate.fromBase(coupon.AmountOff), rate.Currency)
+	default:
+		return "Free shipping"
+	}
+}
+
+// cartCouponDiscount works out the discount of the coupon attached to a cart.
+// A coupon that does not apply right now gives no discount and says why.
+func (cfg *apiConfig) cartCouponDiscount(ctx context.Context, q *database.Queries, cart database.Cart, lines []pricedLine, shipping money.Amount) (discountQuote, error) {
+	if !cart.CouponID.Valid {
+		return discountQuote{}, nil
+	}
+
+	coupon, err := q.GetCouponById(ctx, cart.CouponID.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return discountQuote{}, nil
+		}
+		return discountQuote{}, fmt.Errorf("failed to load coupon: %w", err)
+	}
+
+	rules, err := loadCouponRules(ctx, q, coupon)
+	if err != nil {
+		return discountQuote{}, err
+	}
+
+	applied := &CartCoupon{Code: coupon.Code, Type: coupon.Type}
+	discount, err := discountQuote{}, checkCouponAvailable(coupon, time.Now().UTC())
+	if err == nil {
+		discount, err = calculateDiscount(rules, lines, shipping, cfg.cartExchangeRate(cart))
+	}
+	if err != nil {
+		var vErr validationError
+		if !errors.As(err, &vErr) {
+			return discountQuote{}, err
+		}
+		applied.Reason = vErr.Error()
+		return discountQuote{Coupon: applied}, nil
+	}
+
+	applied.Applied = true
+	discount.Coupon = applied
+	return discount, nil
+}
+
+// validateCoupon checks every rule of a coupon against a cart, including the
+// buyer's own usage, and works out its discount.
+func (cfg *apiConfig) validateCoupon(ctx context.Context, q *database.Queries, coupon database.Coupon, cart database.Cart, lines []pricedLine, shipping money.Amount, userID uuid.UUID, email string) (discountQuote, error) {
+	if err := checkCouponAvailable(coupon, time.Now().UTC()); err != nil {
+		return discountQuote{}, err
+	}
+	if err := checkCouponCustomerUsage(ctx, q, coupon, userID, email); err != nil {
+		return discountQuote{}, err
+	}
+
+	rules, err := loadCouponRules(ctx, q, coupon)
+	if err != nil {
+		return discountQuote{}, err
+	}
+
+	discount, err := calculateDiscount(rules, lines, shipping, cfg.cartExchangeRate(cart))
+	if err != nil {
+		return discountQuote{}, err
+	}
+	discount.Coupon = &CartCoupon{Code: coupon.Code, Type: coupon.Type, Applied: true}
+	return discount, nil
+}
+
+// orderCouponDiscount reapplies an order's coupon to its current lines after an
+// edit. Dates and usage limits were checked when the order was placed; a coupon
+// that has since been deleted no longer discounts the order.
+func (cfg *apiConfig) orderCouponDiscount(ctx context.Context, q *database.Queries, order database.Order, lines []pricedLine, shipping money.Amount) (discountQuote, error) {
+	if !order.CouponID.Valid {
+		return discountQuote{}, nil
+	}
+
+	coupon, err := q.GetCouponById(ctx, order.CouponID.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return discountQuote{}, nil
+		}
+		return discountQuote{}, fmt.Errorf("failed to load coupon: %w", err)
+	}
+
+	rules, err := loadCouponRules(ctx, q, coupon)
+	if err != nil {
+		return discountQuote{}, err
+	}
+
+	discount, err := calculateDiscount(rules, lines, shipping, cfg.orderExchangeRate(order))
+	if err != nil {
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			return discountQuote{}, nil
+		}
+		return discountQuote{}, err
+	}
+	return discount, nil
+}
+
+// redeemCoupon counts a checkout against the coupon's limits. The usage update
+// locks the coupon row, so concurrent checkouts cannot both take its last use.
+func redeemCoupon(ctx context.Context, qtx *database.Queries, coupon database.Coupon, orderID, userID uuid.UUID, email string) error {
+	coupon, err := qtx.IncrementCouponUsage(ctx, coupon.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return validationError("coupon has been used up")
+		}
+		return fmt.Errorf("failed to count coupon usage: %w", err)
+	}
+
+	if err := checkCouponCustomerUsage(ctx, qtx, coupon, userID, email); err != nil {
+		return err
+	}
+
+	_, err = qtx.CreateCouponRedemption(ctx, database.CreateCouponRedemptionParams{
+		CouponID:      coupon.ID,
+		OrderID:       orderID,
+		UserID:        uuid.NullUUID{UUID: userID, Valid: userID != uuid.Nil},
+		CustomerEmail: email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record coupon redemption: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// exchangeRate is the currency a cart or order is priced in, and how many units
+// of it bought one unit of the store currency when it was priced.
+type exchangeRate struct {
+	Currency string
+	Rate     money.Rate
+}
+
+// fromBase converts a store currency amount, such as a catalogue price.
+func (r exchangeRate) fromBase(a money.Amount) money.Amount {
+	return a.Convert(r.Rate)
+}
+
+// toBase converts an amount back to the store currency for reporting.
+func (r exchangeRate) toBase(a money.Amount) money.Amount {
+	return a.ConvertBack(r.Rate)
+}
+
+func (r exchangeRate) nullCurrency() sql.NullString {
+	return sql.NullString{String: r.Currency, Valid: true}
+}
+
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", validationErrorf("invalid currency code %q", code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", validationErrorf("invalid currency code %q", code)
+		}
+	}
+	return code, nil
+}
+
+func (cfg *apiConfig) baseExchangeRate() exchangeRate {
+	return exchangeRate{Currency: cfg.storeCurrency, Rate: money.IdentityRate}
+}
+
+// storedExchangeRate reads back the currency snapshot of a cart or order. Rows
+// from before currencies were recorded are in the store currency.
+func (cfg *apiConfig) storedExchangeRate(currency sql.NullString, rate money.Rate) exchangeRate {
+	if !currency.Valid {
+		return cfg.baseExchangeRate()
+	}
+	return exchangeRate{Currency: currency.String, Rate: rate}
+}
+
+func (cfg *apiConfig) cartExchangeRate(cart database.Cart) exchangeRate {
+	return cfg.storedExchangeRate(cart.Currency, cart.ExchangeRate)
+}
+
+func (cfg *apiConfig) orderExchangeRate(order database.Order) exchangeRate {
+	return cfg.storedExchangeRate(order.Currency, order.ExchangeRate)
+}
+
+func (cfg *apiConfig) orderCurrency(currency sql.NullString) string {
+	if !currency.Valid {
+		return cfg.storeCurrency
+	}
+	return currency.String
+}
+
+func (cfg *apiConfig) countryCurrency(country database.Country) string {
+	return cfg.orderCurrency(country.Currency)
+}
+
+// currentExchangeRate returns the rate a currency is sold at right now.
+func (cfg *apiConfig) currentExchangeRate(ctx context.Context, q *database.Queries, currency string) (exchangeRate, error) {
+	code, err := normalizeCurrency(currency)
+	if err != nil {
+		return exchangeRate{}, err
+	}
+	if code == cfg.storeCurrency {
+		return cfg.baseExchangeRate(), nil
+	}
+
+	row, err := q.GetExchangeRate(ctx, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return exchangeRate{}, validationErrorf("currency %s is not supported", code)
+		}
+		return exchangeRate{}, fmt.Errorf("failed to load exchange rate: %w", err)
+	}
+
+	return exchangeRate{Currency: row.Currency, Rate: row.Rate}, nil
+}
+
+// getCartExchangeRate returns the currency snapshot new items in a cart are
+// priced at.
+func (cfg *apiConfig) getCartExchangeRate(ctx context.Context, cartID uuid.UUID) (exchangeRate, error) {
+	cart, err := cfg.db.GetCartById(ctx, cartID)
+	if err != nil {
+		return exchangeRate{}, fmt.Errorf("failed to load cart: %w", err)
+	}
+	return cfg.cartExchangeRate(cart), nil
+}
+
+// setCartCurrency switches a cart to a currency at its current rate and reprices
+// the items already in it.
+func (cfg *apiConfig) setCartCurrency(ctx context.Context, qtx *database.Queries, cartID uuid.UUID, rate exchangeRate) error {
+	_, err := qtx.UpdateCartCurrency(ctx, database.UpdateCartCurrencyParams{
+		Currency:     rate.nullCurrency(),
+		ExchangeRate: rate.Rate,
+		ID:           cartID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update cart currency: %w", err)
+	}
+
+	items, err := qtx.GetCartDetailsWithSnapshotPrice(ctx, cartID)
+	if err != nil {
+		return fmt.Errorf("failed to load cart items: %w", err)
+	}
+
+	for _, item := range items {
+		_, err := qtx.UpdateCartVariant(ctx, database.UpdateCartVariantParams{
+			CartID:           cartID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+			PricePerItem:     rate.fromBase(item.VariantPrice),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reprice cart item: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// DiscountLine is one promotion or coupon that applied, with a short
+// explanation of the rule for the shopper.
+type DiscountLine struct {
+	PromotionID *uuid.UUID   `json:"promotion_id"`
+	Name        string       `json:"name"`
+	Explanation string       `json:"explanation"`
+	Amount      money.Amount `json:"amount"`
+}
+
+// discountQuote is what promotions and a coupon take off a cart or order. Items
+// holds each line's share of the goods discount so tax is charged on what is
+// paid.
+type discountQuote struct {
+	Coupon   *CartCoupon
+	Lines    []DiscountLine
+	Items    map[uuid.UUID]money.Amount
+	Goods    money.Amount
+	Shipping money.Amount
+}
+
+func (d discountQuote) total() money.Amount {
+	return d.Goods + d.Shipping
+}
+
+func (d discountQuote) lines() []DiscountLine {
+	if d.Lines == nil {
+		return []DiscountLine{}
+	}
+	return d.Lines
+}
+
+// plus adds a discount worked out on what was left after this one.
+func (d discountQuote) plus(next discountQuote) discountQuote {
+	sum := discountQuote{
+		Coupon:   next.Coupon,
+		Lines:    append(append([]DiscountLine{}, d.Lines...), next.Lines...),
+		Items:    make(map[uuid.UUID]money.Amount, len(d.Items)+len(next.Items)),
+		Goods:    d.Goods + next.Goods,
+		Shipping: d.Shipping + next.Shipping,
+	}
+	if sum.Coupon == nil {
+		sum.Coupon = d.Coupon
+	}
+	for id, amount := range d.Items {
+		sum.Items[id] += amount
+	}
+	for id, amount := range next.Items {
+		sum.Items[id] += amount
+	}
+	return sum
+}
+
+// applyTo returns the lines with their share of the discount taken off.
+func (d discountQuote) applyTo(lines []pricedLine) []pricedLine {
+	net := make([]pricedLine, len(lines))
+	for i, line := range lines {
+		line.Amount -= d.Items[line.VariantID]
+		net[i] = line
+	}
+	return net
+}
+
+// spreadDiscount divides an amount in proportion to the weights. The last
+// weighted share takes the rounding remainder, so the shares add up exactly.
+func spreadDiscount(amount money.Amount, weights []money.Amount) []money.Amount {
+	shares := make([]money.Amount, len(weights))
+
+	var total money.Amount
+	last := -1
+	for i, w := range weights {
+		total += w
+		if w.IsPositive() {
+			last = i
+		}
+	}
+	if last < 0 {
+		return shares
+	}
+
+	remaining := amount
+	for i, w := range weights {
+		if !w.IsPositive() {
+			continue
+		}
+		share := amount.MulRat(w.Cents(), total.Cents())
+		if i == last {
+			share = remaining
+		}
+		shares[i] = share
+		remaining -= share
+	}
+	return shares
+}
+
+// cartDiscount runs the active promotions over a cart, then its coupon over
+// what is left.
+func (cfg *apiConfig) cartDiscount(ctx context.Context, q *database.Queries, cart database.Cart, lines []pricedLine, shipping money.Amount) (discountQuote, error) {
+	promotions, err := cfg.cartPromotions(ctx, q, lines, cfg.cartExchangeRate(cart))
+	if err != nil {
+		return discountQuote{}, err
+	}
+
+	coupon, err := cfg.cartCouponDiscount(ctx, q, cart, promotions.applyTo(lines), shipping)
+	if err != nil {
+		return discountQuote{}, err
+	}
+	return promotions.plus(coupon), nil
+}
+
+// getCartDiscount loads a cart and its lines and works out its discount.
+func (cfg *apiConfig) getCartDiscount(ctx context.Context, q *database.Queries, cartID uuid.UUID, shipping money.Amount) (discountQuote, error) {
+	cart, err := q.GetCartById(ctx, cartID)
+	if err != nil {
+		return discountQuote{}, fmt.Errorf("failed to load cart: %w", err)
+	}
+
+	lines, err := loadCartLines(ctx, q, cartID)
+	if err != nil {
+		return discountQuote{}, err
+	}
+	return cfg.cartDiscount(ctx, q, cart, lines, shipping)
+}
+
+// checkoutDiscount works out the discount when the order is placed and
+// re-validates the cart's coupon, including the buyer's own usage. Unlike the
+// cart view, a coupon that no longer applies fails checkout rather than
+// silently changing the price.
+func (cfg *apiConfig) checkoutDiscount(ctx context.Context, q *database.Queries, cart database.Cart, lines []pricedLine, shipping money.Amount, userID uuid.UUID, email string) (*database.Coupon, discountQuote, error) {
+	promotions, err := cfg.cartPromotions(ctx, q, lines, cfg.cartExchangeRate(cart))
+	if err != nil {
+		return nil, discountQuote{}, err
+	}
+
+	if !cart.CouponID.Valid {
+		return nil, promotions, nil
+	}
+
+	coupon, err := q.GetCouponById(ctx, cart.CouponID.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, promotions, nil
+		}
+		return nil, discountQuote{}, fmt.Errorf("failed to load coupon: %w", err)
+	}
+
+	discount, err := cfg.validateCoupon(ctx, q, coupon, cart, promotions.applyTo(lines), shipping, userID, email)
+	if err != nil {
+		return nil, discountQuote{}, err
+	}
+	return &coupon, promotions.plus(discount), nil
+}
+
+// orderDiscount reworks an order's discount after an edit, using the
+// promotions and coupon it was placed with.
+func (cfg *apiConfig) orderDiscount(ctx context.Context, q *database.Queries, order database.Order, lines []pricedLine, shipping money.Amount) (discountQuote, error) {
+	promotions, err := cfg.orderPromotions(ctx, q, order, lines)
+	if err != nil {
+		return discountQuote{}, err
+	}
+
+	coupon, err := cfg.orderCouponDiscount(ctx, q, order, promotions.applyTo(lines), shipping)
+	if err != nil {
+		return discountQuote{}, err
+	}
+	return promotions.plus(coupon), nil
+}
+
+// saveOrderDiscountLines replaces the discount lines stored on an order.
+func saveOrderDiscountLines(ctx context.Context, qtx *database.Queries, orderID uuid.UUID, quote discountQuote) error {
+	if err := qtx.DeleteOrderDiscountLines(ctx, orderID); err != nil {
+		return fmt.Errorf("failed to clear discount lines: %w", err)
+	}
+
+	for i, line := range quote.Lines {
+		var promotionID uuid.NullUUID
+		if line.PromotionID != nil {
+			promotionID = uuid.NullUUID{UUID: *line.PromotionID, Valid: true}
+		}
+
+		_, err := qtx.CreateOrderDiscountLine(ctx, database.CreateOrderDiscountLineParams{
+			OrderID:     orderID,
+			PromotionID: promotionID,
+			Name:        line.Name,
+			Explanation: line.Explanation,
+			Amount:      line.Amount,
+			Position:    int32(i),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save discount line: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getOrderDiscountLines loads the stored discount lines of an order for a response.
+func getOrderDiscountLines(ctx context.Context, q *database.Queries, orderID uuid.UUID) ([]DiscountLine, error) {
+	rows, err := q.GetOrderDiscountLines(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load discount lines: %w", err)
+	}
+
+	lines := make([]DiscountLine, 0, len(rows))
+	for _, row := range rows {
+		line := DiscountLine{
+			Name:        row.Name,
+			Explanation: row.Explanation,
+			Amount:      row.Amount,
+		}
+		if row.PromotionID.Valid {
+			id := row.PromotionID.UUID
+			line.PromotionID = &id
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
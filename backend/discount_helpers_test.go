@@ -0,0 +1,33 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+func TestSpreadDiscount(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  money.Amount
+		weights []money.Amount
+		want    []money.Amount
+	}{
+		{name: "even", amount: 100, weights: []money.Amount{500, 500}, want: []money.Amount{50, 50}},
+		{name: "proportional", amount: 300, weights: []money.Amount{1000, 2000}, want: []money.Amount{100, 200}},
+		{name: "last takes the remainder", amount: 100, weights: []money.Amount{1, 1, 1}, want: []money.Amount{33, 33, 34}},
+		{name: "zero weights get nothing", amount: 100, weights: []money.Amount{0, 500, 0, 500, 0}, want: []money.Amount{0, 50, 0, 50, 0}},
+		{name: "no weights", amount: 100, weights: []money.Amount{0, 0}, want: []money.Amount{0, 0}},
+		{name: "empty", amount: 100, weights: nil, want: []money.Amount{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spreadDiscount(tt.amount, tt.weights)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("spreadDiscount(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
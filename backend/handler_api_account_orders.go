@@ -0,0 +1,376 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+type AccountOrderResponse struct {
+	ID                 uuid.UUID                                        `json:"id"`
+	OrderNumber        string                                           `json:"order_number"`
+	Status             string                                           `json:"status"`
+	PaymentStatus      string                                           `json:"payment_status"`
+	TotalPrice         money.Amount                                     `json:"total_price"`
+	Currency           string                                           `json:"currency"`
+	CreatedAt          time.Time                                        `json:"created_at"`
+	UpdatedAt          time.Time                                        `json:"updated_at"`
+	CustomerEmail      string                                           `json:"customer_email"`
+	ShippingName       string                                           `json:"shipping_name"`
+	ShippingAddress    string                                           `json:"shipping_address"`
+	ShippingCity       string                                           `json:"shipping_city"`
+	ShippingPostalCode string                                           `json:"shipping_postal_code"`
+	ShippingPhone      string                                           `json:"shipping_phone"`
+	ShippingCountryID  uuid.UUID                                        `json:"shipping_country_id"`
+	BillingName        string                                           `json:"billing_name"`
+	BillingAddress     string                                           `json:"billing_address"`
+	BillingCity        string                                           `json:"billing_city"`
+	BillingPostalCode  string                                           `json:"billing_postal_code"`
+	BillingCountryID   uuid.UUID                                        `json:"billing_country_id"`
+	CustomerNote       string                                           `json:"customer_note"`
+	ShippingMethodName string                                           `json:"shipping_method_name"`
+	ShippingPrice      money.Amount                                     `json:"shipping_price"`
+	CouponCode         string                                           `json:"coupon_code"`
+	DiscountTotal      money.Amount                                     `json:"discount_total"`
+	Discounts          []DiscountLine                                   `json:"discounts"`
+	TaxTotal           money.Amount                                     `json:"tax_total"`
+	PricesIncludeTax   bool                                             `json:"prices_include_tax"`
+	TaxLines           []TaxLine                                        `json:"tax_lines"`
+	VatID              string                                           `json:"vat_id"`
+	ReverseCharge      bool                                             `json:"reverse_charge"`
+	PaymentMethodName  string                                           `json:"payment_method_name"`
+	OrderItems         []database.GetOrderItemsByOrderIdWithVariantsRow `json:"order_items"`
+	Shipments          []ShipmentResponse                               `json:"shipments"`
+	Notes              []OrderNoteResponse                              `json:"notes"`
+	Returns            []ReturnResponse                                 `json:"returns"`
+}
+
+// AccountOrderSummaryResponse is one order in the customer's order history.
+type AccountOrderSummaryResponse struct {
+	ID                 uuid.UUID                                        `json:"id"`
+	OrderNumber        string                                           `json:"order_number"`
+	Status             string                                           `json:"status"`
+	PaymentStatus      string                                           `json:"payment_status"`
+	TotalPrice         money.Amount                                     `json:"total_price"`
+	Currency           string                                           `json:"currency"`
+	ShippingPrice      money.Amount                                     `json:"shipping_price"`
+	CreatedAt          time.Time                                        `json:"created_at"`
+	UpdatedAt          time.Time                                        `json:"updated_at"`
+	ShippingMethodName string                                           `json:"shipping_method_name"`
+	PaymentMethodName  string                                           `json:"payment_method_name"`
+	ItemCount          int32                                            `json:"item_count"`
+	OrderItems         []database.GetOrderItemsByOrderIdWithVariantsRow `json:"order_items"`
+}
+
+func (cfg *apiConfig) handleApiGetAccountOrders(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	page, limit := getPaginationParams(r)
+	offset := (page - 1) * limit
+
+	ownerID := uuid.NullUUID{UUID: userID, Valid: true}
+
+	count, err := cfg.db.CountOrdersByUserId(r.Context(), ownerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to count orders")
+		return
+	}
+
+	orders, err := cfg.db.ListOrdersByUserId(r.Context(), database.ListOrdersByUserIdParams{
+		UserID: ownerID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list orders")
+		return
+	}
+
+	// The lines of the whole page are loaded at once rather than per order.
+	items, err := cfg.db.GetOrderItemsForUserOrders(r.Context(), database.GetOrderItemsForUserOrdersParams{
+		UserID: ownerID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order items")
+		return
+	}
+
+	itemsByOrder := make(map[uuid.UUID][]database.GetOrderItemsByOrderIdWithVariantsRow)
+	for _, item := range items {
+		itemsByOrder[item.OrderID] = append(itemsByOrder[item.OrderID], database.GetOrderItemsByOrderIdWithVariantsRow(item))
+	}
+
+	resp := make([]AccountOrderSummaryResponse, 0, len(orders))
+	for _, order := range orders {
+		orderItems := itemsByOrder[order.ID]
+		if orderItems == nil {
+			orderItems = []database.GetOrderItemsByOrderIdWithVariantsRow{}
+		}
+		resp = append(resp, AccountOrderSummaryResponse{
+			ID:                 order.ID,
+			OrderNumber:        order.OrderNumber,
+			Status:             string(order.Status),
+			PaymentStatus:      string(order.PaymentStatus),
+			TotalPrice:         order.TotalPrice,
+			Currency:           cfg.orderCurrency(order.Currency),
+			ShippingPrice:      order.ShippingPrice,
+			CreatedAt:          order.CreatedAt,
+			UpdatedAt:          order.UpdatedAt,
+			ShippingMethodName: order.ShippingMethodName.String,
+			PaymentMethodName:  order.PaymentMethodName.String,
+			ItemCount:          order.ItemCount,
+			OrderItems:         orderItems,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, NewPaginatedResponse(resp, page, limit, count))
+}
+
+func (cfg *apiConfig) handleApiGetAccountOrder(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	order, err := cfg.db.GetUserOrderById(r.Context(), database.GetUserOrderByIdParams{
+		ID:     orderID,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	orderItems, err := cfg.db.GetOrderItemsByOrderIdWithVariants(r.Context(), order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order items")
+		return
+	}
+
+	if orderItems == nil {
+		orderItems = []database.GetOrderItemsByOrderIdWithVariantsRow{}
+	}
+
+	shipments, err := cfg.getOrderShipments(r.Context(), order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order shipments")
+		return
+	}
+
+	notes, err := cfg.getCustomerOrderNotes(r.Context(), order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order notes")
+		return
+	}
+
+	returns, err := cfg.getOrderReturns(r.Context(), order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order returns")
+		return
+	}
+
+	taxLines, err := getOrderTaxLines(r.Context(), cfg.db, order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order tax lines")
+		return
+	}
+
+	discounts, err := getOrderDiscountLines(r.Context(), cfg.db, order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order discounts")
+		return
+	}
+
+	resp := AccountOrderResponse{
+		ID:                 order.ID,
+		OrderNumber:        order.OrderNumber,
+		Status:             string(order.Status),
+		PaymentStatus:      string(order.PaymentStatus),
+		TotalPrice:         order.TotalPrice,
+		Currency:           cfg.orderCurrency(order.Currency),
+		CreatedAt:          order.CreatedAt,
+		UpdatedAt:          order.UpdatedAt,
+		CustomerEmail:      order.CustomerEmail,
+		ShippingName:       order.ShippingName,
+		ShippingAddress:    order.ShippingAddress,
+		ShippingCity:       order.ShippingCity,
+		ShippingPostalCode: order.ShippingPostalCode,
+		ShippingPhone:      order.ShippingPhone,
+		ShippingCountryID:  order.ShippingCountryID,
+		BillingName:        order.BillingName,
+		BillingAddress:     order.BillingAddress,
+		BillingCity:        order.BillingCity,
+		BillingPostalCode:  order.BillingPostalCode,
+		BillingCountryID:   order.BillingCountryID,
+		CustomerNote:       order.CustomerNote.String,
+		ShippingMethodName: order.ShippingMethodName.String,
+		ShippingPrice:      order.ShippingPrice,
+		CouponCode:         order.CouponCode.String,
+		DiscountTotal:      order.DiscountTotal,
+		Discounts:          discounts,
+		TaxTotal:           order.TaxTotal,
+		PricesIncludeTax:   order.PricesIncludeTax,
+		TaxLines:           taxLines,
+		VatID:              order.VatID.String,
+		ReverseCharge:      order.ReverseCharge,
+		PaymentMethodName:  order.PaymentMethodName.String,
+		OrderItems:         orderItems,
+		Shipments:          shipments,
+		Notes:              notes,
+		Returns:            returns,
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleApiCancelAccountOrder(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	updated, err := cfg.updateOrderInTx(r.Context(), orderID, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		if !order.UserID.Valid || order.UserID.UUID != userID {
+			return order, sql.ErrNoRows
+		}
+
+		switch order.Status {
+		case database.OrderStatusPending, database.OrderStatusPaid, database.OrderStatusCancelled:
+		default:
+			return order, errInvalidStatusTransition
+		}
+
+		return cfg.cancelOrder(r.Context(), qtx, order, userActor(userID))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		if errors.Is(err, errInvalidStatusTransition) {
+			respondWithError(w, http.StatusConflict, "This order can no longer be cancelled")
+			return
+		}
+		log.Printf("Cancel order error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to cancel order")
+		return
+	}
+
+	// The order stays cancelled whatever the provider says; a void or refund it
+	// rejects is left on the ledger for the shop to follow up.
+	if paid, err := cfg.runPaymentOperations(r.Context(), orderID); err != nil {
+		log.Printf("Cancel order %s payment error: %v", orderID, err)
+	} else {
+		updated = paid
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"id":     updated.ID,
+		"status": updated.Status,
+	})
+}
+
+func (cfg *apiConfig) handleApiGetAccountOrderReturns(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	_, err = cfg.db.GetUserOrderById(r.Context(), database.GetUserOrderByIdParams{
+		ID:     orderID,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	returns, err := cfg.getOrderReturns(r.Context(), orderID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order returns")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, returns)
+}
+
+func (cfg *apiConfig) handleApiCreateAccountOrderReturn(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params, ok := decodeReturnRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var ret database.Return
+	_, err = cfg.updateOrderInTx(r.Context(), orderID, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		if !order.UserID.Valid || order.UserID.UUID != userID {
+			return order, sql.ErrNoRows
+		}
+
+		created, err := createReturn(r.Context(), qtx, order, params, userActor(userID))
+		ret = created
+		return order, err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithReturnError(w, err, "Create")
+		return
+	}
+
+	cfg.respondWithReturn(w, r, orderID, ret.ID, http.StatusCreated)
+}
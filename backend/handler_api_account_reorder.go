@@ -0,0 +1,166 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+type ReorderLine struct {
+	VariantID   uuid.UUID `json:"variant_id"`
+	Sku         string    `json:"sku"`
+	ProductName string    `json:"product_name"`
+	Requested   int32     `json:"requested"`
+	Quantity    int32     `json:"quantity"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+type ReorderResponse struct {
+	Cart    CartResponse  `json:"cart"`
+	Added   []ReorderLine `json:"added"`
+	Reduced []ReorderLine `json:"reduced"`
+	Skipped []ReorderLine `json:"skipped"`
+}
+
+// handleApiReorderAccountOrder copies a past order's lines into the active cart at
+// current prices. Lines are capped by stock and the cart limit, counting what is
+// already in the cart.
+func (cfg *apiConfig) handleApiReorderAccountOrder(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	order, err := cfg.db.GetUserOrderById(r.Context(), database.GetUserOrderByIdParams{
+		ID:     orderID,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	orderItems, err := cfg.db.GetOrderItemsByOrderIdWithVariants(r.Context(), order.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order items")
+		return
+	}
+
+	cartID, err := cfg.getOrCreateCartID(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not get or create cart")
+		return
+	}
+
+	rate, err := cfg.getCartExchangeRate(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
+	cartItems, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+	inCart := make(map[uuid.UUID]int32, len(cartItems))
+	for _, item := range cartItems {
+		inCart[item.ProductVariantID] = item.Quantity
+	}
+
+	resp := ReorderResponse{
+		Added:   []ReorderLine{},
+		Reduced: []ReorderLine{},
+		Skipped: []ReorderLine{},
+	}
+
+	for _, item := range orderItems {
+		line := ReorderLine{
+			VariantID:   item.ProductVariantID,
+			Sku:         item.Sku,
+			ProductName: item.ProductName,
+			Requested:   item.Quantity,
+		}
+
+		variant, err := cfg.db.GetVariantByID(r.Context(), item.ProductVariantID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				line.Reason = "no longer available"
+				resp.Skipped = append(resp.Skipped, line)
+				continue
+			}
+			log.Printf("Reorder variant lookup error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to load product variant")
+			return
+		}
+
+		available := min(variant.StockQuantity, int32(cfg.maxCartQuantity)) - inCart[variant.ID]
+		if available <= 0 {
+			line.Reason = "out of stock"
+			if variant.StockQuantity > inCart[variant.ID] {
+				line.Reason = "cart limit reached"
+			}
+			resp.Skipped = append(resp.Skipped, line)
+			continue
+		}
+
+		line.Quantity = min(item.Quantity, available)
+		if line.Quantity < line.Requested {
+			line.Reason = "limited stock"
+			if variant.StockQuantity-inCart[variant.ID] >= line.Requested {
+				line.Reason = "cart limit reached"
+			}
+		}
+
+		_, err = cfg.db.UpsertVariantToCart(r.Context(), database.UpsertVariantToCartParams{
+			CartID:           cartID,
+			ProductVariantID: variant.ID,
+			Quantity:         line.Quantity,
+			PricePerItem:     rate.fromBase(variant.Price),
+		})
+		if err != nil {
+			log.Printf("Reorder add to cart error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Could not add to cart")
+			return
+		}
+		inCart[variant.ID] += line.Quantity
+
+		if line.Quantity < line.Requested {
+			resp.Reduced = append(resp.Reduced, line)
+		} else {
+			resp.Added = append(resp.Added, line)
+		}
+	}
+
+	cartItems, err = cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp.Cart = calculateCartTotal(cartID, rate.Currency, cartItems, 0, discount, cfg.untaxedQuote())
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -16,6 +16,7 @@ type AdminCategoryRequest struct {
 	Slug        string        `json:"slug"`
 	Description string        `json:"description"`
 	ParentID    uuid.NullUUID `json:"parent_id"`
+	TaxClassID  uuid.NullUUID `json:"tax_class_id"`
 }
 
 func (cfg *apiConfig) handleApiAdminGetCategories(w http.ResponseWriter, r *http.Request) {
@@ -54,6 +55,7 @@ func (cfg *apiConfig) handleApiAdminCreateCategory(w http.ResponseWriter, r *htt
 		Slug:        params.Slug,
 		Description: sql.NullString{String: params.Description, Valid: params.Description != ""},
 		ParentID:    params.ParentID,
+		TaxClassID:  params.TaxClassID,
 	})
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
@@ -142,6 +144,7 @@ func (cfg *apiConfig) handleApiAdminUpdateCategory(w http.ResponseWriter, r *htt
 		Slug:        params.Slug,
 		Description: sql.NullString{String: params.Description, Valid: params.Description != ""},
 		ParentID:    params.ParentID,
+		TaxClassID:  params.TaxClassID,
 		ID:          categoryId,
 	})
 
@@ -43,6 +43,13 @@ func (cfg *apiConfig) handleApiAdminCreateCountry(w http.ResponseWriter, r *http
 		return
 	}
 	params.IsoCode = strings.ToUpper(params.IsoCode)
+	if params.Currency != "" {
+		params.Currency, err = normalizeCurrency(params.Currency)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	createdCountry, err := cfg.db.CreateCountry(r.Context(), params)
 
 	if err != nil {
@@ -102,6 +109,13 @@ func (cfg *apiConfig) handleApiAdminUpdateCountry(w http.ResponseWriter, r *http
 	}
 	params.ID = countryId
 	params.IsoCode = strings.ToUpper(params.IsoCode)
+	if params.Currency != "" {
+		params.Currency, err = normalizeCurrency(params.Currency)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	country, err := cfg.db.UpdateCountryById(r.Context(), params)
 
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AdminCouponRequest describes a coupon. Amounts are in the store currency.
+// Without product or category IDs the coupon applies to the whole cart.
+type AdminCouponRequest struct {
+	Code                  string              `json:"code"`
+	Description           string              `json:"description"`
+	Type                  database.CouponType `json:"type"`
+	PercentOff            money.Percent       `json:"percent_off"`
+	AmountOff             money.Amount        `json:"amount_off"`
+	MinSubtotal           money.Amount        `json:"min_subtotal"`
+	StartsAt              *time.Time          `json:"starts_at"`
+	EndsAt                *time.Time          `json:"ends_at"`
+	UsageLimit            *int32              `json:"usage_limit"`
+	UsageLimitPerCustomer *int32              `json:"usage_limit_per_customer"`
+	IsActive              bool                `json:"is_active"`
+	ProductIDs            []uuid.UUID         `json:"product_ids"`
+	CategoryIDs           []uuid.UUID         `json:"category_ids"`
+}
+
+type AdminCouponResponse struct {
+	ID                    uuid.UUID           `json:"id"`
+	Code                  string              `json:"code"`
+	Description           string              `json:"description"`
+	Type                  database.CouponType `json:"type"`
+	PercentOff            money.Percent       `json:"percent_off"`
+	AmountOff             money.Amount        `json:"amount_off"`
+	MinSubtotal           money.Amount        `json:"min_subtotal"`
+	StartsAt              *time.Time          `json:"starts_at"`
+	EndsAt                *time.Time          `json:"ends_at"`
+	UsageLimit            *int32              `json:"usage_limit"`
+	UsageLimitPerCustomer *int32              `json:"usage_limit_per_customer"`
+	TimesUsed             int32               `json:"times_used"`
+	IsActive              bool                `json:"is_active"`
+	ProductIDs            []uuid.UUID         `json:"product_ids"`
+	CategoryIDs           []uuid.UUID         `json:"category_ids"`
+	CreatedAt             time.Time           `json:"created_at"`
+	UpdatedAt             time.Time           `json:"updated_at"`
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func couponResponse(ctx context.Context, q *database.Queries, coupon database.Coupon) (AdminCouponResponse, error) {
+	productIDs, err := q.GetCouponProductIds(ctx, coupon.ID)
+	if err != nil {
+		return AdminCouponResponse{}, fmt.Errorf("failed to load coupon products: %w", err)
+	}
+	categoryIDs, err := q.GetCouponCategoryIds(ctx, coupon.ID)
+	if err != nil {
+		return AdminCouponResponse{}, fmt.Errorf("failed to load coupon categories: %w", err)
+	}
+	if productIDs == nil {
+		productIDs = []uuid.UUID{}
+	}
+	if categoryIDs == nil {
+		categoryIDs = []uuid.UUID{}
+	}
+
+	return AdminCouponResponse{
+		ID:                    coupon.ID,
+		Code:                  coupon.Code,
+		Description:           coupon.Description.String,
+		Type:                  coupon.Type,
+		PercentOff:            coupon.PercentOff,
+		AmountOff:             coupon.AmountOff,
+		MinSubtotal:           coupon.MinSubtotal,
+		StartsAt:              nullTimePtr(coupon.StartsAt),
+		EndsAt:                nullTimePtr(coupon.EndsAt),
+		UsageLimit:            nullInt32Ptr(coupon.UsageLimit),
+		UsageLimitPerCustomer: nullInt32Ptr(coupon.UsageLimitPerCustomer),
+		TimesUsed:             coupon.TimesUsed,
+		IsActive:              coupon.IsActive,
+		ProductIDs:            productIDs,
+		CategoryIDs:           categoryIDs,
+		CreatedAt:             coupon.CreatedAt,
+		UpdatedAt:             coupon.UpdatedAt,
+	}, nil
+}
+
+func decodeCouponRequest(r *http.Request) (AdminCouponRequest, error) {
+	var params AdminCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		if errors.Is(err, money.ErrInvalidPercent) {
+			return params, validationError("percent_off must be a percentage between 0 and 100")
+		}
+		return params, validationError("invalid JSON body")
+	}
+
+	params.Code = strings.TrimSpace(params.Code)
+	params.Description = strings.TrimSpace(params.Description)
+	if params.Code == "" {
+		return params, validationError("code cannot be empty")
+	}
+
+	switch params.Type {
+	case database.CouponTypePercentage:
+		if params.PercentOff.IsZero() || params.PercentOff > money.HundredPercent {
+			return params, validationError("percent_off must be a percentage between 0 and 100")
+		}
+	case database.CouponTypeFixedAmount:
+		if !params.AmountOff.IsPositive() {
+			return params, validationError("amount_off must be greater than zero")
+		}
+	case database.CouponTypeFreeShipping:
+	default:
+		return params, validationError("type must be percentage, fixed_amount or free_shipping")
+	}
+
+	if params.MinSubtotal.IsNegative() {
+		return params, validationError("min_subtotal cannot be negative")
+	}
+	if params.StartsAt != nil && params.EndsAt != nil && !params.EndsAt.After(*params.StartsAt) {
+		return params, validationError("ends_at must be after starts_at")
+	}
+	if params.UsageLimit != nil && *params.UsageLimit <= 0 {
+		return params, validationError("usage_limit must be greater than zero")
+	}
+	if params.UsageLimitPerCustomer != nil && *params.UsageLimitPerCustomer <= 0 {
+		return params, validationError("usage_limit_per_customer must be greater than zero")
+	}
+	return params, nil
+}
+
+// couponParams maps a request onto the stored columns. Only the amount for the
+// coupon's type is kept, and times are stored in UTC like the rest of the schema.
+func couponParams(req AdminCouponRequest) database.CreateCouponParams {
+	params := database.CreateCouponParams{
+		Code:        req.Code,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Type:        req.Type,
+		MinSubtotal: req.MinSubtotal,
+		IsActive:    req.IsActive,
+	}
+	switch req.Type {
+	case database.CouponTypePercentage:
+		params.PercentOff = req.PercentOff
+	case database.CouponTypeFixedAmount:
+		params.AmountOff = req.AmountOff
+	}
+	if req.StartsAt != nil {
+		params.StartsAt = sql.NullTime{Time: req.StartsAt.UTC(), Valid: true}
+	}
+	if req.EndsAt != nil {
+		params.EndsAt = sql.NullTime{Time: req.EndsAt.UTC(), Valid: true}
+	}
+	if req.UsageLimit != nil {
+		params.UsageLimit = sql.NullInt32{Int32: *req.UsageLimit, Valid: true}
+	}
+	if req.UsageLimitPerCustomer != nil {
+		params.UsageLimitPerCustomer = sql.NullInt32{Int32: *req.UsageLimitPerCustomer, Valid: true}
+	}
+	return params
+}
+
+// setCouponEligibility replaces the products and categories a coupon is limited to.
+func setCouponEligibility(ctx context.Context, qtx *database.Queries, couponID uuid.UUID, req AdminCouponRequest) error {
+	if err := qtx.DeleteCouponProducts(ctx, couponID); err != nil {
+		return err
+	}
+	if err := qtx.DeleteCouponCategories(ctx, couponID); err != nil {
+		return err
+	}
+
+	for _, productID := range req.ProductIDs {
+		err := qtx.AddCouponProduct(ctx, database.AddCouponProductParams{CouponID: couponID, ProductID: productID})
+		if err != nil {
+			return err
+		}
+	}
+	for _, categoryID := range req.CategoryIDs {
+		err := qtx.AddCouponCategory(ctx, database.AddCouponCategoryParams{CouponID: couponID, CategoryID: categoryID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleApiAdminGetCoupons(w http.ResponseWriter, r *http.Request) {
+	coupons, err := cfg.db.ListCoupons(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get coupons")
+		return
+	}
+
+	resp := make([]AdminCouponResponse, 0, len(coupons))
+	for _, coupon := range coupons {
+		c, err := couponResponse(r.Context(), cfg.db, coupon)
+		if err != nil {
+			log.Printf("Coupon response error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to get coupons")
+			return
+		}
+		resp = append(resp, c)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleApiAdminCreateCoupon(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeCouponRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create coupon")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+	coupon, err := qtx.CreateCoupon(r.Context(), couponParams(req))
+	if err != nil {
+		respondWithCouponError(w, err, "Failed to create coupon")
+		return
+	}
+
+	if err := setCouponEligibility(r.Context(), qtx, coupon.ID, req); err != nil {
+		respondWithCouponError(w, err, "Failed to create coupon")
+		return
+	}
+
+	resp, err := couponResponse(r.Context(), qtx, coupon)
+	if err != nil {
+		log.Printf("Coupon response error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create coupon")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create coupon")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateCoupon(w http.ResponseWriter, r *http.Request) {
+	couponID, err := uuid.Parse(r.PathValue("couponId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid coupon ID")
+		return
+	}
+
+	req, err := decodeCouponRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update coupon")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+	params := couponParams(req)
+	coupon, err := qtx.UpdateCoupon(r.Context(), database.UpdateCouponParams{
+		Code:                  params.Code,
+		Description:           params.Description,
+		Type:                  params.Type,
+		PercentOff:            params.PercentOff,
+		AmountOff:             params.AmountOff,
+		MinSubtotal:           params.MinSubtotal,
+		StartsAt:              params.StartsAt,
+		EndsAt:                params.EndsAt,
+		UsageLimit:            params.UsageLimit,
+		UsageLimitPerCustomer: params.UsageLimitPerCustomer,
+		IsActive:              params.IsActive,
+		ID:                    couponID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Coupon not found")
+			return
+		}
+		respondWithCouponError(w, err, "Failed to update coupon")
+		return
+	}
+
+	if err := setCouponEligibility(r.Context(), qtx, coupon.ID, req); err != nil {
+		respondWithCouponError(w, err, "Failed to update coupon")
+		return
+	}
+
+	resp, err := couponResponse(r.Context(), qtx, coupon)
+	if err != nil {
+		log.Printf("Coupon response error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update coupon")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update coupon")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleApiAdminDeleteCoupon removes a coupon from carts. Orders that used it
+// keep its code and discount.
+func (cfg *apiConfig) handleApiAdminDeleteCoupon(w http.ResponseWriter, r *http.Request) {
+	couponID, err := uuid.Parse(r.PathValue("couponId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid coupon ID")
+		return
+	}
+
+	rows, err := cfg.db.DeleteCoupon(r.Context(), couponID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete coupon")
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Coupon not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func respondWithCouponError(w http.ResponseWriter, err error, msg string) {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			respondWithError(w, http.StatusConflict, "Coupon with the same code already exists")
+			return
+		case "23503":
+			respondWithError(w, http.StatusBadRequest, "Product or category does not exist")
+			return
+		}
+	}
+	respondWithError(w, http.StatusInternalServerError, msg)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+func (cfg *apiConfig) handleApiAdminGetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := cfg.db.GetExchangeRates(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get exchange rates")
+		return
+	}
+
+	if rates == nil {
+		rates = []database.ExchangeRate{}
+	}
+
+	respondWithJSON(w, http.StatusOK, rates)
+}
+
+// handleApiAdminUpsertExchangeRate sets how many units of a currency buy one unit
+// of the store currency. Carts and orders keep the rate they were priced at.
+func (cfg *apiConfig) handleApiAdminUpsertExchangeRate(w http.ResponseWriter, r *http.Request) {
+	currency, err := normalizeCurrency(r.PathValue("currency"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if currency == cfg.storeCurrency {
+		respondWithError(w, http.StatusBadRequest, "The store currency always has a rate of 1")
+		return
+	}
+
+	params := struct {
+		Rate *money.Rate `json:"rate"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		if errors.Is(err, money.ErrInvalidRate) {
+			respondWithError(w, http.StatusBadRequest, "Rate must be a positive number")
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if params.Rate == nil {
+		respondWithError(w, http.StatusBadRequest, "Rate is required")
+		return
+	}
+
+	rate, err := cfg.db.UpsertExchangeRate(r.Context(), database.UpsertExchangeRateParams{
+		Currency: currency,
+		Rate:     *params.Rate,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save exchange rate")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rate)
+}
+
+func (cfg *apiConfig) handleApiAdminDeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	currency, err := normalizeCurrency(r.PathValue("currency"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := cfg.db.DeleteExchangeRate(r.Context(), currency)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete exchange rate")
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Exchange rate not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
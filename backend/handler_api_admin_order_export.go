@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// exportFlushEvery controls how many rows are buffered before they are flushed to the client.
+const exportFlushEvery = 500
+
+// exportPageSize is how many rows are read from the database at a time, so exports
+// of any size run with constant memory.
+const exportPageSize = 1000
+
+type OrderExportRecord struct {
+	ID                  uuid.UUID    `json:"id"`
+	OrderNumber         string       `json:"order_number"`
+	CreatedAt           time.Time    `json:"created_at"`
+	Status              string       `json:"status"`
+	PaymentStatus       string       `json:"payment_status"`
+	CustomerEmail       string       `json:"customer_email"`
+	UserEmail           string       `json:"user_email"`
+	ShippingName        string       `json:"shipping_name"`
+	ShippingAddress     string       `json:"shipping_address"`
+	ShippingCity        string       `json:"shipping_city"`
+	ShippingPostalCode  string       `json:"shipping_postal_code"`
+	ShippingCountryCode string       `json:"shipping_country_code"`
+	ShippingPhone       string       `json:"shipping_phone"`
+	BillingName         string       `json:"billing_name"`
+	BillingAddress      string       `json:"billing_address"`
+	BillingCity         string       `json:"billing_city"`
+	BillingPostalCode   string       `json:"billing_postal_code"`
+	BillingCountryCode  string       `json:"billing_country_code"`
+	VatID               string       `json:"vat_id"`
+	ShippingMethodName  string       `json:"shipping_method_name"`
+	PaymentMethodName   string       `json:"payment_method_name"`
+	ItemCount           int32        `json:"item_count"`
+	ShippingPrice       money.Amount `json:"shipping_price"`
+	CouponCode          string       `json:"coupon_code"`
+	DiscountTotal       money.Amount `json:"discount_total"`
+	TaxTotal            money.Amount `json:"tax_total"`
+	ReverseCharge       bool         `json:"reverse_charge"`
+	TotalPrice          money.Amount `json:"total_price"`
+	Currency            string       `json:"currency"`
+	ExchangeRate        money.Rate   `json:"exchange_rate"`
+	BaseShippingPrice   money.Amount `json:"base_shipping_price"`
+	BaseDiscountTotal   money.Amount `json:"base_discount_total"`
+	BaseTaxTotal        money.Amount `json:"base_tax_total"`
+	BaseTotalPrice      money.Amount `json:"base_total_price"`
+	CustomerNote        string       `json:"customer_note"`
+}
+
+var orderExportHeader = []string{
+	"id", "order_number", "created_at", "status", "payment_status", "customer_email", "user_email",
+	"shipping_name", "shipping_address", "shipping_city", "shipping_postal_code", "shipping_country_code", "shipping_phone",
+	"billing_name", "billing_address", "billing_city", "billing_postal_code", "billing_country_code", "vat_id",
+	"shipping_method_name", "payment_method_name", "item_count", "shipping_price", "coupon_code", "discount_total", "tax_total", "reverse_charge", "total_price",
+	"currency", "exchange_rate", "base_shipping_price", "base_discount_total", "base_tax_total", "base_total_price", "customer_note",
+}
+
+// Amounts are exported in the order's currency and converted back to the store
+// currency at the rate the order was placed at.
+func (cfg *apiConfig) newOrderExportRecord(row database.ExportOrdersRow) OrderExportRecord {
+	rate := cfg.storedExchangeRate(row.Currency, row.ExchangeRate)
+	return OrderExportRecord{
+		ID:                  row.ID,
+		OrderNumber:         row.OrderNumber,
+		CreatedAt:           row.CreatedAt,
+		Status:              string(row.Status),
+		PaymentStatus:       string(row.PaymentStatus),
+		CustomerEmail:       row.CustomerEmail,
+		UserEmail:           row.UserEmail.String,
+		ShippingName:        row.ShippingName,
+		ShippingAddress:     row.ShippingAddress,
+		ShippingCity:        row.ShippingCity,
+		ShippingPostalCode:  row.ShippingPostalCode,
+		ShippingCountryCode: row.ShippingCountryCode.String,
+		ShippingPhone:       row.ShippingPhone,
+		BillingName:         row.BillingName,
+		BillingAddress:      row.BillingAddress,
+		BillingCity:         row.BillingCity,
+		BillingPostalCode:   row.BillingPostalCode,
+		BillingCountryCode:  row.BillingCountryCode.String,
+		VatID:               row.VatID.String,
+		ShippingMethodName:  row.ShippingMethodName.String,
+		PaymentMethodName:   row.PaymentMethodName.String,
+		ItemCount:           row.ItemCount,
+		ShippingPrice:       row.ShippingPrice,
+		CouponCode:          row.CouponCode.String,
+		DiscountTotal:       row.DiscountTotal,
+		TaxTotal:            row.TaxTotal,
+		ReverseCharge:       row.ReverseCharge,
+		TotalPrice:          row.TotalPrice,
+		Currency:            rate.Currency,
+		ExchangeRate:        rate.Rate,
+		BaseShippingPrice:   rate.toBase(row.ShippingPrice),
+		BaseDiscountTotal:   rate.toBase(row.DiscountTotal),
+		BaseTaxTotal:        rate.toBase(row.TaxTotal),
+		BaseTotalPrice:      rate.toBase(row.TotalPrice),
+		CustomerNote:        row.CustomerNote.String,
+	}
+}
+
+// csvText neutralises a free-text value for spreadsheets, which run cells that
+// start with a formula character as formulas. The leading quote is not shown.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (rec OrderExportRecord) csvRecord() []string {
+	return []string{
+		rec.ID.String(), csvText(rec.OrderNumber), rec.CreatedAt.Format(time.RFC3339), rec.Status, rec.PaymentStatus, csvText(rec.CustomerEmail), csvText(rec.UserEmail),
+		csvText(rec.ShippingName), csvText(rec.ShippingAddress), csvText(rec.ShippingCity), csvText(rec.ShippingPostalCode), rec.ShippingCountryCode, csvText(rec.ShippingPhone),
+		csvText(rec.BillingName), csvText(rec.BillingAddress), csvText(rec.BillingCity), csvText(rec.BillingPostalCode), rec.BillingCountryCode, csvText(rec.VatID),
+		csvText(rec.ShippingMethodName), csvText(rec.PaymentMethodName), strconv.Itoa(int(rec.ItemCount)), rec.ShippingPrice.String(), csvText(rec.CouponCode), rec.DiscountTotal.String(), rec.TaxTotal.String(), strconv.FormatBool(rec.ReverseCharge), rec.TotalPrice.String(),
+		rec.Currency, rec.ExchangeRate.String(), rec.BaseShippingPrice.String(), rec.BaseDiscountTotal.String(), rec.BaseTaxTotal.String(), rec.BaseTotalPrice.String(), csvText(rec.CustomerNote),
+	}
+}
+
+type OrderItemExportRecord struct {
+	OrderID       uuid.UUID    `json:"order_id"`
+	OrderNumber   string       `json:"order_number"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Status        string       `json:"status"`
+	PaymentStatus string       `json:"payment_status"`
+	CustomerEmail string       `json:"customer_email"`
+	VariantID     uuid.UUID    `json:"variant_id"`
+	Sku           string       `json:"sku"`
+	ProductName   string       `json:"product_name"`
+	VariantName   string       `json:"variant_name"`
+	Quantity      int32        `json:"quantity"`
+	PricePerItem  money.Amount `json:"price_per_item"`
+	LineTotal     money.Amount `json:"line_total"`
+	Currency      string       `json:"currency"`
+	ExchangeRate  money.Rate   `json:"exchange_rate"`
+	BaseLineTotal money.Amount `json:"base_line_total"`
+}
+
+var orderItemExportHeader = []string{
+	"order_id", "order_number", "created_at", "status", "payment_status", "customer_email",
+	"variant_id", "sku", "product_name", "variant_name", "quantity", "price_per_item", "line_total",
+	"currency", "exchange_rate", "base_line_total",
+}
+
+func (cfg *apiConfig) newOrderItemExportRecord(row database.ExportOrderItemsRow) OrderItemExportRecord {
+	rate := cfg.storedExchangeRate(row.Currency, row.ExchangeRate)
+	lineTotal := row.PricePerItem.Mul(int64(row.Quantity))
+	return OrderItemExportRecord{
+		OrderID:       row.OrderID,
+		OrderNumber:   row.OrderNumber,
+		CreatedAt:     row.CreatedAt,
+		Status:        string(row.Status),
+		PaymentStatus: string(row.PaymentStatus),
+		CustomerEmail: row.CustomerEmail,
+		VariantID:     row.ProductVariantID,
+		Sku:           row.Sku,
+		ProductName:   row.ProductName,
+		VariantName:   row.VariantName.String,
+		Quantity:      row.Quantity,
+		PricePerItem:  row.PricePerItem,
+		LineTotal:     lineTotal,
+		Currency:      rate.Currency,
+		ExchangeRate:  rate.Rate,
+		BaseLineTotal: rate.toBase(lineTotal),
+	}
+}
+
+func (rec OrderItemExportRecord) csvRecord() []string {
+	return []string{
+		rec.OrderID.String(), csvText(rec.OrderNumber), rec.CreatedAt.Format(time.RFC3339), rec.Status, rec.PaymentStatus, csvText(rec.CustomerEmail),
+		rec.VariantID.String(), csvText(rec.Sku), csvText(rec.ProductName), csvText(rec.VariantName), strconv.Itoa(int(rec.Quantity)), rec.PricePerItem.String(), rec.LineTotal.String(),
+		rec.Currency, rec.ExchangeRate.String(), rec.BaseLineTotal.String(),
+	}
+}
+
+type csvExportRecord interface {
+	csvRecord() []string
+}
+
+// exportStream writes records as CSV or JSON Lines, flushing to the client in batches.
+type exportStream[T csvExportRecord] struct {
+	w       *bufio.Writer
+	rc      *http.ResponseController
+	csv     *csv.Writer
+	json    *json.Encoder
+	pending int
+}
+
+func newExportStream[T csvExportRecord](w http.ResponseWriter, format string, header []string) (*exportStream[T], error) {
+	s := &exportStream[T]{
+		w:  bufio.NewWriter(w),
+		rc: http.NewResponseController(w),
+	}
+	if format == "csv" {
+		s.csv = csv.NewWriter(s.w)
+		if err := s.csv.Write(header); err != nil {
+			return nil, err
+		}
+	} else {
+		s.json = json.NewEncoder(s.w)
+	}
+	return s, nil
+}
+
+func (s *exportStream[T]) write(rec T) error {
+	if s.csv != nil {
+		if err := s.csv.Write(rec.csvRecord()); err != nil {
+			return err
+		}
+	} else if err := s.json.Encode(rec); err != nil {
+		return err
+	}
+
+	s.pending++
+	if s.pending >= exportFlushEvery {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *exportStream[T]) flush() error {
+	s.pending = 0
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (cfg *apiConfig) handleApiAdminExportOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "jsonl" {
+		respondWithError(w, http.StatusBadRequest, "Invalid format, expected csv or jsonl")
+		return
+	}
+
+	level := q.Get("level")
+	if level == "" {
+		level = "order"
+	}
+	if level != "order" && level != "item" {
+		respondWithError(w, http.StatusBadRequest, "Invalid level, expected order or item")
+		return
+	}
+
+	filters := parseOrderFilters(r)
+
+	filename := fmt.Sprintf("orders-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	if level == "item" {
+		filename = fmt.Sprintf("order-items-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// Large exports outlive the server's write timeout, so lift it for this response.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Export orders: could not clear write deadline: %v", err)
+	}
+
+	var err error
+	if level == "order" {
+		err = cfg.exportOrders(w, r, format, filters)
+	} else {
+		err = cfg.exportOrderItems(w, r, format, filters)
+	}
+
+	// Headers are already sent once rows are streaming, so failures can only be logged.
+	if err != nil {
+		log.Printf("Export orders error: %v", err)
+	}
+}
+
+func (cfg *apiConfig) exportOrders(w http.ResponseWriter, r *http.Request, format string, filters orderFilters) error {
+	stream, err := newExportStream[OrderExportRecord](w, format, orderExportHeader)
+	if err != nil {
+		return err
+	}
+
+	params := database.ExportOrdersParams{
+		Search:        filters.Search,
+		Status:        filters.Status,
+		PaymentStatus: filters.PaymentStatus,
+		DateFrom:      filters.DateFrom,
+		DateTo:        filters.DateTo,
+		PageSize:      exportPageSize,
+	}
+	for {
+		rows, err := cfg.db.ExportOrders(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := stream.write(cfg.newOrderExportRecord(row)); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < exportPageSize {
+			break
+		}
+		last := rows[len(rows)-1]
+		params.AfterCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: last.ID, Valid: true}
+	}
+
+	return stream.flush()
+}
+
+func (cfg *apiConfig) exportOrderItems(w http.ResponseWriter, r *http.Request, format string, filters orderFilters) error {
+	stream, err := newExportStream[OrderItemExportRecord](w, format, orderItemExportHeader)
+	if err != nil {
+		return err
+	}
+
+	params := database.ExportOrderItemsParams{
+		Search:        filters.Search,
+		Status:        filters.Status,
+		PaymentStatus: filters.PaymentStatus,
+		DateFrom:      filters.DateFrom,
+		DateTo:        filters.DateTo,
+		PageSize:      exportPageSize,
+	}
+	for {
+		rows, err := cfg.db.ExportOrderItems(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := stream.write(cfg.newOrderItemExportRecord(row)); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < exportPageSize {
+			break
+		}
+		last := rows[len(rows)-1]
+		params.AfterCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		params.AfterOrderID = uuid.NullUUID{UUID: last.OrderID, Valid: true}
+		params.AfterSku = sql.NullString{String: last.Sku, Valid: true}
+	}
+
+	return stream.flush()
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+type OrderNoteRequest struct {
+	Body              string `json:"body"`
+	IsCustomerVisible bool   `json:"is_customer_visible"`
+}
+
+type OrderNoteResponse struct {
+	ID                uuid.UUID `json:"id"`
+	Body              string    `json:"body"`
+	IsCustomerVisible bool      `json:"is_customer_visible"`
+	CreatedByEmail    string    `json:"created_by_email,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (cfg *apiConfig) getOrderNotes(ctx context.Context, orderID uuid.UUID) ([]OrderNoteResponse, error) {
+	notes, err := cfg.db.GetOrderNotes(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]OrderNoteResponse, 0, len(notes))
+	for _, note := range notes {
+		resp = append(resp, OrderNoteResponse{
+			ID:                note.ID,
+			Body:              note.Body,
+			IsCustomerVisible: note.IsCustomerVisible,
+			CreatedByEmail:    note.CreatedByEmail.String,
+			CreatedAt:         note.CreatedAt,
+			UpdatedAt:         note.UpdatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// getCustomerOrderNotes returns only the notes staff marked as visible to the customer.
+func (cfg *apiConfig) getCustomerOrderNotes(ctx context.Context, orderID uuid.UUID) ([]OrderNoteResponse, error) {
+	notes, err := cfg.db.GetCustomerVisibleOrderNotes(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]OrderNoteResponse, 0, len(notes))
+	for _, note := range notes {
+		resp = append(resp, OrderNoteResponse{
+			ID:                note.ID,
+			Body:              note.Body,
+			IsCustomerVisible: note.IsCustomerVisible,
+			CreatedAt:         note.CreatedAt,
+			UpdatedAt:         note.UpdatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func decodeOrderNoteRequest(w http.ResponseWriter, r *http.Request) (OrderNoteRequest, bool) {
+	params := OrderNoteRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return params, false
+	}
+
+	params.Body = strings.TrimSpace(params.Body)
+	if params.Body == "" {
+		respondWithError(w, http.StatusBadRequest, "Note body is required")
+		return params, false
+	}
+
+	if utf8.RuneCountInString(params.Body) > maxOrderNoteLength {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Note cannot exceed %d characters", maxOrderNoteLength))
+		return params, false
+	}
+
+	return params, true
+}
+
+func (cfg *apiConfig) handleApiAdminGetOrderNotes(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if _, err := cfg.db.GetOrderById(r.Context(), orderId); err != nil {
+		respondWithError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	notes, err := cfg.getOrderNotes(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get order notes")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, notes)
+}
+
+func (cfg *apiConfig) handleApiAdminCreateOrderNote(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params, ok := decodeOrderNoteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	actor := adminActor(getUserIDFromContext(r.Context()))
+
+	var note database.OrderNote
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		created, err := qtx.CreateOrderNote(r.Context(), database.CreateOrderNoteParams{
+			OrderID:           order.ID,
+			Body:              params.Body,
+			IsCustomerVisible: params.IsCustomerVisible,
+			CreatedBy:         actor.nullID(),
+		})
+		if err != nil {
+			return order, fmt.Errorf("failed to create note: %w", err)
+		}
+		note = created
+
+		return order, recordOrderEvent(r.Context(), qtx, order.ID, database.OrderEventTypeNoteAdded, actor, map[string]any{
+			"note_id":             created.ID,
+			"is_customer_visible": created.IsCustomerVisible,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		log.Printf("Create order note error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create order note")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, OrderNoteResponse{
+		ID:                note.ID,
+		Body:              note.Body,
+		IsCustomerVisible: note.IsCustomerVisible,
+		CreatedAt:         note.CreatedAt,
+		UpdatedAt:         note.UpdatedAt,
+	})
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateOrderNote(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	noteId, err := uuid.Parse(r.PathValue("noteId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid note ID")
+		return
+	}
+
+	params, ok := decodeOrderNoteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	note, err := cfg.db.UpdateOrderNote(r.Context(), database.UpdateOrderNoteParams{
+		Body:              params.Body,
+		IsCustomerVisible: params.IsCustomerVisible,
+		ID:                noteId,
+		OrderID:           orderId,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Note not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update order note")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, OrderNoteResponse{
+		ID:                note.ID,
+		Body:              note.Body,
+		IsCustomerVisible: note.IsCustomerVisible,
+		CreatedAt:         note.CreatedAt,
+		UpdatedAt:         note.UpdatedAt,
+	})
+}
+
+func (cfg *apiConfig) handleApiAdminDeleteOrderNote(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	noteId, err := uuid.Parse(r.PathValue("noteId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid note ID")
+		return
+	}
+
+	rows, err := cfg.db.DeleteOrderNote(r.Context(), database.DeleteOrderNoteParams{
+		ID:      noteId,
+		OrderID: orderId,
+	})
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete order note")
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Note not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
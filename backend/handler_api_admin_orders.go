@@ -2,18 +2,29 @@ package main
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
 	"github.com/google/uuid"
 )
 
-func (cfg *apiConfig) handleApiAdminListOrders(w http.ResponseWriter, r *http.Request) {
-	page, limit := getPaginationParams(r)
-	offset := (page - 1) * limit
+// orderFilters holds the list filters shared by the admin order list and export.
+type orderFilters struct {
+	Search        sql.NullString
+	Status        database.NullOrderStatus
+	PaymentStatus database.NullPaymentStatus
+	DateFrom      sql.NullTime
+	DateTo        sql.NullTime
+}
 
+func parseOrderFilters(r *http.Request) orderFilters {
 	q := r.URL.Query()
 
 	var statusParam string
@@ -45,14 +56,38 @@ func (cfg *apiConfig) handleApiAdminListOrders(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	ctx := r.Context()
-
-	count, err := cfg.db.CountOrders(ctx, database.CountOrdersParams{
+	return orderFilters{
 		Search:        sql.NullString{String: searchParam, Valid: searchParam != ""},
 		Status:        database.NullOrderStatus{OrderStatus: database.OrderStatus(statusParam), Valid: statusParam != ""},
 		PaymentStatus: database.NullPaymentStatus{PaymentStatus: database.PaymentStatus(paymentStatusParam), Valid: paymentStatusParam != ""},
 		DateFrom:      sql.NullTime{Time: dateFromParam, Valid: !dateFromParam.IsZero()},
 		DateTo:        sql.NullTime{Time: dateToParam, Valid: !dateToParam.IsZero()},
+	}
+}
+
+// AdminOrderListItem adds store currency totals to a listed order so reports can
+// sum orders placed in different currencies.
+type AdminOrderListItem struct {
+	database.ListOrdersRow
+	Currency          string       `json:"currency"`
+	BaseShippingPrice money.Amount `json:"base_shipping_price"`
+	BaseTotalPrice    money.Amount `json:"base_total_price"`
+}
+
+func (cfg *apiConfig) handleApiAdminListOrders(w http.ResponseWriter, r *http.Request) {
+	page, limit := getPaginationParams(r)
+	offset := (page - 1) * limit
+
+	filters := parseOrderFilters(r)
+
+	ctx := r.Context()
+
+	count, err := cfg.db.CountOrders(ctx, database.CountOrdersParams{
+		Search:        filters.Search,
+		Status:        filters.Status,
+		PaymentStatus: filters.PaymentStatus,
+		DateFrom:      filters.DateFrom,
+		DateTo:        filters.DateTo,
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to count orders")
@@ -64,11 +99,11 @@ func (cfg *apiConfig) handleApiAdminListOrders(w http.ResponseWriter, r *http.Re
 		database.ListOrdersParams{
 			Limit:         limit,
 			Offset:        offset,
-			Search:        sql.NullString{String: searchParam, Valid: searchParam != ""},
-			Status:        database.NullOrderStatus{OrderStatus: database.OrderStatus(statusParam), Valid: statusParam != ""},
-			PaymentStatus: database.NullPaymentStatus{PaymentStatus: database.PaymentStatus(paymentStatusParam), Valid: paymentStatusParam != ""},
-			DateFrom:      sql.NullTime{Time: dateFromParam, Valid: !dateFromParam.IsZero()},
-			DateTo:        sql.NullTime{Time: dateToParam, Valid: !dateToParam.IsZero()},
+			Search:        filters.Search,
+			Status:        filters.Status,
+			PaymentStatus: filters.PaymentStatus,
+			DateFrom:      filters.DateFrom,
+			DateTo:        filters.DateTo,
 		},
 	)
 	if err != nil {
@@ -77,11 +112,18 @@ func (cfg *apiConfig) handleApiAdminListOrders(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if orders == nil {
-		orders = []database.ListOrdersRow{}
+	items := make([]AdminOrderListItem, 0, len(orders))
+	for _, order := range orders {
+		rate := cfg.storedExchangeRate(order.Currency, order.ExchangeRate)
+		items = append(items, AdminOrderListItem{
+			ListOrdersRow:     order,
+			Currency:          rate.Currency,
+			BaseShippingPrice: rate.toBase(order.ShippingPrice),
+			BaseTotalPrice:    rate.toBase(order.TotalPrice),
+		})
 	}
 
-	response := NewPaginatedResponse(orders, page, limit, count)
+	response := NewPaginatedResponse(items, page, limit, count)
 	respondWithJSON(w, http.StatusOK, response)
 }
 
@@ -111,12 +153,74 @@ func (cfg *apiConfig) handleApiAdminGetSingleOrder(w http.ResponseWriter, r *htt
 		orderItems = []database.GetOrderItemsByOrderIdWithVariantsRow{}
 	}
 
+	timeline, err := cfg.getOrderTimeline(r.Context(), orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order timeline")
+		return
+	}
+
+	refunds, err := cfg.getOrderRefunds(r.Context(), orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order refunds")
+		return
+	}
+
+	shipments, err := cfg.getOrderShipments(r.Context(), orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order shipments")
+		return
+	}
+
+	notes, err := cfg.getOrderNotes(r.Context(), orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order notes")
+		return
+	}
+
+	returns, err := cfg.getOrderReturns(r.Context(), orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order returns")
+		return
+	}
+
+	paymentTransactions, err := cfg.getOrderPaymentTransactions(r.Context(), orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get payment transactions")
+		return
+	}
+
+	taxLines, err := getOrderTaxLines(r.Context(), cfg.db, orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order tax lines")
+		return
+	}
+
+	discounts, err := getOrderDiscountLines(r.Context(), cfg.db, orderId)
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to get order discounts")
+		return
+	}
+
+	rate := cfg.storedExchangeRate(order.Currency, order.ExchangeRate)
+
 	resp := struct {
 		OrderID            uuid.UUID                                        `json:"order_id"`
+		OrderNumber        string                                           `json:"order_number"`
 		UserID             uuid.NullUUID                                    `json:"user_id"`
 		Status             string                                           `json:"status"`
 		PaymentStatus      string                                           `json:"payment_status"`
-		TotalPrice         float64                                          `json:"total_price"`
+		TotalPrice         money.Amount                                     `json:"total_price"`
+		Currency           string                                           `json:"currency"`
+		ExchangeRate       money.Rate                                       `json:"exchange_rate"`
+		BaseTotalPrice     money.Amount                                     `json:"base_total_price"`
 		CreatedAt          time.Time                                        `json:"created_at"`
 		UpdatedAt          time.Time                                        `json:"updated_at"`
 		CustomerEmail      string                                           `json:"customer_email"`
@@ -130,21 +234,43 @@ func (cfg *apiConfig) handleApiAdminGetSingleOrder(w http.ResponseWriter, r *htt
 		BillingCity        string                                           `json:"billing_city"`
 		BillingPostalCode  string                                           `json:"billing_postal_code"`
 		ShippingOptionID   uuid.UUID                                        `json:"shipping_option_id"`
-		ShippingPrice      float64                                          `json:"shipping_price"`
+		ShippingPrice      money.Amount                                     `json:"shipping_price"`
+		BaseShippingPrice  money.Amount                                     `json:"base_shipping_price"`
+		CouponCode         string                                           `json:"coupon_code"`
+		DiscountTotal      money.Amount                                     `json:"discount_total"`
+		BaseDiscountTotal  money.Amount                                     `json:"base_discount_total"`
+		Discounts          []DiscountLine                                   `json:"discounts"`
+		TaxTotal           money.Amount                                     `json:"tax_total"`
+		BaseTaxTotal       money.Amount                                     `json:"base_tax_total"`
+		PricesIncludeTax   bool                                             `json:"prices_include_tax"`
+		TaxLines           []TaxLine                                        `json:"tax_lines"`
+		VatID              string                                           `json:"vat_id"`
+		ReverseCharge      bool                                             `json:"reverse_charge"`
 		PaymentOptionID    uuid.UUID                                        `json:"payment_option_id"`
 		ShippingCountryID  uuid.UUID                                        `json:"shipping_country_id"`
 		BillingCountryID   uuid.UUID                                        `json:"billing_country_id"`
+		CustomerNote       string                                           `json:"customer_note"`
 		ShippingMethodName sql.NullString                                   `json:"shipping_method_name"`
 		PaymentMethodName  sql.NullString                                   `json:"payment_method_name"`
 		UserEmail          sql.NullString                                   `json:"user_email"`
 		UserCreatedAt      sql.NullTime                                     `json:"user_created_at"`
 		OrderItems         []database.GetOrderItemsByOrderIdWithVariantsRow `json:"order_items"`
+		Timeline           []OrderEventResponse                             `json:"timeline"`
+		Refunds            []RefundResponse                                 `json:"refunds"`
+		Shipments          []ShipmentResponse                               `json:"shipments"`
+		Notes              []OrderNoteResponse                              `json:"notes"`
+		Returns            []ReturnResponse                                 `json:"returns"`
+		Payments           []PaymentTransactionResponse                     `json:"payment_transactions"`
 	}{
 		OrderID:            order.ID,
+		OrderNumber:        order.OrderNumber,
 		UserID:             order.UserID,
 		Status:             string(order.Status),
 		PaymentStatus:      string(order.PaymentStatus),
 		TotalPrice:         order.TotalPrice,
+		Currency:           rate.Currency,
+		ExchangeRate:       rate.Rate,
+		BaseTotalPrice:     rate.toBase(order.TotalPrice),
 		CreatedAt:          order.CreatedAt,
 		UpdatedAt:          order.UpdatedAt,
 		CustomerEmail:      order.CustomerEmail,
@@ -159,14 +285,264 @@ func (cfg *apiConfig) handleApiAdminGetSingleOrder(w http.ResponseWriter, r *htt
 		BillingPostalCode:  order.BillingPostalCode,
 		ShippingOptionID:   order.ShippingOptionID,
 		ShippingPrice:      order.ShippingPrice,
+		BaseShippingPrice:  rate.toBase(order.ShippingPrice),
+		CouponCode:         order.CouponCode.String,
+		DiscountTotal:      order.DiscountTotal,
+		BaseDiscountTotal:  rate.toBase(order.DiscountTotal),
+		Discounts:          discounts,
+		TaxTotal:           order.TaxTotal,
+		BaseTaxTotal:       rate.toBase(order.TaxTotal),
+		PricesIncludeTax:   order.PricesIncludeTax,
+		TaxLines:           taxLines,
+		VatID:              order.VatID.String,
+		ReverseCharge:      order.ReverseCharge,
 		PaymentOptionID:    order.PaymentOptionID,
 		ShippingCountryID:  order.ShippingCountryID,
 		BillingCountryID:   order.BillingCountryID,
+		CustomerNote:       order.CustomerNote.String,
 		ShippingMethodName: order.ShippingMethodName,
 		PaymentMethodName:  order.PaymentMethodName,
 		UserEmail:          order.UserEmail,
 		UserCreatedAt:      order.UserCreatedAt,
 		OrderItems:         orderItems,
+		Timeline:           timeline,
+		Refunds:            refunds,
+		Shipments:          shipments,
+		Notes:              notes,
+		Returns:            returns,
+		Payments:           paymentTransactions,
 	}
 	respondWithJSON(w, http.StatusOK, resp)
 }
+
+func (cfg *apiConfig) handleApiAdminUpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params := struct {
+		Status database.OrderStatus `json:"status"`
+	}{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !isValidOrderStatus(params.Status) {
+		respondWithError(w, http.StatusBadRequest, "Invalid order status")
+		return
+	}
+
+	actor := adminActor(getUserIDFromContext(r.Context()))
+
+	updated, err := cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		if params.Status == database.OrderStatusCancelled {
+			return cfg.cancelOrder(r.Context(), qtx, order, actor)
+		}
+		return transitionOrderStatus(r.Context(), qtx, order, params.Status, actor)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		if errors.Is(err, errInvalidStatusTransition) {
+			respondWithError(w, http.StatusConflict, fmt.Sprintf("Cannot change order status from %s to %s", updated.Status, params.Status))
+			return
+		}
+		log.Printf("Update order status error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update order status")
+		return
+	}
+
+	if params.Status == database.OrderStatusCancelled {
+		cfg.respondWithCancelledOrder(w, r, updated)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateOrderPaymentStatus(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params := struct {
+		PaymentStatus database.PaymentStatus `json:"payment_status"`
+	}{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !isValidPaymentStatus(params.PaymentStatus) {
+		respondWithError(w, http.StatusBadRequest, "Invalid payment status")
+		return
+	}
+
+	if params.PaymentStatus == database.PaymentStatusRefunded || params.PaymentStatus == database.PaymentStatusPartiallyRefunded {
+		respondWithError(w, http.StatusBadRequest, "Refunded payment statuses are set by creating a refund")
+		return
+	}
+
+	// The status is not set directly: the admin's confirmation is recorded on the
+	// payment ledger and the status derived from it.
+	txnType, txnStatus := database.PaymentTransactionTypeAuthorization, database.PaymentTransactionStatusPending
+	switch params.PaymentStatus {
+	case database.PaymentStatusPaid:
+		txnType, txnStatus = database.PaymentTransactionTypeCapture, database.PaymentTransactionStatusSucceeded
+	case database.PaymentStatusFailed:
+		txnStatus = database.PaymentTransactionStatusFailed
+	}
+
+	updated, err := cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		if !canTransitionPaymentStatus(order.PaymentStatus, params.PaymentStatus) {
+			return order, fmt.Errorf("%w: %s -> %s", errInvalidStatusTransition, order.PaymentStatus, params.PaymentStatus)
+		}
+
+		provider := payments.ManualKey
+		if order.PaymentProvider.Valid {
+			provider = order.PaymentProvider.String
+		}
+
+		// Only manual payments are confirmed by hand. Other providers hold the
+		// money, so marking the order paid captures its open authorization.
+		if provider != payments.ManualKey && params.PaymentStatus == database.PaymentStatusPaid {
+			return cfg.settleAuthorization(r.Context(), qtx, order, database.PaymentTransactionTypeCapture, 0, adminActor(getUserIDFromContext(r.Context())))
+		}
+
+		return recordPaymentTransaction(r.Context(), qtx, order, database.CreatePaymentTransactionParams{
+			Provider:          provider,
+			Type:              txnType,
+			Status:            txnStatus,
+			Amount:            order.TotalPrice,
+			Currency:          cfg.orderExchangeRate(order).Currency,
+			ProviderReference: order.PaymentReference,
+		}, adminActor(getUserIDFromContext(r.Context())))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		if errors.Is(err, errInvalidStatusTransition) {
+			respondWithError(w, http.StatusConflict, fmt.Sprintf("Cannot change payment status from %s to %s", updated.PaymentStatus, params.PaymentStatus))
+			return
+		}
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusConflict, "Only an authorized payment can be marked as paid for this provider")
+			return
+		}
+		log.Printf("Update payment status error: %v", err)
+		if errors.Is(err, errPaymentProvider) {
+			respondWithError(w, http.StatusBadGateway, "Payment provider rejected the capture")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update payment status")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (cfg *apiConfig) handleApiAdminCancelOrder(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	updated, err := cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		return cfg.cancelOrder(r.Context(), qtx, order, adminActor(getUserIDFromContext(r.Context())))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		if errors.Is(err, errInvalidStatusTransition) {
+			respondWithError(w, http.StatusConflict, fmt.Sprintf("Cannot cancel an order with status %s", updated.Status))
+			return
+		}
+		log.Printf("Cancel order error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to cancel order")
+		return
+	}
+
+	cfg.respondWithCancelledOrder(w, r, updated)
+}
+
+// respondWithCancelledOrder sends the void or refund queued by cancelling the
+// order and responds with the order as it stands afterwards. The order stays
+// cancelled if the provider rejects them.
+func (cfg *apiConfig) respondWithCancelledOrder(w http.ResponseWriter, r *http.Request, order database.Order) {
+	updated, err := cfg.runPaymentOperations(r.Context(), order.ID)
+	if err != nil {
+		log.Printf("Cancel order %s payment error: %v", order.ID, err)
+		if errors.Is(err, errPaymentProvider) {
+			respondWithError(w, http.StatusBadGateway, "Order cancelled, but the payment provider rejected the refund or void")
+			return
+		}
+		// Still pending; the payment operation worker resends it.
+		respondWithJSON(w, http.StatusOK, order)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, updated)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateOrder(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params := OrderEditRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if params.ShippingAddress == nil && params.BillingAddress == nil && params.ShippingMethodID == nil && len(params.Items) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Nothing to update")
+		return
+	}
+
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		return cfg.editOrder(r.Context(), qtx, order, params, adminActor(getUserIDFromContext(r.Context())))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		if errors.Is(err, errOrderPaymentHeld) {
+			respondWithError(w, http.StatusConflict, "The order total cannot change once its payment is captured or authorized")
+			return
+		}
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusBadRequest, vErr.Error())
+			return
+		}
+		log.Printf("Update order error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update order")
+		return
+	}
+
+	cfg.handleApiAdminGetSingleOrder(w, r)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
+	"github.com/google/uuid"
+)
+
+type PaymentTransactionResponse struct {
+	ID                uuid.UUID       `json:"id"`
+	OrderID           uuid.UUID       `json:"order_id"`
+	Provider          string          `json:"provider"`
+	Type              string          `json:"type"`
+	Status            string          `json:"status"`
+	Amount            money.Amount    `json:"amount"`
+	Currency          string          `json:"currency"`
+	ProviderReference string          `json:"provider_reference"`
+	RawResponse       json.RawMessage `json:"raw_response"`
+	ErrorMessage      string          `json:"error_message"`
+	CreatedByEmail    string          `json:"created_by_email"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+func (cfg *apiConfig) getOrderPaymentTransactions(ctx context.Context, orderID uuid.UUID) ([]PaymentTransactionResponse, error) {
+	txns, err := cfg.db.GetPaymentTransactionsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]PaymentTransactionResponse, 0, len(txns))
+	for _, txn := range txns {
+		resp = append(resp, PaymentTransactionResponse{
+			ID:                txn.ID,
+			OrderID:           txn.OrderID,
+			Provider:          txn.Provider,
+			Type:              string(txn.Type),
+			Status:            string(txn.Status),
+			Amount:            txn.Amount,
+			Currency:          txn.Currency,
+			ProviderReference: txn.ProviderReference.String,
+			RawResponse:       txn.RawResponse,
+			ErrorMessage:      txn.ErrorMessage.String,
+			CreatedByEmail:    txn.CreatedByEmail.String,
+			CreatedAt:         txn.CreatedAt,
+			UpdatedAt:         txn.UpdatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (cfg *apiConfig) handleApiAdminGetOrderPaymentTransactions(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if _, err := cfg.db.GetOrderById(r.Context(), orderId); err != nil {
+		respondWithError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	txns, err := cfg.getOrderPaymentTransactions(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get payment transactions")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, txns)
+}
+
+// settleAuthorization captures or voids a locked order's open authorization with
+// its provider and records the outcome on the ledger.
+func (cfg *apiConfig) settleAuthorization(ctx context.Context, qtx *database.Queries, order database.Order, txnType database.PaymentTransactionType, amount money.Amount, actor orderActor) (database.Order, error) {
+	txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+
+	auth, ok := openAuthorization(txns)
+	if !ok {
+		return order, validationError("order has no authorized payment")
+	}
+
+	provider, err := cfg.orderPaymentProvider(ctx, order)
+	if err != nil {
+		return order, err
+	}
+
+	reference := auth.ProviderReference.String
+	var result payments.Result
+	if txnType == database.PaymentTransactionTypeCapture {
+		if amount == 0 {
+			amount = auth.Amount
+		}
+		if amount < 0 || amount > auth.Amount {
+			return order, validationError("capture amount exceeds the authorized amount")
+		}
+		result, err = provider.Capture(ctx, reference, amount, "capture-"+auth.ID.String())
+	} else {
+		amount = auth.Amount
+		result, err = provider.Void(ctx, reference, "void-"+auth.ID.String())
+	}
+	if err != nil {
+		return order, fmt.Errorf("%w: %s failed: %v", errPaymentProvider, txnType, err)
+	}
+
+	return recordPaymentTransaction(ctx, qtx, order, database.CreatePaymentTransactionParams{
+		Provider:          auth.Provider,
+		Type:              txnType,
+		Status:            database.PaymentTransactionStatusSucceeded,
+		Amount:            amount,
+		Currency:          auth.Currency,
+		ProviderReference: auth.ProviderReference,
+		RawResponse:       result.Raw,
+	}, actor)
+}
+
+func (cfg *apiConfig) handleApiAdminCapturePayment(w http.ResponseWriter, r *http.Request) {
+	params := struct {
+		Amount money.Amount `json:"amount"`
+	}{}
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	}
+
+	cfg.settlePayment(w, r, database.PaymentTransactionTypeCapture, params.Amount)
+}
+
+func (cfg *apiConfig) handleApiAdminVoidPayment(w http.ResponseWriter, r *http.Request) {
+	cfg.settlePayment(w, r, database.PaymentTransactionTypeVoid, 0)
+}
+
+func (cfg *apiConfig) settlePayment(w http.ResponseWriter, r *http.Request, txnType database.PaymentTransactionType, amount money.Amount) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	updated, err := cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		return cfg.settleAuthorization(r.Context(), qtx, order, txnType, amount, adminActor(getUserIDFromContext(r.Context())))
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusBadRequest, vErr.Error())
+			return
+		}
+		log.Printf("Payment %s error: %v", txnType, err)
+		if errors.Is(err, errPaymentProvider) {
+			respondWithError(w, http.StatusBadGateway, "Payment provider rejected the "+string(txnType))
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+string(txnType)+" payment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, updated)
+}
@@ -135,6 +135,7 @@ func (cfg *apiConfig) handleApiCreateProduct(w http.ResponseWriter, r *http.Requ
 		Description string         `json:"description"`
 		ImageURL    string         `json:"image_url"`
 		CategoryID  uuid.UUID      `json:"category_id"`
+		TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 		Variant     VariantRequest `json:"product_variant"`
 	}
 
@@ -168,6 +169,7 @@ func (cfg *apiConfig) handleApiCreateProduct(w http.ResponseWriter, r *http.Requ
 		Description: sql.NullString{Valid: params.Description != "", String: params.Description},
 		ImageUrl:    sql.NullString{Valid: params.ImageURL != "", String: params.ImageURL},
 		CategoryID:  params.CategoryID,
+		TaxClassID:  params.TaxClassID,
 	})
 
 	if err != nil {
@@ -217,6 +219,7 @@ func (cfg *apiConfig) handleApiCreateProduct(w http.ResponseWriter, r *http.Requ
 	type response struct {
 		ID          uuid.UUID               `json:"id"`
 		CategoryID  uuid.UUID               `json:"category_id"`
+		TaxClassID  uuid.NullUUID           `json:"tax_class_id"`
 		Name        string                  `json:"name"`
 		Slug        string                  `json:"slug"`
 		ImageUrl    sql.NullString          `json:"image_url"`
@@ -229,6 +232,7 @@ func (cfg *apiConfig) handleApiCreateProduct(w http.ResponseWriter, r *http.Requ
 	resp := response{
 		ID:          product.ID,
 		CategoryID:  product.CategoryID,
+		TaxClassID:  product.TaxClassID,
 		Name:        product.Name,
 		Slug:        product.Slug,
 		ImageUrl:    product.ImageUrl,
@@ -250,11 +254,12 @@ func (cfg *apiConfig) handleApiUpdateProduct(w http.ResponseWriter, r *http.Requ
 	}
 
 	type parameters struct {
-		Name        string    `json:"name"`
-		Slug        string    `json:"slug"`
-		Description string    `json:"description"`
-		ImageURL    string    `json:"image_url"`
-		CategoryID  uuid.UUID `json:"category_id"`
+		Name        string        `json:"name"`
+		Slug        string        `json:"slug"`
+		Description string        `json:"description"`
+		ImageURL    string        `json:"image_url"`
+		CategoryID  uuid.UUID     `json:"category_id"`
+		TaxClassID  uuid.NullUUID `json:"tax_class_id"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -280,6 +285,7 @@ func (cfg *apiConfig) handleApiUpdateProduct(w http.ResponseWriter, r *http.Requ
 		Description: sql.NullString{Valid: params.Description != "", String: params.Description},
 		ImageUrl:    sql.NullString{Valid: params.ImageURL != "", String: params.ImageURL},
 		CategoryID:  params.CategoryID,
+		TaxClassID:  params.TaxClassID,
 	})
 
 	if err != nil {
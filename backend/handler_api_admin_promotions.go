@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PromotionProduct targets a product. Quantity only matters for bundles.
+type PromotionProduct struct {
+	ProductID uuid.UUID                    `json:"product_id"`
+	Role      database.PromotionTargetRole `json:"role"`
+	Quantity  int32                        `json:"quantity"`
+}
+
+type PromotionCategory struct {
+	CategoryID uuid.UUID                    `json:"category_id"`
+	Role       database.PromotionTargetRole `json:"role"`
+}
+
+// AdminPromotionRequest describes a promotion. Amounts are in the store
+// currency. Targets default to the buy role.
+type AdminPromotionRequest struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        database.PromotionType `json:"type"`
+	Priority    int32                  `json:"priority"`
+	PercentOff  money.Percent          `json:"percent_off"`
+	AmountOff   money.Amount           `json:"amount_off"`
+	MinSubtotal money.Amount           `json:"min_subtotal"`
+	BuyQuantity int32                  `json:"buy_quantity"`
+	GetQuantity int32                  `json:"get_quantity"`
+	BundlePrice money.Amount           `json:"bundle_price"`
+	StartsAt    *time.Time             `json:"starts_at"`
+	EndsAt      *time.Time             `json:"ends_at"`
+	IsActive    bool                   `json:"is_active"`
+	Products    []PromotionProduct     `json:"products"`
+	Categories  []PromotionCategory    `json:"categories"`
+}
+
+type AdminPromotionResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        database.PromotionType `json:"type"`
+	Priority    int32                  `json:"priority"`
+	PercentOff  money.Percent          `json:"percent_off"`
+	AmountOff   money.Amount           `json:"amount_off"`
+	MinSubtotal money.Amount           `json:"min_subtotal"`
+	BuyQuantity int32                  `json:"buy_quantity"`
+	GetQuantity int32                  `json:"get_quantity"`
+	BundlePrice money.Amount           `json:"bundle_price"`
+	StartsAt    *time.Time             `json:"starts_at"`
+	EndsAt      *time.Time             `json:"ends_at"`
+	IsActive    bool                   `json:"is_active"`
+	Products    []PromotionProduct     `json:"products"`
+	Categories  []PromotionCategory    `json:"categories"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+func promotionResponse(ctx context.Context, q *database.Queries, promotion database.Promotion) (AdminPromotionResponse, error) {
+	products, err := q.GetPromotionProducts(ctx, promotion.ID)
+	if err != nil {
+		return AdminPromotionResponse{}, fmt.Errorf("failed to load promotion products: %w", err)
+	}
+	categories, err := q.GetPromotionCategories(ctx, promotion.ID)
+	if err != nil {
+		return AdminPromotionResponse{}, fmt.Errorf("failed to load promotion categories: %w", err)
+	}
+
+	resp := AdminPromotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Description: promotion.Description.String,
+		Type:        promotion.Type,
+		Priority:    promotion.Priority,
+		PercentOff:  promotion.PercentOff,
+		AmountOff:   promotion.AmountOff,
+		MinSubtotal: promotion.MinSubtotal,
+		BuyQuantity: promotion.BuyQuantity,
+		GetQuantity: promotion.GetQuantity,
+		BundlePrice: promotion.BundlePrice,
+		StartsAt:    nullTimePtr(promotion.StartsAt),
+		EndsAt:      nullTimePtr(promotion.EndsAt),
+		IsActive:    promotion.IsActive,
+		Products:    make([]PromotionProduct, 0, len(products)),
+		Categories:  make([]PromotionCategory, 0, len(categories)),
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+	for _, p := range products {
+		resp.Products = append(resp.Products, PromotionProduct{ProductID: p.ProductID, Role: p.Role, Quantity: p.Quantity})
+	}
+	for _, c := range categories {
+		resp.Categories = append(resp.Categories, PromotionCategory{CategoryID: c.CategoryID, Role: c.Role})
+	}
+	return resp, nil
+}
+
+func decodePromotionRequest(r *http.Request) (AdminPromotionRequest, error) {
+	var params AdminPromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		if errors.Is(err, money.ErrInvalidPercent) {
+			return params, validationError("percent_off must be a percentage between 0 and 100")
+		}
+		return params, validationError("invalid JSON body")
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	params.Description = strings.TrimSpace(params.Description)
+	if params.Name == "" {
+		return params, validationError("name cannot be empty")
+	}
+
+	validRole := func(role database.PromotionTargetRole) bool {
+		return role == database.PromotionTargetRoleBuy || role == database.PromotionTargetRoleGet
+	}
+	hasGetTargets := false
+	for i := range params.Products {
+		p := &params.Products[i]
+		if p.Role == "" {
+			p.Role = database.PromotionTargetRoleBuy
+		}
+		if p.Quantity == 0 {
+			p.Quantity = 1
+		}
+		if !validRole(p.Role) {
+			return params, validationError("role must be buy or get")
+		}
+		if p.Quantity < 0 {
+			return params, validationError("product quantity must be positive")
+		}
+		hasGetTargets = hasGetTargets || p.Role == database.PromotionTargetRoleGet
+	}
+	for i := range params.Categories {
+		c := &params.Categories[i]
+		if c.Role == "" {
+			c.Role = database.PromotionTargetRoleBuy
+		}
+		if !validRole(c.Role) {
+			return params, validationError("role must be buy or get")
+		}
+		hasGetTargets = hasGetTargets || c.Role == database.PromotionTargetRoleGet
+	}
+
+	switch params.Type {
+	case database.PromotionTypeBuyXGetY:
+		if params.BuyQuantity <= 0 || params.GetQuantity <= 0 {
+			return params, validationError("buy_quantity and get_quantity must be greater than zero")
+		}
+		if params.PercentOff.IsZero() || params.PercentOff > money.HundredPercent {
+			return params, validationError("percent_off must be a percentage between 0 and 100")
+		}
+	case database.PromotionTypeSpendThreshold:
+		if params.PercentOff.IsZero() == params.AmountOff.IsZero() {
+			return params, validationError("set either percent_off or amount_off")
+		}
+		if params.PercentOff > money.HundredPercent {
+			return params, validationError("percent_off must be a percentage between 0 and 100")
+		}
+		if params.AmountOff.IsNegative() {
+			return params, validationError("amount_off cannot be negative")
+		}
+	case database.PromotionTypeBundlePrice:
+		if len(params.Products) == 0 || len(params.Categories) > 0 {
+			return params, validationError("a bundle is made of products only")
+		}
+		if !params.BundlePrice.IsPositive() {
+			return params, validationError("bundle_price must be greater than zero")
+		}
+	default:
+		return params, validationError("type must be buy_x_get_y, spend_threshold or bundle_price")
+	}
+
+	if hasGetTargets && params.Type != database.PromotionTypeBuyXGetY {
+		return params, validationError("only buy_x_get_y promotions have get targets")
+	}
+	if params.MinSubtotal.IsNegative() {
+		return params, validationError("min_subtotal cannot be negative")
+	}
+	if params.StartsAt != nil && params.EndsAt != nil && !params.EndsAt.After(*params.StartsAt) {
+		return params, validationError("ends_at must be after starts_at")
+	}
+	return params, nil
+}
+
+// promotionParams maps a request onto the stored columns. Only the fields used
+// by the promotion's type are kept, and times are stored in UTC.
+func promotionParams(req AdminPromotionRequest) database.CreatePromotionParams {
+	params := database.CreatePromotionParams{
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Type:        req.Type,
+		Priority:    req.Priority,
+		MinSubtotal: req.MinSubtotal,
+		IsActive:    req.IsActive,
+	}
+	switch req.Type {
+	case database.PromotionTypeBuyXGetY:
+		params.PercentOff = req.PercentOff
+		params.BuyQuantity = req.BuyQuantity
+		params.GetQuantity = req.GetQuantity
+	case database.PromotionTypeSpendThreshold:
+		params.PercentOff = req.PercentOff
+		params.AmountOff = req.AmountOff
+	case database.PromotionTypeBundlePrice:
+		params.BundlePrice = req.BundlePrice
+	}
+	if req.StartsAt != nil {
+		params.StartsAt = sql.NullTime{Time: req.StartsAt.UTC(), Valid: true}
+	}
+	if req.EndsAt != nil {
+		params.EndsAt = sql.NullTime{Time: req.EndsAt.UTC(), Valid: true}
+	}
+	return params
+}
+
+// setPromotionTargets replaces the products and categories a promotion targets.
+func setPromotionTargets(ctx context.Context, qtx *database.Queries, promotionID uuid.UUID, req AdminPromotionRequest) error {
+	if err := qtx.DeletePromotionProducts(ctx, promotionID); err != nil {
+		return err
+	}
+	if err := qtx.DeletePromotionCategories(ctx, promotionID); err != nil {
+		return err
+	}
+
+	for _, p := range req.Products {
+		err := qtx.AddPromotionProduct(ctx, database.AddPromotionProductParams{
+			PromotionID: promotionID,
+			ProductID:   p.ProductID,
+			Role:        p.Role,
+			Quantity:    p.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range req.Categories {
+		err := qtx.AddPromotionCategory(ctx, database.AddPromotionCategoryParams{
+			PromotionID: promotionID,
+			CategoryID:  c.CategoryID,
+			Role:        c.Role,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleApiAdminGetPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := cfg.db.ListPromotions(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get promotions")
+		return
+	}
+
+	resp := make([]AdminPromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		p, err := promotionResponse(r.Context(), cfg.db, promotion)
+		if err != nil {
+			log.Printf("Promotion response error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to get promotions")
+			return
+		}
+		resp = append(resp, p)
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleApiAdminCreatePromotion(w http.ResponseWriter, r *http.Request) {
+	req, err := decodePromotionRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create promotion")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+	promotion, err := qtx.CreatePromotion(r.Context(), promotionParams(req))
+	if err != nil {
+		respondWithPromotionError(w, err, "Failed to create promotion")
+		return
+	}
+
+	if err := setPromotionTargets(r.Context(), qtx, promotion.ID, req); err != nil {
+		respondWithPromotionError(w, err, "Failed to create promotion")
+		return
+	}
+
+	resp, err := promotionResponse(r.Context(), qtx, promotion)
+	if err != nil {
+		log.Printf("Promotion response error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create promotion")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create promotion")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := uuid.Parse(r.PathValue("promotionId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	req, err := decodePromotionRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update promotion")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+	params := promotionParams(req)
+	promotion, err := qtx.UpdatePromotion(r.Context(), database.UpdatePromotionParams{
+		Name:        params.Name,
+		Description: params.Description,
+		Type:        params.Type,
+		Priority:    params.Priority,
+		PercentOff:  params.PercentOff,
+		AmountOff:   params.AmountOff,
+		MinSubtotal: params.MinSubtotal,
+		BuyQuantity: params.BuyQuantity,
+		GetQuantity: params.GetQuantity,
+		BundlePrice: params.BundlePrice,
+		StartsAt:    params.StartsAt,
+		EndsAt:      params.EndsAt,
+		IsActive:    params.IsActive,
+		ID:          promotionID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Promotion not found")
+			return
+		}
+		respondWithPromotionError(w, err, "Failed to update promotion")
+		return
+	}
+
+	if err := setPromotionTargets(r.Context(), qtx, promotion.ID, req); err != nil {
+		respondWithPromotionError(w, err, "Failed to update promotion")
+		return
+	}
+
+	resp, err := promotionResponse(r.Context(), qtx, promotion)
+	if err != nil {
+		log.Printf("Promotion response error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update promotion")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update promotion")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleApiAdminDeletePromotion stops a promotion for good. Orders keep the
+// discount lines it gave them.
+func (cfg *apiConfig) handleApiAdminDeletePromotion(w http.ResponseWriter, r *http.Request) {
+	promotionID, err := uuid.Parse(r.PathValue("promotionId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid promotion ID")
+		return
+	}
+
+	rows, err := cfg.db.DeletePromotion(r.Context(), promotionID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete promotion")
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Promotion not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func respondWithPromotionError(w http.ResponseWriter, err error, msg string) {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		respondWithError(w, http.StatusBadRequest, "Product or category does not exist")
+		return
+	}
+	respondWithError(w, http.StatusInternalServerError, msg)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+type RefundItemResponse struct {
+	VariantID   uuid.UUID    `json:"variant_id"`
+	Sku         string       `json:"sku"`
+	ProductName string       `json:"product_name"`
+	VariantName string       `json:"variant_name"`
+	Quantity    int32        `json:"quantity"`
+	Amount      money.Amount `json:"amount"`
+}
+
+type RefundResponse struct {
+	ID             uuid.UUID            `json:"id"`
+	OrderID        uuid.UUID            `json:"order_id"`
+	Amount         money.Amount         `json:"amount"`
+	ShippingAmount money.Amount         `json:"shipping_amount"`
+	Reason         string               `json:"reason"`
+	Restock        bool                 `json:"restock"`
+	PaymentStatus  string               `json:"payment_status"`
+	CreatedByEmail string               `json:"created_by_email"`
+	CreatedAt      time.Time            `json:"created_at"`
+	Items          []RefundItemResponse `json:"items"`
+}
+
+func (cfg *apiConfig) getOrderRefunds(ctx context.Context, orderID uuid.UUID) ([]RefundResponse, error) {
+	refunds, err := cfg.db.GetRefundsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := cfg.db.GetRefundVariantsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByRefund := make(map[uuid.UUID][]RefundItemResponse)
+	for _, v := range variants {
+		itemsByRefund[v.RefundID] = append(itemsByRefund[v.RefundID], RefundItemResponse{
+			VariantID:   v.ProductVariantID,
+			Sku:         v.Sku,
+			ProductName: v.ProductName,
+			VariantName: v.VariantName.String,
+			Quantity:    v.Quantity,
+			Amount:      v.Amount,
+		})
+	}
+
+	resp := make([]RefundResponse, 0, len(refunds))
+	for _, refund := range refunds {
+		items := itemsByRefund[refund.ID]
+		if items == nil {
+			items = []RefundItemResponse{}
+		}
+		// Refunds made before the ledger was queued were settled on the spot.
+		paymentStatus := database.PaymentTransactionStatusSucceeded
+		if refund.PaymentStatus.Valid {
+			paymentStatus = refund.PaymentStatus.PaymentTransactionStatus
+		}
+		resp = append(resp, RefundResponse{
+			ID:             refund.ID,
+			OrderID:        refund.OrderID,
+			Amount:         refund.Amount,
+			ShippingAmount: refund.ShippingAmount,
+			Reason:         refund.Reason.String,
+			Restock:        refund.Restock,
+			PaymentStatus:  string(paymentStatus),
+			CreatedByEmail: refund.CreatedByEmail.String,
+			CreatedAt:      refund.CreatedAt,
+			Items:          items,
+		})
+	}
+
+	return resp, nil
+}
+
+func (cfg *apiConfig) handleApiAdminGetOrderRefunds(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if _, err := cfg.db.GetOrderById(r.Context(), orderId); err != nil {
+		respondWithError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	refunds, err := cfg.getOrderRefunds(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get refunds")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, refunds)
+}
+
+func (cfg *apiConfig) handleApiAdminCreateRefund(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params := RefundRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !params.Full && len(params.Items) == 0 && !params.IncludeShipping {
+		respondWithError(w, http.StatusBadRequest, "Nothing to refund")
+		return
+	}
+
+	var refund database.Refund
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		updated, created, err := cfg.createRefund(r.Context(), qtx, order, params, adminActor(getUserIDFromContext(r.Context())))
+		refund = created
+		return updated, err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusBadRequest, vErr.Error())
+			return
+		}
+		log.Printf("Create refund error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create refund")
+		return
+	}
+
+	// A refund the provider has not answered for stays pending and is resent by
+	// the payment operation worker.
+	if _, err := cfg.runPaymentOperations(r.Context(), orderId); err != nil {
+		log.Printf("Refund %s payment error: %v", refund.ID, err)
+		if errors.Is(err, errPaymentProvider) {
+			respondWithError(w, http.StatusBadGateway, "Payment provider rejected the refund")
+			return
+		}
+	}
+
+	refunds, err := cfg.getOrderRefunds(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get refunds")
+		return
+	}
+
+	for _, resp := range refunds {
+		if resp.ID == refund.ID {
+			respondWithJSON(w, http.StatusCreated, resp)
+			return
+		}
+	}
+
+	respondWithError(w, http.StatusInternalServerError, "Failed to load refund")
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+type ReturnItemResponse struct {
+	VariantID   uuid.UUID `json:"variant_id"`
+	Sku         string    `json:"sku"`
+	ProductName string    `json:"product_name"`
+	VariantName string    `json:"variant_name"`
+	Quantity    int32     `json:"quantity"`
+}
+
+type ReturnResponse struct {
+	ID             uuid.UUID            `json:"id"`
+	OrderID        uuid.UUID            `json:"order_id"`
+	Status         string               `json:"status"`
+	Reason         string               `json:"reason"`
+	ResolutionNote string               `json:"resolution_note"`
+	Restocked      bool                 `json:"restocked"`
+	RefundID       *uuid.UUID           `json:"refund_id"`
+	CreatedByEmail string               `json:"created_by_email,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	Items          []ReturnItemResponse `json:"items"`
+}
+
+func (cfg *apiConfig) getOrderReturns(ctx context.Context, orderID uuid.UUID) ([]ReturnResponse, error) {
+	returns, err := cfg.db.GetReturnsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := cfg.db.GetReturnVariantsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByReturn := make(map[uuid.UUID][]ReturnItemResponse)
+	for _, v := range variants {
+		itemsByReturn[v.ReturnID] = append(itemsByReturn[v.ReturnID], ReturnItemResponse{
+			VariantID:   v.ProductVariantID,
+			Sku:         v.Sku,
+			ProductName: v.ProductName,
+			VariantName: v.VariantName.String,
+			Quantity:    v.Quantity,
+		})
+	}
+
+	resp := make([]ReturnResponse, 0, len(returns))
+	for _, ret := range returns {
+		items := itemsByReturn[ret.ID]
+		if items == nil {
+			items = []ReturnItemResponse{}
+		}
+		var refundID *uuid.UUID
+		if ret.RefundID.Valid {
+			refundID = &ret.RefundID.UUID
+		}
+		resp = append(resp, ReturnResponse{
+			ID:             ret.ID,
+			OrderID:        ret.OrderID,
+			Status:         string(ret.Status),
+			Reason:         ret.Reason,
+			ResolutionNote: ret.ResolutionNote.String,
+			Restocked:      ret.Restocked,
+			RefundID:       refundID,
+			CreatedByEmail: ret.CreatedByEmail.String,
+			CreatedAt:      ret.CreatedAt,
+			UpdatedAt:      ret.UpdatedAt,
+			Items:          items,
+		})
+	}
+
+	return resp, nil
+}
+
+// respondWithReturn looks up a single return from the order's list so the response
+// matches what the order detail shows.
+func (cfg *apiConfig) respondWithReturn(w http.ResponseWriter, r *http.Request, orderID, returnID uuid.UUID, status int) {
+	returns, err := cfg.getOrderReturns(r.Context(), orderID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get returns")
+		return
+	}
+
+	for _, resp := range returns {
+		if resp.ID == returnID {
+			respondWithJSON(w, status, resp)
+			return
+		}
+	}
+
+	respondWithError(w, http.StatusInternalServerError, "Failed to load return")
+}
+
+func respondWithReturnError(w http.ResponseWriter, err error, action string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Return not found")
+		return
+	}
+	if errors.Is(err, errInvalidStatusTransition) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	var vErr validationError
+	if errors.As(err, &vErr) {
+		respondWithError(w, http.StatusBadRequest, vErr.Error())
+		return
+	}
+	log.Printf("%s return error: %v", action, err)
+	respondWithError(w, http.StatusInternalServerError, "Failed to "+strings.ToLower(action)+" return")
+}
+
+func decodeReturnRequest(w http.ResponseWriter, r *http.Request) (ReturnRequest, bool) {
+	params := ReturnRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return params, false
+	}
+
+	params.Reason = strings.TrimSpace(params.Reason)
+	if params.Reason == "" {
+		respondWithError(w, http.StatusBadRequest, "Reason is required")
+		return params, false
+	}
+
+	return params, true
+}
+
+func (cfg *apiConfig) handleApiAdminGetOrderReturns(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if _, err := cfg.db.GetOrderById(r.Context(), orderId); err != nil {
+		respondWithError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	returns, err := cfg.getOrderReturns(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get returns")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, returns)
+}
+
+func (cfg *apiConfig) handleApiAdminCreateReturn(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params, ok := decodeReturnRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var ret database.Return
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		created, err := createReturn(r.Context(), qtx, order, params, adminActor(getUserIDFromContext(r.Context())))
+		ret = created
+		return order, err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithReturnError(w, err, "Create")
+		return
+	}
+
+	cfg.respondWithReturn(w, r, orderId, ret.ID, http.StatusCreated)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateReturn(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	returnId, err := uuid.Parse(r.PathValue("returnId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid return ID")
+		return
+	}
+
+	params := ReturnUpdateRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !isValidReturnStatus(params.Status) {
+		respondWithError(w, http.StatusBadRequest, "Invalid return status")
+		return
+	}
+	params.Note = strings.TrimSpace(params.Note)
+
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		updated, _, err := cfg.updateReturnStatus(r.Context(), qtx, order, returnId, params, adminActor(getUserIDFromContext(r.Context())))
+		return updated, err
+	})
+	if err != nil {
+		// A missing order and a return that belongs to another order look the same here.
+		respondWithReturnError(w, err, "Update")
+		return
+	}
+
+	if _, err := cfg.runPaymentOperations(r.Context(), orderId); err != nil {
+		log.Printf("Return %s refund payment error: %v", returnId, err)
+		if errors.Is(err, errPaymentProvider) {
+			respondWithError(w, http.StatusBadGateway, "Payment provider rejected the refund")
+			return
+		}
+	}
+
+	cfg.respondWithReturn(w, r, orderId, returnId, http.StatusOK)
+}
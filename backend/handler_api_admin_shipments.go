@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+type ShipmentItemResponse struct {
+	VariantID   uuid.UUID `json:"variant_id"`
+	Sku         string    `json:"sku"`
+	ProductName string    `json:"product_name"`
+	VariantName string    `json:"variant_name"`
+	Quantity    int32     `json:"quantity"`
+}
+
+type ShipmentResponse struct {
+	ID                  uuid.UUID              `json:"id"`
+	OrderID             uuid.UUID              `json:"order_id"`
+	Carrier             string                 `json:"carrier"`
+	TrackingNumber      string                 `json:"tracking_number"`
+	TrackingURLTemplate string                 `json:"tracking_url_template"`
+	TrackingURL         string                 `json:"tracking_url"`
+	ShippedAt           time.Time              `json:"shipped_at"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+	Items               []ShipmentItemResponse `json:"items"`
+}
+
+func (cfg *apiConfig) getOrderShipments(ctx context.Context, orderID uuid.UUID) ([]ShipmentResponse, error) {
+	shipments, err := cfg.db.GetShipmentsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := cfg.db.GetShipmentVariantsByOrderId(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByShipment := make(map[uuid.UUID][]ShipmentItemResponse)
+	for _, v := range variants {
+		itemsByShipment[v.ShipmentID] = append(itemsByShipment[v.ShipmentID], ShipmentItemResponse{
+			VariantID:   v.ProductVariantID,
+			Sku:         v.Sku,
+			ProductName: v.ProductName,
+			VariantName: v.VariantName.String,
+			Quantity:    v.Quantity,
+		})
+	}
+
+	resp := make([]ShipmentResponse, 0, len(shipments))
+	for _, shipment := range shipments {
+		items := itemsByShipment[shipment.ID]
+		if items == nil {
+			items = []ShipmentItemResponse{}
+		}
+		resp = append(resp, ShipmentResponse{
+			ID:                  shipment.ID,
+			OrderID:             shipment.OrderID,
+			Carrier:             shipment.Carrier,
+			TrackingNumber:      shipment.TrackingNumber.String,
+			TrackingURLTemplate: shipment.TrackingUrlTemplate.String,
+			TrackingURL:         trackingURL(shipment.TrackingUrlTemplate.String, shipment.TrackingNumber.String),
+			ShippedAt:           shipment.ShippedAt,
+			CreatedAt:           shipment.CreatedAt,
+			UpdatedAt:           shipment.UpdatedAt,
+			Items:               items,
+		})
+	}
+
+	return resp, nil
+}
+
+func (cfg *apiConfig) handleApiAdminGetOrderShipments(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if _, err := cfg.db.GetOrderById(r.Context(), orderId); err != nil {
+		respondWithError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	shipments, err := cfg.getOrderShipments(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get shipments")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, shipments)
+}
+
+func (cfg *apiConfig) handleApiAdminCreateShipment(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	params := ShipmentRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	params.Carrier = strings.TrimSpace(params.Carrier)
+	params.TrackingNumber = strings.TrimSpace(params.TrackingNumber)
+	if params.Carrier == "" {
+		respondWithError(w, http.StatusBadRequest, "Carrier is required")
+		return
+	}
+
+	var shipment database.Shipment
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		updated, created, err := createShipment(r.Context(), qtx, order, params, adminActor(getUserIDFromContext(r.Context())))
+		shipment = created
+		return updated, err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusBadRequest, vErr.Error())
+			return
+		}
+		log.Printf("Create shipment error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create shipment")
+		return
+	}
+
+	shipments, err := cfg.getOrderShipments(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get shipments")
+		return
+	}
+
+	for _, resp := range shipments {
+		if resp.ID == shipment.ID {
+			respondWithJSON(w, http.StatusCreated, resp)
+			return
+		}
+	}
+
+	respondWithError(w, http.StatusInternalServerError, "Failed to load shipment")
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateShipment(w http.ResponseWriter, r *http.Request) {
+	orderId, err := uuid.Parse(r.PathValue("orderId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	shipmentId, err := uuid.Parse(r.PathValue("shipmentId"))
+
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid shipment ID")
+		return
+	}
+
+	params := ShipmentRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	params.Carrier = strings.TrimSpace(params.Carrier)
+	params.TrackingNumber = strings.TrimSpace(params.TrackingNumber)
+	if params.Carrier == "" {
+		respondWithError(w, http.StatusBadRequest, "Carrier is required")
+		return
+	}
+
+	if len(params.Items) > 0 {
+		respondWithError(w, http.StatusBadRequest, "Shipment items cannot be changed")
+		return
+	}
+
+	shippedAt := sql.NullTime{}
+	if params.ShippedAt != nil {
+		shippedAt = sql.NullTime{Time: params.ShippedAt.UTC(), Valid: true}
+	}
+
+	_, err = cfg.updateOrderInTx(r.Context(), orderId, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		shipment, err := qtx.UpdateShipment(r.Context(), database.UpdateShipmentParams{
+			Carrier:             params.Carrier,
+			TrackingNumber:      sql.NullString{String: params.TrackingNumber, Valid: params.TrackingNumber != ""},
+			TrackingUrlTemplate: sql.NullString{String: params.TrackingURLTemplate, Valid: params.TrackingURLTemplate != ""},
+			ShippedAt:           shippedAt,
+			ID:                  shipmentId,
+			OrderID:             order.ID,
+		})
+		if err != nil {
+			return order, err
+		}
+
+		return order, recordOrderEvent(r.Context(), qtx, order.ID, database.OrderEventTypeShipmentUpdated, adminActor(getUserIDFromContext(r.Context())), map[string]any{
+			"shipment_id":     shipment.ID,
+			"carrier":         shipment.Carrier,
+			"tracking_number": shipment.TrackingNumber.String,
+			"shipped_at":      shipment.ShippedAt,
+		})
+	})
+	if err != nil {
+		// A missing order and a shipment that belongs to another order look the same here.
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Shipment not found")
+			return
+		}
+		log.Printf("Update shipment error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update shipment")
+		return
+	}
+
+	shipments, err := cfg.getOrderShipments(r.Context(), orderId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get shipments")
+		return
+	}
+
+	for _, resp := range shipments {
+		if resp.ID == shipmentId {
+			respondWithJSON(w, http.StatusOK, resp)
+			return
+		}
+	}
+
+	respondWithError(w, http.StatusInternalServerError, "Failed to load shipment")
+}
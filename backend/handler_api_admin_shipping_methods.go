@@ -4,20 +4,20 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
 type ShippingMethodRequest struct {
-	Name          string  `json:"name"`
-	Description   string  `json:"description"`
-	Price         float64 `json:"price"`
-	EstimatedDays string  `json:"estimated_days"`
-	SortOrder     int32   `json:"sort_order"`
-	IsActive      bool    `json:"is_active"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Price         money.Amount `json:"price"`
+	EstimatedDays string       `json:"estimated_days"`
+	SortOrder     int32        `json:"sort_order"`
+	IsActive      bool         `json:"is_active"`
 }
 
 func (cfg *apiConfig) handleApiAdminGetShippingMethods(w http.ResponseWriter, r *http.Request) {
@@ -55,7 +55,7 @@ func (cfg *apiConfig) handleApiAdminCreateShippingMethod(w http.ResponseWriter,
 	shippingMethod, err := cfg.db.CreateShippingOption(r.Context(), database.CreateShippingOptionParams{
 		Name:          params.Name,
 		Description:   sql.NullString{String: params.Description, Valid: params.Description != ""},
-		Price:         fmt.Sprintf("%2f", params.Price), //TODO [BZC-364]: refactor once the HTML frontend is removed to use float64 instead
+		Price:         params.Price,
 		EstimatedDays: params.EstimatedDays,
 		IsActive:      params.IsActive,
 	})
@@ -115,7 +115,7 @@ func (cfg *apiConfig) handleApiAdminUpdateShippingMethod(w http.ResponseWriter,
 		ID:            shippingMethodId,
 		Name:          params.Name,
 		Description:   sql.NullString{String: params.Description, Valid: params.Description != ""},
-		Price:         fmt.Sprintf("%2f", params.Price), //TODO [BZC-364]: refactor once the HTML frontend is removed to use float64 instead
+		Price:         params.Price,
 		EstimatedDays: params.EstimatedDays,
 		SortOrder:     params.SortOrder,
 		IsActive:      params.IsActive,
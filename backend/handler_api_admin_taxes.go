@@ -0,0 +1,266 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AdminTaxRateRequest struct {
+	CountryID  uuid.UUID      `json:"country_id"`
+	TaxClassID uuid.NullUUID  `json:"tax_class_id"`
+	Name       string         `json:"name"`
+	Rate       *money.Percent `json:"rate"`
+}
+
+func (cfg *apiConfig) handleApiAdminGetTaxClasses(w http.ResponseWriter, r *http.Request) {
+	classes, err := cfg.db.GetTaxClasses(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get tax classes")
+		return
+	}
+
+	if classes == nil {
+		classes = []database.TaxClass{}
+	}
+
+	respondWithJSON(w, http.StatusOK, classes)
+}
+
+func (cfg *apiConfig) handleApiAdminCreateTaxClass(w http.ResponseWriter, r *http.Request) {
+	params := struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name cannot be empty")
+		return
+	}
+
+	class, err := cfg.db.CreateTaxClass(r.Context(), params.Name)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "Tax class with the same name already exists")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to create tax class")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, class)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateTaxClass(w http.ResponseWriter, r *http.Request) {
+	taxClassID, err := uuid.Parse(r.PathValue("taxClassId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid tax class ID")
+		return
+	}
+
+	params := struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Name cannot be empty")
+		return
+	}
+
+	class, err := cfg.db.UpdateTaxClass(r.Context(), database.UpdateTaxClassParams{
+		Name: params.Name,
+		ID:   taxClassID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Tax class not found")
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "Tax class with the same name already exists")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update tax class")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, class)
+}
+
+// handleApiAdminDeleteTaxClass removes a class along with its rates. Products and
+// categories in the class fall back to the standard rate.
+func (cfg *apiConfig) handleApiAdminDeleteTaxClass(w http.ResponseWriter, r *http.Request) {
+	taxClassID, err := uuid.Parse(r.PathValue("taxClassId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid tax class ID")
+		return
+	}
+
+	rows, err := cfg.db.DeleteTaxClass(r.Context(), taxClassID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete tax class")
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Tax class not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func (cfg *apiConfig) handleApiAdminGetTaxRates(w http.ResponseWriter, r *http.Request) {
+	var countryID uuid.NullUUID
+	if s := r.URL.Query().Get("country_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid country ID")
+			return
+		}
+		countryID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	rates, err := cfg.db.ListTaxRates(r.Context(), countryID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get tax rates")
+		return
+	}
+
+	if rates == nil {
+		rates = []database.ListTaxRatesRow{}
+	}
+
+	respondWithJSON(w, http.StatusOK, rates)
+}
+
+func decodeTaxRateRequest(r *http.Request) (AdminTaxRateRequest, error) {
+	var params AdminTaxRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		if errors.Is(err, money.ErrInvalidPercent) {
+			return params, validationError("rate must be a percentage between 0 and 100")
+		}
+		return params, validationError("invalid JSON body")
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return params, validationError("name cannot be empty")
+	}
+	if params.Rate == nil {
+		return params, validationError("rate is required")
+	}
+	if *params.Rate > money.HundredPercent {
+		return params, validationError("rate must be a percentage between 0 and 100")
+	}
+	return params, nil
+}
+
+// handleApiAdminCreateTaxRate adds a rate for a country. Without a tax class it
+// is the country's standard rate, which also applies to shipping.
+func (cfg *apiConfig) handleApiAdminCreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeTaxRateRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if params.CountryID == uuid.Nil {
+		respondWithError(w, http.StatusBadRequest, "Country ID is required")
+		return
+	}
+
+	rate, err := cfg.db.CreateTaxRate(r.Context(), database.CreateTaxRateParams{
+		CountryID:  params.CountryID,
+		TaxClassID: params.TaxClassID,
+		Name:       params.Name,
+		Rate:       *params.Rate,
+	})
+	if err != nil {
+		respondWithTaxRateError(w, err, "Failed to create tax rate")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rate)
+}
+
+func (cfg *apiConfig) handleApiAdminUpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	taxRateID, err := uuid.Parse(r.PathValue("taxRateId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	params, err := decodeTaxRateRequest(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rate, err := cfg.db.UpdateTaxRate(r.Context(), database.UpdateTaxRateParams{
+		TaxClassID: params.TaxClassID,
+		Name:       params.Name,
+		Rate:       *params.Rate,
+		ID:         taxRateID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Tax rate not found")
+			return
+		}
+		respondWithTaxRateError(w, err, "Failed to update tax rate")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rate)
+}
+
+func (cfg *apiConfig) handleApiAdminDeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	taxRateID, err := uuid.Parse(r.PathValue("taxRateId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid tax rate ID")
+		return
+	}
+
+	rows, err := cfg.db.DeleteTaxRate(r.Context(), taxRateID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete tax rate")
+		return
+	}
+
+	if rows == 0 {
+		respondWithError(w, http.StatusNotFound, "Tax rate not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func respondWithTaxRateError(w http.ResponseWriter, err error, msg string) {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			respondWithError(w, http.StatusConflict, "The country already has a rate for this tax class")
+			return
+		case "23503":
+			respondWithError(w, http.StatusBadRequest, "Country or tax class does not exist")
+			return
+		}
+	}
+	respondWithError(w, http.StatusInternalServerError, msg)
+}
@@ -7,16 +7,17 @@ import (
 	"net/http"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
 type VariantRequest struct {
-	Sku           string  `json:"sku"`
-	Price         float64 `json:"price"`
-	StockQuantity int32   `json:"stock_quantity"`
-	ImageUrl      string  `json:"image_url"`
-	Name          string  `json:"name"`
+	Sku           string       `json:"sku"`
+	Price         money.Amount `json:"price"`
+	StockQuantity int32        `json:"stock_quantity"`
+	ImageUrl      string       `json:"image_url"`
+	Name          string       `json:"name"`
 }
 
 func (cfg *apiConfig) handleApiAdminGetVariants(w http.ResponseWriter, r *http.Request) {
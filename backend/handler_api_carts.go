@@ -1,20 +1,38 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
+// CartResponse carries tax only once a destination is known, as in a quote.
+// When PricesIncludeTax is set the tax is already part of the prices; otherwise
+// it is added to Total. Discounts lists each promotion and the coupon that
+// applied; their sum, Discount, is taken off Total and, without a shipping
+// method, does not include free shipping yet.
 type CartResponse struct {
-	CartID      uuid.UUID                                     `json:"cart_id"`
-	ItemCount   int                                           `json:"item_count"`
-	Items       []database.GetCartDetailsWithSnapshotPriceRow `json:"items"`
-	Subtotal    float64                                       `json:"subtotal"`
-	ShippingFee float64                                       `json:"shipping"`
-	Total       float64                                       `json:"total"`
+	CartID           uuid.UUID                                     `json:"cart_id"`
+	Currency         string                                        `json:"currency"`
+	ItemCount        int                                           `json:"item_count"`
+	Items            []database.GetCartDetailsWithSnapshotPriceRow `json:"items"`
+	Subtotal         money.Amount                                  `json:"subtotal"`
+	ShippingFee      money.Amount                                  `json:"shipping"`
+	Coupon           *CartCoupon                                   `json:"coupon"`
+	Discounts        []DiscountLine                                `json:"discounts"`
+	Discount         money.Amount                                  `json:"discount"`
+	Tax              money.Amount                                  `json:"tax"`
+	TaxLines         []TaxLine                                     `json:"tax_lines"`
+	PricesIncludeTax bool                                          `json:"prices_include_tax"`
+	ReverseCharge    bool                                          `json:"reverse_charge"`
+	Total            money.Amount                                  `json:"total"`
 }
 
 func (cfg *apiConfig) handleApiAddToCart(w http.ResponseWriter, r *http.Request) {
@@ -57,11 +75,17 @@ func (cfg *apiConfig) handleApiAddToCart(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	rate, err := cfg.getCartExchangeRate(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
 	_, err = cfg.db.UpsertVariantToCart(r.Context(), database.UpsertVariantToCartParams{
 		CartID:           cartID,
 		ProductVariantID: dbVariant.ID,
 		Quantity:         params.Quantity,
-		PricePerItem:     dbVariant.Price,
+		PricePerItem:     rate.fromBase(dbVariant.Price),
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Could not add to cart")
@@ -74,7 +98,14 @@ func (cfg *apiConfig) handleApiAddToCart(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	resp := calculateCartTotal(cartID, cartItems, 0)
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp := calculateCartTotal(cartID, rate.Currency, cartItems, 0, discount, cfg.untaxedQuote())
 
 	respondWithJSON(w, http.StatusOK, resp)
 }
@@ -97,9 +128,12 @@ func (cfg *apiConfig) handleApiGetCart(w http.ResponseWriter, r *http.Request) {
 	if cart.UserID.UUID != userID {
 		clearCartIDCookie(w)
 		response := CartResponse{
-			ItemCount: 0,
-			Items:     []database.GetCartDetailsWithSnapshotPriceRow{},
-			Subtotal:  0.0,
+			Currency:         cfg.storeCurrency,
+			ItemCount:        0,
+			Items:            []database.GetCartDetailsWithSnapshotPriceRow{},
+			Subtotal:         0.0,
+			TaxLines:         []TaxLine{},
+			PricesIncludeTax: cfg.pricesIncludeTax,
 		}
 		respondWithJSON(w, http.StatusOK, response)
 		return
@@ -112,7 +146,14 @@ func (cfg *apiConfig) handleApiGetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := calculateCartTotal(cartID, items, 0)
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp := calculateCartTotal(cartID, cfg.cartExchangeRate(cart).Currency, items, 0, discount, cfg.untaxedQuote())
 
 	respondWithJSON(w, http.StatusOK, resp)
 }
@@ -142,13 +183,26 @@ func (cfg *apiConfig) handleApiDeleteFromCart(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	rate, err := cfg.getCartExchangeRate(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
 	cartItems, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
 		return
 	}
 
-	resp := calculateCartTotal(cartID, cartItems, 0)
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp := calculateCartTotal(cartID, rate.Currency, cartItems, 0, discount, cfg.untaxedQuote())
 
 	respondWithJSON(w, http.StatusOK, resp)
 }
@@ -178,6 +232,12 @@ func (cfg *apiConfig) handleApiUpdateCartVariant(w http.ResponseWriter, r *http.
 
 	ctx := r.Context()
 
+	rate, err := cfg.getCartExchangeRate(ctx, cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
 	if params.Quantity <= 0 {
 		err = cfg.db.DeleteCartVariant(ctx, database.DeleteCartVariantParams{
 			CartID:           cartID,
@@ -198,7 +258,7 @@ func (cfg *apiConfig) handleApiUpdateCartVariant(w http.ResponseWriter, r *http.
 			CartID:           cartID,
 			ProductVariantID: variantID,
 			Quantity:         params.Quantity,
-			PricePerItem:     dbVariant.Price,
+			PricePerItem:     rate.fromBase(dbVariant.Price),
 		})
 		if err != nil {
 			respondWithError(w, http.StatusInternalServerError, "Could not update cart item")
@@ -212,6 +272,311 @@ func (cfg *apiConfig) handleApiUpdateCartVariant(w http.ResponseWriter, r *http.
 		return
 	}
 
-	resp := calculateCartTotal(cartID, cartItems, 0)
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp := calculateCartTotal(cartID, rate.Currency, cartItems, 0, discount, cfg.untaxedQuote())
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleApiSetCartCurrency switches the cart to a display currency, chosen
+// directly or through the shopper's country, and reprices it at today's rate.
+func (cfg *apiConfig) handleApiSetCartCurrency(w http.ResponseWriter, r *http.Request) {
+	params := struct {
+		Currency  string    `json:"currency"`
+		CountryID uuid.UUID `json:"country_id"`
+	}{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not decode parameters")
+		return
+	}
+
+	if params.CountryID != uuid.Nil {
+		country, err := cfg.db.GetCountryById(r.Context(), params.CountryID)
+		if err != nil || !country.IsActive {
+			respondWithError(w, http.StatusBadRequest, "Invalid country")
+			return
+		}
+		params.Currency = cfg.countryCurrency(country)
+	}
+
+	rate, err := cfg.currentExchangeRate(r.Context(), cfg.db, params.Currency)
+	if err != nil {
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusBadRequest, vErr.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Could not load exchange rate")
+		return
+	}
+
+	cartID, err := cfg.getOrCreateCartID(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not get or create cart")
+		return
+	}
+
+	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not update cart")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+	if err := cfg.setCartCurrency(r.Context(), qtx, cartID, rate); err != nil {
+		log.Printf("Set cart currency error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not update cart")
+		return
+	}
+
+	cartItems, err := qtx.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not update cart")
+		return
+	}
+
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp := calculateCartTotal(cartID, rate.Currency, cartItems, 0, discount, cfg.untaxedQuote())
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleApiGetCartQuote prices the cart for a destination: shipping for the
+// chosen method, if any, and tax for the country. A billing_country_id and
+// vat_id quote the reverse charge checkout would apply.
+func (cfg *apiConfig) handleApiGetCartQuote(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	countryID, err := uuid.Parse(q.Get("country_id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid country ID")
+		return
+	}
+	country, err := cfg.db.GetCountryById(r.Context(), countryID)
+	if err != nil || !country.IsActive {
+		respondWithError(w, http.StatusBadRequest, "Invalid country")
+		return
+	}
+
+	cartID, err := cfg.getOrCreateCartID(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not get or create cart")
+		return
+	}
+
+	rate, err := cfg.getCartExchangeRate(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
+	var shippingFee money.Amount
+	if s := q.Get("shipping_method_id"); s != "" {
+		shippingMethodID, err := uuid.Parse(s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid shipping method ID")
+			return
+		}
+		shippingMethod, err := cfg.db.SelectShippingOptionById(r.Context(), shippingMethodID)
+		if err != nil || !shippingMethod.IsActive {
+			respondWithError(w, http.StatusNotFound, "Shipping method not found")
+			return
+		}
+		shippingFee = rate.fromBase(shippingMethod.Price)
+	}
+
+	cartItems, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+
+	var buyer buyerVAT
+	if vatID := q.Get("vat_id"); vatID != "" {
+		billingCountry := country
+		if s := q.Get("billing_country_id"); s != "" {
+			billingCountryID, err := uuid.Parse(s)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid billing country ID")
+				return
+			}
+			billingCountry, err = cfg.db.GetCountryById(r.Context(), billingCountryID)
+			if err != nil || !billingCountry.IsActive {
+				respondWithError(w, http.StatusBadRequest, "Invalid billing country")
+				return
+			}
+		}
+
+		buyer, err = cfg.checkBuyerVAT(r.Context(), billingCountry, vatID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	lines, err := loadCartLines(r.Context(), cfg.db, cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, shippingFee)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	tax, err := cfg.quoteCartTax(r.Context(), cfg.db, country.ID, lines, shippingFee, discount)
+	if err != nil {
+		log.Printf("Cart tax quote error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not calculate tax")
+		return
+	}
+	tax = buyer.applyTo(tax)
+
+	resp := calculateCartTotal(cartID, rate.Currency, cartItems, shippingFee, discount, tax)
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleApiApplyCartCoupon attaches a coupon to the cart. The coupon has to
+// apply to the cart as it is now; later changes to the cart are reflected in
+// the coupon's status instead of removing it.
+func (cfg *apiConfig) handleApiApplyCartCoupon(w http.ResponseWriter, r *http.Request) {
+	params := struct {
+		Code string `json:"code"`
+	}{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Could not decode parameters")
+		return
+	}
+
+	params.Code = strings.TrimSpace(params.Code)
+	if params.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Coupon code is required")
+		return
+	}
+
+	cartID, err := cfg.getOrCreateCartID(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not get or create cart")
+		return
+	}
+
+	cart, err := cfg.db.GetCartById(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not load cart")
+		return
+	}
+
+	coupon, err := cfg.db.GetCouponByCode(r.Context(), params.Code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Coupon not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Could not load coupon")
+		return
+	}
+
+	lines, err := loadCartLines(r.Context(), cfg.db, cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+
+	email := ""
+	userID := getUserIDFromContext(r.Context())
+	if userID != uuid.Nil {
+		user, err := cfg.db.GetUserById(r.Context(), userID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to load user")
+			return
+		}
+		email = user.Email
+	}
+
+	// Coupons apply to what promotions leave, as at checkout.
+	promotions, err := cfg.cartPromotions(r.Context(), cfg.db, lines, cfg.cartExchangeRate(cart))
+	if err == nil {
+		_, err = cfg.validateCoupon(r.Context(), cfg.db, coupon, cart, promotions.applyTo(lines), 0, userID, email)
+	}
+	if err != nil {
+		var vErr validationError
+		if errors.As(err, &vErr) {
+			respondWithError(w, http.StatusBadRequest, vErr.Error())
+			return
+		}
+		log.Printf("Apply coupon error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	_, err = cfg.db.UpdateCartCoupon(r.Context(), database.UpdateCartCouponParams{
+		CouponID: uuid.NullUUID{UUID: coupon.ID, Valid: true},
+		ID:       cartID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	cfg.respondWithCart(w, r, cartID, cfg.cartExchangeRate(cart).Currency)
+}
+
+func (cfg *apiConfig) handleApiRemoveCartCoupon(w http.ResponseWriter, r *http.Request) {
+	cartID, err := cfg.getOrCreateCartID(w, r)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not get or create cart")
+		return
+	}
+
+	cart, err := cfg.db.UpdateCartCoupon(r.Context(), database.UpdateCartCouponParams{
+		CouponID: uuid.NullUUID{},
+		ID:       cartID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Could not remove coupon")
+		return
+	}
+
+	cfg.respondWithCart(w, r, cartID, cfg.cartExchangeRate(cart).Currency)
+}
+
+// respondWithCart writes the cart without shipping or tax, as the other cart
+// endpoints do.
+func (cfg *apiConfig) respondWithCart(w http.ResponseWriter, r *http.Request, cartID uuid.UUID, currency string) {
+	cartItems, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get cart items")
+		return
+	}
+
+	discount, err := cfg.getCartDiscount(r.Context(), cfg.db, cartID, 0)
+	if err != nil {
+		log.Printf("Cart discount error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Could not apply coupon")
+		return
+	}
+
+	resp := calculateCartTotal(cartID, currency, cartItems, 0, discount, cfg.untaxedQuote())
 	respondWithJSON(w, http.StatusOK, resp)
 }
@@ -1,13 +1,14 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -26,13 +27,17 @@ type CheckoutParams struct {
 	BillingCountryID   uuid.UUID `json:"billing_country_id"`
 	ShippingMethodID   uuid.UUID `json:"shipping_method_id"`
 	PaymentMethodID    uuid.UUID `json:"payment_method_id"`
+	CustomerNote       string    `json:"customer_note"`
+	VatID              string    `json:"vat_id"`
 }
 
 type OrderResponse struct {
 	ID                 uuid.UUID                `json:"id"`
+	OrderNumber        string                   `json:"order_number"`
 	UserID             *uuid.UUID               `json:"user_id"`
 	Status             string                   `json:"status"`
-	TotalPrice         float64                  `json:"total_price"`
+	TotalPrice         money.Amount             `json:"total_price"`
+	Currency           string                   `json:"currency"`
 	CreatedAt          time.Time                `json:"created_at"`
 	UpdatedAt          time.Time                `json:"updated_at"`
 	CustomerEmail      string                   `json:"customer_email"`
@@ -46,11 +51,25 @@ type OrderResponse struct {
 	BillingCity        string                   `json:"billing_city"`
 	BillingPostalCode  string                   `json:"billing_postal_code"`
 	ShippingMethodID   uuid.UUID                `json:"shipping_method_id"`
-	ShippingPrice      float64                  `json:"shipping_price"`
+	ShippingPrice      money.Amount             `json:"shipping_price"`
+	CouponCode         string                   `json:"coupon_code"`
+	DiscountTotal      money.Amount             `json:"discount_total"`
+	Discounts          []DiscountLine           `json:"discounts"`
+	TaxTotal           money.Amount             `json:"tax_total"`
+	PricesIncludeTax   bool                     `json:"prices_include_tax"`
+	TaxLines           []TaxLine                `json:"tax_lines"`
+	VatID              string                   `json:"vat_id"`
+	ReverseCharge      bool                     `json:"reverse_charge"`
 	PaymentMethodID    uuid.UUID                `json:"payment_method_id"`
 	ShippingCountryID  uuid.UUID                `json:"shipping_country_id"`
 	BillingCountryID   uuid.UUID                `json:"billing_country_id"`
+	CustomerNote       string                   `json:"customer_note"`
 	CartItems          []database.OrdersVariant `json:"cart_items"`
+	AccessToken        string                   `json:"access_token"`
+	AccessExpiresAt    time.Time                `json:"access_expires_at"`
+	OrderURL           string                   `json:"order_url"`
+	PaymentStatus      string                   `json:"payment_status"`
+	Payment            CheckoutPayment          `json:"payment"`
 }
 
 func (cfg *apiConfig) handleApiCheckout(w http.ResponseWriter, r *http.Request) {
@@ -72,155 +91,19 @@ func (cfg *apiConfig) handleApiCheckout(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, http.StatusInternalServerError, "Unable to load cart")
 		return
 	}
-	email := params.CustomerEmail
-	userId := getUserIDFromContext(r.Context())
 
-	if userId != uuid.Nil {
-		user, err := cfg.db.GetUserById(r.Context(), userId)
-
-		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Unable to load user")
-			return
-		}
-
-		email = user.Email
-	} else {
-
-		if !isValidEmail(email) {
-			respondWithError(w, http.StatusBadRequest, "Invalid email")
-			return
-		}
-	}
-
-	if params.ShippingName == "" || params.ShippingAddress == "" ||
-		params.ShippingCity == "" || params.ShippingPostalCode == "" ||
-		params.ShippingCountryID == uuid.Nil || params.ShippingPhone == "" ||
-		params.ShippingMethodID == uuid.Nil || params.PaymentMethodID == uuid.Nil ||
-		params.BillingName == "" || params.BillingAddress == "" ||
-		params.BillingCity == "" || params.BillingPostalCode == "" ||
-		params.BillingCountryID == uuid.Nil {
-		respondWithError(w, http.StatusBadRequest, "Missing required fields")
-		return
-	}
-
-	shippingCountry, err := cfg.db.GetCountryById(r.Context(), params.ShippingCountryID)
-	if err != nil || !shippingCountry.IsActive {
-		respondWithError(w, http.StatusBadRequest, "Invalid shipping country")
-		return
-	}
-	billingCountry, err := cfg.db.GetCountryById(r.Context(), params.BillingCountryID)
-	if err != nil || !billingCountry.IsActive {
-		respondWithError(w, http.StatusBadRequest, "Invalid billing country")
-		return
-	}
-
-	items, err := cfg.db.GetCartDetailsWithSnapshotPrice(r.Context(), cartId)
+	placed, err := cfg.placeOrder(r.Context(), cartId, getUserIDFromContext(r.Context()), params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to load cart details")
-		return
-	}
-	if len(items) == 0 {
-		respondWithError(w, http.StatusBadRequest, "Cart is empty")
-		return
-	}
-
-	subtotal := 0.0
-
-	for _, item := range items {
-		subtotal += float64(item.Quantity) * item.PricePerItem
-	}
-
-	shippingMethod, err := cfg.db.SelectShippingOptionById(r.Context(), params.ShippingMethodID)
-
-	if err != nil || !shippingMethod.IsActive {
-		respondWithError(w, http.StatusNotFound, "Shipping method not found")
-		return
-	}
-
-	paymentMethod, err := cfg.db.GetPaymentOptionById(r.Context(), params.PaymentMethodID)
-	if err != nil || !paymentMethod.IsActive {
-		respondWithError(w, http.StatusNotFound, "Payment method not found")
-		return
-	}
-
-	shippingPrice := shippingMethod.Price
-
-	totalPrice := subtotal + shippingPrice
-	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
-
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to create order")
-		return
-	}
-
-	defer func() {
-		if tx != nil {
-			_ = tx.Rollback()
-		}
-	}()
-
-	qtx := cfg.db.WithTx(tx)
-
-	for _, item := range items {
-		_, err := qtx.DecreaseVariantStock(r.Context(), database.DecreaseVariantStockParams{
-			Quantity:  item.Quantity,
-			VariantID: item.ProductVariantID,
-		})
-		if err != nil {
-			if err == sql.ErrNoRows {
-				respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Insufficient stock for SKU %s", item.Sku))
-				return
-			}
-			respondWithError(w, http.StatusInternalServerError, "Failed to reserve stock")
+		var cErr checkoutError
+		if errors.As(err, &cErr) {
+			respondWithError(w, cErr.status, cErr.message)
 			return
 		}
-	}
-
-	order, err := qtx.CreateOrder(r.Context(), database.CreateOrderParams{
-		UserID:             uuid.NullUUID{UUID: userId, Valid: userId != uuid.Nil},
-		TotalPrice:         totalPrice,
-		CustomerEmail:      email,
-		ShippingName:       params.ShippingName,
-		ShippingAddress:    params.ShippingAddress,
-		ShippingCity:       params.ShippingCity,
-		ShippingPostalCode: params.ShippingPostalCode,
-		ShippingCountryID:  params.ShippingCountryID,
-		ShippingPhone:      params.ShippingPhone,
-		BillingName:        params.BillingName,
-		BillingAddress:     params.BillingAddress,
-		BillingCity:        params.BillingCity,
-		BillingPostalCode:  params.BillingPostalCode,
-		BillingCountryID:   params.BillingCountryID,
-		ShippingOptionID:   params.ShippingMethodID,
-		PaymentOptionID:    params.PaymentMethodID,
-		ShippingPrice:      shippingPrice,
-	})
-	if err != nil {
+		log.Printf("Checkout error: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Cannot create order")
 		return
 	}
-
-	cartItems, err := qtx.CopyCartDataIntoOrder(r.Context(), database.CopyCartDataIntoOrderParams{OrderID: order.ID, CartID: cartId})
-
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Cannot copy cart items into order")
-		return
-	}
-
-	if _, err := qtx.UpdateCartStatus(r.Context(), database.UpdateCartStatusParams{
-		Status: "completed",
-		CartID: cartId,
-	}); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to update cart")
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Cannot finalize order")
-		return
-	}
-
-	tx = nil
+	order := placed.Order
 
 	var userIDPtr *uuid.UUID
 	if order.UserID.Valid {
@@ -232,8 +115,10 @@ func (cfg *apiConfig) handleApiCheckout(w http.ResponseWriter, r *http.Request)
 	resp := OrderResponse{
 		ID:                 order.ID,
 		UserID:             userIDPtr,
+		OrderNumber:        order.OrderNumber,
 		Status:             string(order.Status),
 		TotalPrice:         order.TotalPrice,
+		Currency:           placed.Currency,
 		CreatedAt:          order.CreatedAt,
 		UpdatedAt:          order.UpdatedAt,
 		CustomerEmail:      order.CustomerEmail,
@@ -248,10 +133,24 @@ func (cfg *apiConfig) handleApiCheckout(w http.ResponseWriter, r *http.Request)
 		BillingPostalCode:  order.BillingPostalCode,
 		ShippingMethodID:   order.ShippingOptionID,
 		ShippingPrice:      order.ShippingPrice,
+		CouponCode:         order.CouponCode.String,
+		DiscountTotal:      order.DiscountTotal,
+		Discounts:          placed.Discount.lines(),
+		TaxTotal:           order.TaxTotal,
+		PricesIncludeTax:   order.PricesIncludeTax,
+		TaxLines:           placed.Tax.Lines,
+		VatID:              order.VatID.String,
+		ReverseCharge:      order.ReverseCharge,
 		PaymentMethodID:    order.PaymentOptionID,
 		ShippingCountryID:  order.ShippingCountryID,
 		BillingCountryID:   order.BillingCountryID,
-		CartItems:          cartItems,
+		CustomerNote:       order.CustomerNote.String,
+		CartItems:          placed.CartItems,
+		AccessToken:        placed.AccessToken,
+		AccessExpiresAt:    placed.AccessExpiresAt,
+		OrderURL:           placed.OrderURL,
+		PaymentStatus:      string(order.PaymentStatus),
+		Payment:            placed.Payment,
 	}
 
 	respondWithJSON(w, http.StatusOK, resp)
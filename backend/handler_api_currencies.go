@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+type CurrencyResponse struct {
+	Currency string     `json:"currency"`
+	Rate     money.Rate `json:"rate"`
+}
+
+type CurrenciesResponse struct {
+	BaseCurrency string             `json:"base_currency"`
+	Currencies   []CurrencyResponse `json:"currencies"`
+}
+
+// handleApiGetCurrencies lists the currencies the storefront can display prices
+// in, starting with the store currency.
+func (cfg *apiConfig) handleApiGetCurrencies(w http.ResponseWriter, r *http.Request) {
+	rates, err := cfg.db.GetExchangeRates(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting currencies")
+		return
+	}
+
+	resp := CurrenciesResponse{
+		BaseCurrency: cfg.storeCurrency,
+		Currencies:   []CurrencyResponse{{Currency: cfg.storeCurrency, Rate: money.IdentityRate}},
+	}
+	for _, rate := range rates {
+		if rate.Currency == cfg.storeCurrency {
+			continue
+		}
+		resp.Currencies = append(resp.Currencies, CurrencyResponse{Currency: rate.Currency, Rate: rate.Rate})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handleApiAdminOrderInvoice(w http.ResponseWriter, r *http.Request) {
+	cfg.serveOrderDocument(w, r, r.PathValue("orderId"), uuid.Nil, "invoice", renderInvoice)
+}
+
+func (cfg *apiConfig) handleApiAdminOrderPackingSlip(w http.ResponseWriter, r *http.Request) {
+	cfg.serveOrderDocument(w, r, r.PathValue("orderId"), uuid.Nil, "packing-slip", renderPackingSlip)
+}
+
+func (cfg *apiConfig) handleApiAccountOrderInvoice(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == uuid.Nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	cfg.serveOrderDocument(w, r, r.PathValue("id"), userID, "invoice", renderInvoice)
+}
+
+// serveOrderDocument renders a PDF for the order. When ownerID is set the order
+// must belong to that user.
+func (cfg *apiConfig) serveOrderDocument(w http.ResponseWriter, r *http.Request, rawOrderID string, ownerID uuid.UUID, name string, render func(orderDocumentData) []byte) {
+	orderID, err := uuid.Parse(rawOrderID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	if ownerID != uuid.Nil {
+		_, err := cfg.db.GetUserOrderById(r.Context(), database.GetUserOrderByIdParams{
+			ID:     orderID,
+			UserID: uuid.NullUUID{UUID: ownerID, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Order not found")
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+			return
+		}
+	}
+
+	data, err := cfg.loadOrderDocumentData(r.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		log.Printf("Load order document error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	respondWithPDF(w, name+"-"+data.Order.OrderNumber+".pdf", render(data))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/auth"
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// GuestOrderResponse is what a customer sees without logging in, so it leaves out
+// addresses and contact details.
+type GuestOrderResponse struct {
+	ID                 uuid.UUID                                        `json:"id"`
+	OrderNumber        string                                           `json:"order_number"`
+	Status             string                                           `json:"status"`
+	PaymentStatus      string                                           `json:"payment_status"`
+	TotalPrice         money.Amount                                     `json:"total_price"`
+	Currency           string                                           `json:"currency"`
+	ShippingPrice      money.Amount                                     `json:"shipping_price"`
+	CouponCode         string                                           `json:"coupon_code"`
+	DiscountTotal      money.Amount                                     `json:"discount_total"`
+	Discounts          []DiscountLine                                   `json:"discounts"`
+	TaxTotal           money.Amount                                     `json:"tax_total"`
+	PricesIncludeTax   bool                                             `json:"prices_include_tax"`
+	TaxLines           []TaxLine                                        `json:"tax_lines"`
+	VatID              string                                           `json:"vat_id"`
+	ReverseCharge      bool                                             `json:"reverse_charge"`
+	ShippingMethodName string                                           `json:"shipping_method_name"`
+	PaymentMethodName  string                                           `json:"payment_method_name"`
+	CreatedAt          time.Time                                        `json:"created_at"`
+	UpdatedAt          time.Time                                        `json:"updated_at"`
+	OrderItems         []database.GetOrderItemsByOrderIdWithVariantsRow `json:"order_items"`
+	Shipments          []ShipmentResponse                               `json:"shipments"`
+}
+
+func (cfg *apiConfig) orderAccessToken(orderID uuid.UUID) (string, time.Time) {
+	expiresAt := time.Now().UTC().Add(cfg.orderAccessTTL)
+	return auth.SignOrderAccess(orderID.String(), expiresAt, cfg.orderAccessSecret), expiresAt
+}
+
+func (cfg *apiConfig) orderAccessURL(orderID uuid.UUID, token string) string {
+	return strings.TrimRight(cfg.frontendUrl, "/") + "/orders/" + orderID.String() + "?token=" + url.QueryEscape(token)
+}
+
+func (cfg *apiConfig) buildGuestOrderResponse(ctx context.Context, order database.GetOrderStatusByIdRow) (GuestOrderResponse, error) {
+	orderItems, err := cfg.db.GetOrderItemsByOrderIdWithVariants(ctx, order.ID)
+	if err != nil {
+		return GuestOrderResponse{}, err
+	}
+
+	if orderItems == nil {
+		orderItems = []database.GetOrderItemsByOrderIdWithVariantsRow{}
+	}
+
+	shipments, err := cfg.getOrderShipments(ctx, order.ID)
+	if err != nil {
+		return GuestOrderResponse{}, err
+	}
+
+	taxLines, err := getOrderTaxLines(ctx, cfg.db, order.ID)
+	if err != nil {
+		return GuestOrderResponse{}, err
+	}
+
+	discounts, err := getOrderDiscountLines(ctx, cfg.db, order.ID)
+	if err != nil {
+		return GuestOrderResponse{}, err
+	}
+
+	return GuestOrderResponse{
+		ID:                 order.ID,
+		OrderNumber:        order.OrderNumber,
+		Status:             string(order.Status),
+		PaymentStatus:      string(order.PaymentStatus),
+		TotalPrice:         order.TotalPrice,
+		Currency:           cfg.orderCurrency(order.Currency),
+		ShippingPrice:      order.ShippingPrice,
+		CouponCode:         order.CouponCode.String,
+		DiscountTotal:      order.DiscountTotal,
+		Discounts:          discounts,
+		TaxTotal:           order.TaxTotal,
+		PricesIncludeTax:   order.PricesIncludeTax,
+		TaxLines:           taxLines,
+		VatID:              order.VatID.String,
+		ReverseCharge:      order.ReverseCharge,
+		ShippingMethodName: order.ShippingMethodName.String,
+		PaymentMethodName:  order.PaymentMethodName.String,
+		CreatedAt:          order.CreatedAt,
+		UpdatedAt:          order.UpdatedAt,
+		OrderItems:         orderItems,
+		Shipments:          shipments,
+	}, nil
+}
+
+// handleApiLookupOrder finds a guest's order by email and order number. Order
+// numbers are sequential, so attempts are limited per client and per email.
+func (cfg *apiConfig) handleApiLookupOrder(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	if !cfg.lookupIPLimiter.allow(clientIP(r), now) {
+		respondWithTooManyLookups(w)
+		return
+	}
+
+	params := struct {
+		Email       string `json:"email"`
+		OrderNumber string `json:"order_number"`
+	}{}
+
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	params.Email = strings.TrimSpace(params.Email)
+	params.OrderNumber = strings.TrimSpace(params.OrderNumber)
+	if params.Email == "" || params.OrderNumber == "" {
+		respondWithError(w, http.StatusBadRequest, "Email and order number are required")
+		return
+	}
+
+	if !cfg.lookupEmailLimiter.allow(strings.ToLower(params.Email), now) {
+		respondWithTooManyLookups(w)
+		return
+	}
+
+	order, err := cfg.db.GetOrderStatusByNumberAndEmail(r.Context(), database.GetOrderStatusByNumberAndEmailParams{
+		OrderNumber:   params.OrderNumber,
+		CustomerEmail: params.Email,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	resp, err := cfg.buildGuestOrderResponse(r.Context(), database.GetOrderStatusByIdRow(order))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func respondWithTooManyLookups(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(orderLookupWindow.Seconds())))
+	respondWithError(w, http.StatusTooManyRequests, "Too many order lookups, please try again later")
+}
+
+func (cfg *apiConfig) handleApiGetOrderWithToken(w http.ResponseWriter, r *http.Request) {
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid order ID")
+		return
+	}
+
+	grantedID, err := auth.VerifyOrderAccess(r.URL.Query().Get("token"), cfg.orderAccessSecret)
+	if err != nil {
+		if errors.Is(err, auth.ErrExpiredOrderAccessToken) {
+			respondWithError(w, http.StatusUnauthorized, "Order link has expired")
+			return
+		}
+		respondWithError(w, http.StatusUnauthorized, "Invalid order link")
+		return
+	}
+
+	if grantedID != orderID.String() {
+		respondWithError(w, http.StatusUnauthorized, "Invalid order link")
+		return
+	}
+
+	order, err := cfg.db.GetOrderStatusById(r.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Order not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	resp, err := cfg.buildGuestOrderResponse(r.Context(), order)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to load order")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
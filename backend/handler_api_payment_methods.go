@@ -14,7 +14,7 @@ func (cfg *apiConfig) handleApiGetPaymentMethods(w http.ResponseWriter, r *http.
 		return
 	}
 	if paymentMethods == nil {
-		paymentMethods = []database.PaymentOption{}
+		paymentMethods = []database.GetActivePaymentOptionsRow{}
 	}
 
 	respondWithJSON(w, http.StatusOK, paymentMethods)
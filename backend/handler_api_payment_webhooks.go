@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
+	"github.com/google/uuid"
+)
+
+const maxWebhookBodyBytes = 1 << 20
+
+// verifyPaymentWebhook tries every payment option bound to the provider, since
+// each may carry its own signing secret.
+func (cfg *apiConfig) verifyPaymentWebhook(ctx context.Context, providerKey string, header http.Header, body []byte) (payments.WebhookEvent, error) {
+	options, err := cfg.db.GetPaymentOptionsByProvider(ctx, providerKey)
+	if err != nil {
+		return payments.WebhookEvent{}, fmt.Errorf("failed to load payment options: %w", err)
+	}
+
+	supported := false
+	for _, option := range options {
+		provider, err := cfg.paymentProvider(option)
+		if err != nil {
+			continue
+		}
+		parser, ok := provider.(payments.WebhookParser)
+		if !ok {
+			continue
+		}
+		supported = true
+
+		event, err := parser.ParseWebhook(header, body)
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			continue
+		}
+		return event, err
+	}
+
+	if !supported {
+		return payments.WebhookEvent{}, payments.ErrUnknownProvider
+	}
+	return payments.WebhookEvent{}, payments.ErrInvalidSignature
+}
+
+// applyPaymentWebhook records a verified event on the payment ledger of the order it
+// refers to. Events that do not map to a legal payment transition are ignored
+// rather than rejected, so the provider stops retrying them. A capture or
+// authorization for an order that was cancelled meanwhile is recorded and a
+// refund or void queued for it.
+func (cfg *apiConfig) applyPaymentWebhook(ctx context.Context, qtx *database.Queries, order database.Order, event payments.WebhookEvent, body []byte) (database.Order, database.PaymentWebhookOutcome, error) {
+	outstanding := order.PaymentStatus == database.PaymentStatusPending || order.PaymentStatus == database.PaymentStatusFailed
+	reference := order.PaymentReference
+
+	var applies bool
+	switch event.Status {
+	case payments.StatusCaptured:
+		applies = canTransitionPaymentStatus(order.PaymentStatus, database.PaymentStatusPaid)
+	case payments.StatusFailed:
+		applies = canTransitionPaymentStatus(order.PaymentStatus, database.PaymentStatusFailed)
+	case payments.StatusAuthorized, payments.StatusVoided:
+		// Authorizations and voids only matter while the payment is still outstanding.
+		applies = outstanding
+	case payments.StatusRefunded:
+		applies = order.PaymentStatus == database.PaymentStatusPaid || order.PaymentStatus == database.PaymentStatusPartiallyRefunded
+		if event.TransactionReference != "" {
+			reference = sql.NullString{String: event.TransactionReference, Valid: true}
+		}
+	}
+	if !applies {
+		return order, database.PaymentWebhookOutcomeIgnored, nil
+	}
+
+	amount := event.Amount
+	if amount == 0 {
+		amount = order.TotalPrice
+	}
+	txnType, txnStatus := ledgerEntryFor(event.Status)
+
+	// Refunds and voids the shop made itself are reported back by the provider;
+	// they are already on the ledger under the same reference, or still pending
+	// if the event arrives before the provider's answer to the shop's request.
+	if txnType == database.PaymentTransactionTypeRefund || txnType == database.PaymentTransactionTypeVoid {
+		txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+		if err != nil {
+			return order, "", fmt.Errorf("failed to load payment transactions: %w", err)
+		}
+		if hasLedgerEntry(txns, txnType, reference) {
+			return order, database.PaymentWebhookOutcomeIgnored, nil
+		}
+		if pending, ok := pendingOperation(txns, txnType, amount); ok {
+			order, err := settlePaymentOperation(ctx, qtx, order, database.SettlePaymentTransactionParams{
+				ID:                pending.ID,
+				Status:            database.PaymentTransactionStatusSucceeded,
+				ProviderReference: reference,
+				RawResponse:       json.RawMessage(body),
+			})
+			if err != nil {
+				return order, "", err
+			}
+			return order, database.PaymentWebhookOutcomeProcessed, nil
+		}
+	}
+
+	order, err := recordPaymentTransaction(ctx, qtx, order, database.CreatePaymentTransactionParams{
+		Provider:          order.PaymentProvider.String,
+		Type:              txnType,
+		Status:            txnStatus,
+		Amount:            amount,
+		Currency:          cfg.orderExchangeRate(order).Currency,
+		ProviderReference: reference,
+		RawResponse:       json.RawMessage(body),
+	}, systemActor)
+	if err != nil {
+		return order, "", err
+	}
+
+	if order.Status == database.OrderStatusCancelled {
+		switch event.Status {
+		case payments.StatusCaptured:
+			order, err = cfg.refundCancelledOrderCapture(ctx, qtx, order, amount, "webhook-"+event.ID)
+		case payments.StatusAuthorized:
+			order, err = cfg.voidCancelledOrderAuthorization(ctx, qtx, order, amount, "webhook-"+event.ID)
+		}
+		if err != nil {
+			return order, "", err
+		}
+	}
+
+	return order, database.PaymentWebhookOutcomeProcessed, nil
+}
+
+// refundCancelledOrderCapture queues a refund for a payment that completed after
+// its order was cancelled. The order's stock and coupon are already released, so
+// it cannot simply be reinstated. The refund is sent once the webhook is
+// committed, under a key derived from the event, so a redelivered event does not
+// refund twice.
+func (cfg *apiConfig) refundCancelledOrderCapture(ctx context.Context, qtx *database.Queries, order database.Order, amount money.Amount, idempotencyKey string) (database.Order, error) {
+	err := recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeRefundCreated, systemActor, map[string]any{
+		"amount": amount,
+		"reason": "Payment captured after the order was cancelled",
+	})
+	if err != nil {
+		return order, err
+	}
+
+	_, err = queuePaymentOperation(ctx, qtx, order, database.CreatePaymentTransactionParams{
+		Provider:          order.PaymentProvider.String,
+		Type:              database.PaymentTransactionTypeRefund,
+		Amount:            amount,
+		Currency:          cfg.orderExchangeRate(order).Currency,
+		ProviderReference: order.PaymentReference,
+		IdempotencyKey:    sql.NullString{String: idempotencyKey, Valid: true},
+	}, systemActor)
+	return order, err
+}
+
+// voidCancelledOrderAuthorization queues a void for a payment authorized after
+// its order was cancelled, such as a card payment that finished 3-D Secure once
+// the order had expired, so the hold on the customer's card is released.
+func (cfg *apiConfig) voidCancelledOrderAuthorization(ctx context.Context, qtx *database.Queries, order database.Order, amount money.Amount, idempotencyKey string) (database.Order, error) {
+	_, err := queuePaymentOperation(ctx, qtx, order, database.CreatePaymentTransactionParams{
+		Provider:          order.PaymentProvider.String,
+		Type:              database.PaymentTransactionTypeVoid,
+		Amount:            amount,
+		Currency:          cfg.orderExchangeRate(order).Currency,
+		ProviderReference: order.PaymentReference,
+		IdempotencyKey:    sql.NullString{String: idempotencyKey, Valid: true},
+	}, systemActor)
+	return order, err
+}
+
+func (cfg *apiConfig) handleApiPaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	providerKey := r.PathValue("provider")
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	event, err := cfg.verifyPaymentWebhook(r.Context(), providerKey, r.Header, body)
+	if err != nil {
+		if errors.Is(err, payments.ErrUnknownProvider) {
+			log.Printf("Payment webhook for unknown provider %q", providerKey)
+			respondWithError(w, http.StatusNotFound, "Unknown payment provider")
+			return
+		}
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			log.Printf("Payment webhook from %s with invalid signature", providerKey)
+			respondWithError(w, http.StatusBadRequest, "Invalid signature")
+			return
+		}
+		log.Printf("Payment webhook from %s could not be parsed: %v", providerKey, err)
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook")
+		return
+	}
+
+	tx, err := cfg.sqlDB.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to process webhook")
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+
+	stored, err := qtx.CreatePaymentWebhookEvent(r.Context(), database.CreatePaymentWebhookEventParams{
+		Provider:  providerKey,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Reference: sql.NullString{String: event.Reference, Valid: event.Reference != ""},
+		Payload:   json.RawMessage(body),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Payment webhook %s/%s already received, skipping", providerKey, event.ID)
+			respondWithJSON(w, http.StatusOK, map[string]any{"received": true, "duplicate": true})
+			return
+		}
+		log.Printf("Failed to store payment webhook %s/%s: %v", providerKey, event.ID, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to process webhook")
+		return
+	}
+
+	outcome := database.PaymentWebhookOutcomeUnmatched
+	orderID := uuid.NullUUID{}
+
+	order, err := qtx.GetOrderByPaymentReferenceForUpdate(r.Context(), database.GetOrderByPaymentReferenceForUpdateParams{
+		PaymentProvider:  sql.NullString{String: providerKey, Valid: true},
+		PaymentReference: sql.NullString{String: event.Reference, Valid: event.Reference != ""},
+	})
+	switch {
+	case err == nil:
+		orderID = uuid.NullUUID{UUID: order.ID, Valid: true}
+		_, outcome, err = cfg.applyPaymentWebhook(r.Context(), qtx, order, event, body)
+		if err != nil {
+			log.Printf("Failed to apply payment webhook %s/%s: %v", providerKey, event.ID, err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to process webhook")
+			return
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		log.Printf("Payment webhook %s/%s references unknown payment %q", providerKey, event.ID, event.Reference)
+	default:
+		log.Printf("Failed to load order for payment webhook %s/%s: %v", providerKey, event.ID, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to process webhook")
+		return
+	}
+
+	if outcome == database.PaymentWebhookOutcomeIgnored {
+		log.Printf("Payment webhook %s/%s (%s, %s) ignored for order %s in payment status %s", providerKey, event.ID, event.Type, event.Status, order.ID, order.PaymentStatus)
+	}
+
+	err = qtx.UpdatePaymentWebhookEventOutcome(r.Context(), database.UpdatePaymentWebhookEventOutcomeParams{
+		OrderID: orderID,
+		Outcome: outcome,
+		ID:      stored.ID,
+	})
+	if err != nil {
+		log.Printf("Failed to update payment webhook %s/%s: %v", providerKey, event.ID, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to process webhook")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to process webhook")
+		return
+	}
+
+	// A refund the provider does not confirm now is resent by the payment
+	// operation worker, so the event is acknowledged either way.
+	if orderID.Valid {
+		if _, err := cfg.runPaymentOperations(r.Context(), orderID.UUID); err != nil {
+			log.Printf("Payment webhook %s/%s follow-up failed: %v", providerKey, event.ID, err)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]any{"received": true})
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidOrderAccessToken = errors.New("invalid order access token")
+	ErrExpiredOrderAccessToken = errors.New("order access token has expired")
+)
+
+func signOrderAccess(orderID string, expiresAt int64, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("order-access:" + orderID + ":" + strconv.FormatInt(expiresAt, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignOrderAccess returns a token of the form <order id>.<expiry unix>.<signature>
+// that grants read access to a single order until expiresAt.
+func SignOrderAccess(orderID string, expiresAt time.Time, secret []byte) string {
+	exp := expiresAt.Unix()
+	return orderID + "." + strconv.FormatInt(exp, 10) + "." + signOrderAccess(orderID, exp, secret)
+}
+
+// VerifyOrderAccess checks the token's signature and expiry and returns the order ID it grants.
+func VerifyOrderAccess(token string, secret []byte) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidOrderAccessToken
+	}
+
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrInvalidOrderAccessToken
+	}
+
+	expected := signOrderAccess(parts[0], exp, secret)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return "", ErrInvalidOrderAccessToken
+	}
+
+	if time.Now().Unix() > exp {
+		return "", ErrExpiredOrderAccessToken
+	}
+
+	return parts[0], nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyOrderAccess(t *testing.T) {
+	secret := []byte("secret")
+	orderID := "8a6e0804-2bd0-4672-b79d-d97027f9071a"
+	future := time.Now().Add(time.Hour)
+	exp := strconv.FormatInt(future.Unix(), 10)
+	valid := SignOrderAccess(orderID, future, secret)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "round trip", token: valid},
+		{name: "expired", token: SignOrderAccess(orderID, time.Now().Add(-time.Minute), secret), wantErr: ErrExpiredOrderAccessToken},
+		{name: "other secret", token: SignOrderAccess(orderID, future, []byte("other")), wantErr: ErrInvalidOrderAccessToken},
+		{name: "tampered order", token: strings.Replace(valid, "8a6e0804", "8a6e0805", 1), wantErr: ErrInvalidOrderAccessToken},
+		{name: "tampered expiry", token: orderID + "." + strconv.FormatInt(future.Add(time.Hour).Unix(), 10) + valid[strings.LastIndex(valid, "."):], wantErr: ErrInvalidOrderAccessToken},
+		{name: "cart signature", token: orderID + "." + exp + "." + SignCartID(orderID+":"+exp, secret), wantErr: ErrInvalidOrderAccessToken},
+		{name: "malformed", token: orderID, wantErr: ErrInvalidOrderAccessToken},
+		{name: "empty", token: "", wantErr: ErrInvalidOrderAccessToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyOrderAccess(tt.token, secret)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyOrderAccess() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyOrderAccess(): %v", err)
+			}
+			if got != orderID {
+				t.Errorf("VerifyOrderAccess() = %q, want %q", got, orderID)
+			}
+		})
+	}
+}
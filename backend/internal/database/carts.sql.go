@@ -9,6 +9,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -19,10 +20,10 @@ RETURNING cart_id, product_variant_id, quantity, price_per_item, created_at, upd
 `
 
 type AddVariantToCartParams struct {
-	CartID           uuid.UUID `json:"cart_id"`
-	ProductVariantID uuid.UUID `json:"product_variant_id"`
-	Quantity         int32     `json:"quantity"`
-	PricePerItem     float64   `json:"price_per_item"`
+	CartID           uuid.UUID    `json:"cart_id"`
+	ProductVariantID uuid.UUID    `json:"product_variant_id"`
+	Quantity         int32        `json:"quantity"`
+	PricePerItem     money.Amount `json:"price_per_item"`
 }
 
 func (q *Queries) AddVariantToCart(ctx context.Context, arg AddVariantToCartParams) (CartsVariant, error) {
@@ -55,11 +56,17 @@ func (q *Queries) ClearCartItems(ctx context.Context, cartID uuid.UUID) error {
 }
 
 const createCart = `-- name: CreateCart :one
-INSERT INTO carts (user_id)
-VALUES ($1)
+INSERT INTO carts (user_id, currency, exchange_rate)
+VALUES ($1, $2, $3)
 RETURNING id, user_id, created_at, updated_at
 `
 
+type CreateCartParams struct {
+	UserID       uuid.NullUUID  `json:"user_id"`
+	Currency     sql.NullString `json:"currency"`
+	ExchangeRate money.Rate     `json:"exchange_rate"`
+}
+
 type CreateCartRow struct {
 	ID        uuid.UUID     `json:"id"`
 	UserID    uuid.NullUUID `json:"user_id"`
@@ -67,8 +74,8 @@ type CreateCartRow struct {
 	UpdatedAt sql.NullTime  `json:"updated_at"`
 }
 
-func (q *Queries) CreateCart(ctx context.Context, userID uuid.NullUUID) (CreateCartRow, error) {
-	row := q.db.QueryRowContext(ctx, createCart, userID)
+func (q *Queries) CreateCart(ctx context.Context, arg CreateCartParams) (CreateCartRow, error) {
+	row := q.db.QueryRowContext(ctx, createCart, arg.UserID, arg.Currency, arg.ExchangeRate)
 	var i CreateCartRow
 	err := row.Scan(
 		&i.ID,
@@ -106,7 +113,7 @@ func (q *Queries) DeleteCartVariant(ctx context.Context, arg DeleteCartVariantPa
 }
 
 const getCartById = `-- name: GetCartById :one
-SELECT id, user_id, status, created_at, updated_at FROM carts
+SELECT id, user_id, status, created_at, updated_at, currency, exchange_rate, coupon_id FROM carts
 WHERE id = $1
 `
 
@@ -119,6 +126,9 @@ func (q *Queries) GetCartById(ctx context.Context, id uuid.UUID) (Cart, error) {
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.CouponID,
 	)
 	return i, err
 }
@@ -148,9 +158,9 @@ type GetCartDetailsByCartIdRow struct {
 	CartID           uuid.UUID      `json:"cart_id"`
 	ProductVariantID uuid.UUID      `json:"product_variant_id"`
 	Quantity         int32          `json:"quantity"`
-	PricePerItem     float64        `json:"price_per_item"`
+	PricePerItem     money.Amount   `json:"price_per_item"`
 	Sku              string         `json:"sku"`
-	VariantPrice     float64        `json:"variant_price"`
+	VariantPrice     money.Amount   `json:"variant_price"`
 	StockQuantity    int32          `json:"stock_quantity"`
 	VariantImage     sql.NullString `json:"variant_image"`
 	VariantName      sql.NullString `json:"variant_name"`
@@ -221,7 +231,7 @@ type GetCartDetailsWithLivePriceRow struct {
 	CartID           uuid.UUID      `json:"cart_id"`
 	ProductVariantID uuid.UUID      `json:"product_variant_id"`
 	Quantity         int32          `json:"quantity"`
-	CurrentPrice     float64        `json:"current_price"`
+	CurrentPrice     money.Amount   `json:"current_price"`
 	Sku              string         `json:"sku"`
 	StockQuantity    int32          `json:"stock_quantity"`
 	VariantImage     sql.NullString `json:"variant_image"`
@@ -294,9 +304,9 @@ type GetCartDetailsWithSnapshotPriceRow struct {
 	CartID           uuid.UUID      `json:"cart_id"`
 	ProductVariantID uuid.UUID      `json:"product_variant_id"`
 	Quantity         int32          `json:"quantity"`
-	PricePerItem     float64        `json:"price_per_item"`
+	PricePerItem     money.Amount   `json:"price_per_item"`
 	Sku              string         `json:"sku"`
-	VariantPrice     float64        `json:"variant_price"`
+	VariantPrice     money.Amount   `json:"variant_price"`
 	StockQuantity    int32          `json:"stock_quantity"`
 	VariantImage     sql.NullString `json:"variant_image"`
 	VariantName      sql.NullString `json:"variant_name"`
@@ -392,7 +402,7 @@ func (q *Queries) GetCartProductsByCartId(ctx context.Context, cartID uuid.UUID)
 }
 
 const getCartsByUserId = `-- name: GetCartsByUserId :many
-SELECT id, user_id, status, created_at, updated_at FROM carts
+SELECT id, user_id, status, created_at, updated_at, currency, exchange_rate, coupon_id FROM carts
 WHERE user_id = $1
 ORDER BY created_at DESC
 `
@@ -412,6 +422,9 @@ func (q *Queries) GetCartsByUserId(ctx context.Context, userID uuid.NullUUID) ([
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.CouponID,
 		); err != nil {
 			return nil, err
 		}
@@ -450,11 +463,68 @@ func (q *Queries) MarkCartsAsAbandoned(ctx context.Context, threshold sql.NullTi
 	return err
 }
 
+const updateCartCoupon = `-- name: UpdateCartCoupon :one
+UPDATE carts
+SET coupon_id = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, user_id, status, created_at, updated_at, currency, exchange_rate, coupon_id
+`
+
+type UpdateCartCouponParams struct {
+	CouponID uuid.NullUUID `json:"coupon_id"`
+	ID       uuid.UUID     `json:"id"`
+}
+
+func (q *Queries) UpdateCartCoupon(ctx context.Context, arg UpdateCartCouponParams) (Cart, error) {
+	row := q.db.QueryRowContext(ctx, updateCartCoupon, arg.CouponID, arg.ID)
+	var i Cart
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.CouponID,
+	)
+	return i, err
+}
+
+const updateCartCurrency = `-- name: UpdateCartCurrency :one
+UPDATE carts
+SET currency = $1, exchange_rate = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING id, user_id, status, created_at, updated_at, currency, exchange_rate, coupon_id
+`
+
+type UpdateCartCurrencyParams struct {
+	Currency     sql.NullString `json:"currency"`
+	ExchangeRate money.Rate     `json:"exchange_rate"`
+	ID           uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) UpdateCartCurrency(ctx context.Context, arg UpdateCartCurrencyParams) (Cart, error) {
+	row := q.db.QueryRowContext(ctx, updateCartCurrency, arg.Currency, arg.ExchangeRate, arg.ID)
+	var i Cart
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.CouponID,
+	)
+	return i, err
+}
+
 const updateCartOwner = `-- name: UpdateCartOwner :one
 UPDATE carts
 SET user_id = $1
 WHERE id = $2
-RETURNING id, user_id, status, created_at, updated_at
+RETURNING id, user_id, status, created_at, updated_at, currency, exchange_rate, coupon_id
 `
 
 type UpdateCartOwnerParams struct {
@@ -471,6 +541,9 @@ func (q *Queries) UpdateCartOwner(ctx context.Context, arg UpdateCartOwnerParams
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.CouponID,
 	)
 	return i, err
 }
@@ -479,7 +552,7 @@ const updateCartStatus = `-- name: UpdateCartStatus :one
 UPDATE carts
 SET status = $1, updated_at = NOW()
 WHERE id = $2
-RETURNING id, user_id, status, created_at, updated_at
+RETURNING id, user_id, status, created_at, updated_at, currency, exchange_rate, coupon_id
 `
 
 type UpdateCartStatusParams struct {
@@ -496,6 +569,9 @@ func (q *Queries) UpdateCartStatus(ctx context.Context, arg UpdateCartStatusPara
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.CouponID,
 	)
 	return i, err
 }
@@ -512,10 +588,10 @@ RETURNING cart_id, product_variant_id, quantity, price_per_item, created_at, upd
 `
 
 type UpdateCartVariantParams struct {
-	CartID           uuid.UUID `json:"cart_id"`
-	ProductVariantID uuid.UUID `json:"product_variant_id"`
-	Quantity         int32     `json:"quantity"`
-	PricePerItem     float64   `json:"price_per_item"`
+	CartID           uuid.UUID    `json:"cart_id"`
+	ProductVariantID uuid.UUID    `json:"product_variant_id"`
+	Quantity         int32        `json:"quantity"`
+	PricePerItem     money.Amount `json:"price_per_item"`
 }
 
 func (q *Queries) UpdateCartVariant(ctx context.Context, arg UpdateCartVariantParams) (CartsVariant, error) {
@@ -582,10 +658,10 @@ RETURNING cart_id, product_variant_id, quantity, price_per_item, created_at, upd
 `
 
 type UpsertVariantToCartParams struct {
-	CartID           uuid.UUID `json:"cart_id"`
-	ProductVariantID uuid.UUID `json:"product_variant_id"`
-	Quantity         int32     `json:"quantity"`
-	PricePerItem     float64   `json:"price_per_item"`
+	CartID           uuid.UUID    `json:"cart_id"`
+	ProductVariantID uuid.UUID    `json:"product_variant_id"`
+	Quantity         int32        `json:"quantity"`
+	PricePerItem     money.Amount `json:"price_per_item"`
 }
 
 func (q *Queries) UpsertVariantToCart(ctx context.Context, arg UpsertVariantToCartParams) (CartsVariant, error) {
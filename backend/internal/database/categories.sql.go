@@ -14,14 +14,15 @@ import (
 )
 
 const createCategory = `-- name: CreateCategory :one
-INSERT INTO categories (name, slug, description, parent_id)
+INSERT INTO categories (name, slug, description, parent_id, tax_class_id)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
-RETURNING id, name, slug, description, parent_id, created_at, updated_at
+RETURNING id, name, slug, description, parent_id, created_at, updated_at, tax_class_id
 `
 
 type CreateCategoryParams struct {
@@ -29,6 +30,7 @@ type CreateCategoryParams struct {
 	Slug        string         `json:"slug"`
 	Description sql.NullString `json:"description"`
 	ParentID    uuid.NullUUID  `json:"parent_id"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 }
 
 func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
@@ -37,6 +39,7 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		arg.Slug,
 		arg.Description,
 		arg.ParentID,
+		arg.TaxClassID,
 	)
 	var i Category
 	err := row.Scan(
@@ -47,6 +50,7 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		&i.ParentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
@@ -64,7 +68,7 @@ func (q *Queries) DeleteCategoryById(ctx context.Context, id uuid.UUID) (int64,
 }
 
 const getCategories = `-- name: GetCategories :many
-SELECT id, name, slug, description, parent_id, created_at, updated_at FROM categories ORDER BY name ASC
+SELECT id, name, slug, description, parent_id, created_at, updated_at, tax_class_id FROM categories ORDER BY name ASC
 `
 
 func (q *Queries) GetCategories(ctx context.Context) ([]Category, error) {
@@ -84,6 +88,7 @@ func (q *Queries) GetCategories(ctx context.Context) ([]Category, error) {
 			&i.ParentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TaxClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -99,8 +104,7 @@ func (q *Queries) GetCategories(ctx context.Context) ([]Category, error) {
 }
 
 const getCategoryById = `-- name: GetCategoryById :one
-
-SELECT id, name, slug, description, parent_id, created_at, updated_at FROM categories WHERE id = $1
+SELECT id, name, slug, description, parent_id, created_at, updated_at, tax_class_id FROM categories WHERE id = $1
 `
 
 func (q *Queries) GetCategoryById(ctx context.Context, id uuid.UUID) (Category, error) {
@@ -114,12 +118,13 @@ func (q *Queries) GetCategoryById(ctx context.Context, id uuid.UUID) (Category,
 		&i.ParentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
 
 const getCategoryBySlug = `-- name: GetCategoryBySlug :one
-SELECT id, name, slug, description, parent_id, created_at, updated_at FROM categories WHERE slug = $1
+SELECT id, name, slug, description, parent_id, created_at, updated_at, tax_class_id FROM categories WHERE slug = $1
 `
 
 func (q *Queries) GetCategoryBySlug(ctx context.Context, slug string) (Category, error) {
@@ -133,6 +138,7 @@ func (q *Queries) GetCategoryBySlug(ctx context.Context, slug string) (Category,
 		&i.ParentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
@@ -186,7 +192,7 @@ func (q *Queries) GetCategoryPathByID(ctx context.Context, id uuid.NullUUID) ([]
 }
 
 const getChildCategories = `-- name: GetChildCategories :many
-SELECT id, name, slug, description, parent_id, created_at, updated_at FROM categories WHERE parent_id = $1
+SELECT id, name, slug, description, parent_id, created_at, updated_at, tax_class_id FROM categories WHERE parent_id = $1
 `
 
 func (q *Queries) GetChildCategories(ctx context.Context, parentID uuid.NullUUID) ([]Category, error) {
@@ -206,6 +212,7 @@ func (q *Queries) GetChildCategories(ctx context.Context, parentID uuid.NullUUID
 			&i.ParentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TaxClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -228,6 +235,7 @@ SELECT
   c.description,
   c.parent_id,
   p.name AS parent_name,
+  c.tax_class_id,
   c.created_at,
   c.updated_at
 FROM categories c
@@ -242,6 +250,7 @@ type ListCategoriesWithParentRow struct {
 	Description sql.NullString `json:"description"`
 	ParentID    uuid.NullUUID  `json:"parent_id"`
 	ParentName  sql.NullString `json:"parent_name"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 }
@@ -262,6 +271,7 @@ func (q *Queries) ListCategoriesWithParent(ctx context.Context) ([]ListCategorie
 			&i.Description,
 			&i.ParentID,
 			&i.ParentName,
+			&i.TaxClassID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -285,9 +295,10 @@ SET
     slug = $2,
     description = $3,
     parent_id = $4,
+    tax_class_id = $5,
     updated_at = NOW()
-    WHERE id = $5
-    RETURNING id, name, slug, description, parent_id, created_at, updated_at
+    WHERE id = $6
+    RETURNING id, name, slug, description, parent_id, created_at, updated_at, tax_class_id
 `
 
 type UpdateCategoryByIdParams struct {
@@ -295,6 +306,7 @@ type UpdateCategoryByIdParams struct {
 	Slug        string         `json:"slug"`
 	Description sql.NullString `json:"description"`
 	ParentID    uuid.NullUUID  `json:"parent_id"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 	ID          uuid.UUID      `json:"id"`
 }
 
@@ -304,6 +316,7 @@ func (q *Queries) UpdateCategoryById(ctx context.Context, arg UpdateCategoryById
 		arg.Slug,
 		arg.Description,
 		arg.ParentID,
+		arg.TaxClassID,
 		arg.ID,
 	)
 	var i Category
@@ -315,6 +328,7 @@ func (q *Queries) UpdateCategoryById(ctx context.Context, arg UpdateCategoryById
 		&i.ParentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
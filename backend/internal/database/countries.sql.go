@@ -12,23 +12,30 @@ import (
 )
 
 const createCountry = `-- name: CreateCountry :one
-INSERT INTO countries (name, iso_code, is_active)
+INSERT INTO countries (name, iso_code, is_active, currency)
 VALUES (
     $1,
     $2,
-    $3
+    $3,
+    NULLIF($4::text, '')
     )
-    RETURNING id, name, iso_code, is_active, sort_order, created_at, updated_at
+    RETURNING id, name, iso_code, is_active, sort_order, created_at, updated_at, currency
 `
 
 type CreateCountryParams struct {
 	Name     string `json:"name"`
 	IsoCode  string `json:"iso_code"`
 	IsActive bool   `json:"is_active"`
+	Currency string `json:"currency"`
 }
 
 func (q *Queries) CreateCountry(ctx context.Context, arg CreateCountryParams) (Country, error) {
-	row := q.db.QueryRowContext(ctx, createCountry, arg.Name, arg.IsoCode, arg.IsActive)
+	row := q.db.QueryRowContext(ctx, createCountry,
+		arg.Name,
+		arg.IsoCode,
+		arg.IsActive,
+		arg.Currency,
+	)
 	var i Country
 	err := row.Scan(
 		&i.ID,
@@ -38,6 +45,7 @@ func (q *Queries) CreateCountry(ctx context.Context, arg CreateCountryParams) (C
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -55,7 +63,7 @@ func (q *Queries) DeleteCountryById(ctx context.Context, id uuid.UUID) (int64, e
 }
 
 const getActiveCountries = `-- name: GetActiveCountries :many
-SELECT id, name, iso_code, is_active, sort_order, created_at, updated_at FROM countries WHERE is_active = true ORDER BY sort_order ASC
+SELECT id, name, iso_code, is_active, sort_order, created_at, updated_at, currency FROM countries WHERE is_active = true ORDER BY sort_order ASC
 `
 
 func (q *Queries) GetActiveCountries(ctx context.Context) ([]Country, error) {
@@ -75,6 +83,7 @@ func (q *Queries) GetActiveCountries(ctx context.Context) ([]Country, error) {
 			&i.SortOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -90,7 +99,7 @@ func (q *Queries) GetActiveCountries(ctx context.Context) ([]Country, error) {
 }
 
 const getCountries = `-- name: GetCountries :many
-SELECT id, name, iso_code, is_active, sort_order, created_at, updated_at FROM countries ORDER BY sort_order ASC
+SELECT id, name, iso_code, is_active, sort_order, created_at, updated_at, currency FROM countries ORDER BY sort_order ASC
 `
 
 func (q *Queries) GetCountries(ctx context.Context) ([]Country, error) {
@@ -110,6 +119,7 @@ func (q *Queries) GetCountries(ctx context.Context) ([]Country, error) {
 			&i.SortOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -125,7 +135,7 @@ func (q *Queries) GetCountries(ctx context.Context) ([]Country, error) {
 }
 
 const getCountryById = `-- name: GetCountryById :one
-SELECT id, name, iso_code, is_active, sort_order, created_at, updated_at FROM countries WHERE id = $1
+SELECT id, name, iso_code, is_active, sort_order, created_at, updated_at, currency FROM countries WHERE id = $1
 `
 
 func (q *Queries) GetCountryById(ctx context.Context, id uuid.UUID) (Country, error) {
@@ -139,6 +149,7 @@ func (q *Queries) GetCountryById(ctx context.Context, id uuid.UUID) (Country, er
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -172,10 +183,11 @@ UPDATE countries SET
     name = $1,
     iso_code = $2,
     is_active = $3,
-    sort_order = $4
+    sort_order = $4,
+    currency = NULLIF($5::text, '')
 
-WHERE id = $5
-RETURNING id, name, iso_code, is_active, sort_order, created_at, updated_at
+WHERE id = $6
+RETURNING id, name, iso_code, is_active, sort_order, created_at, updated_at, currency
 `
 
 type UpdateCountryByIdParams struct {
@@ -183,6 +195,7 @@ type UpdateCountryByIdParams struct {
 	IsoCode   string    `json:"iso_code"`
 	IsActive  bool      `json:"is_active"`
 	SortOrder int32     `json:"sort_order"`
+	Currency  string    `json:"currency"`
 	ID        uuid.UUID `json:"id"`
 }
 
@@ -192,6 +205,7 @@ func (q *Queries) UpdateCountryById(ctx context.Context, arg UpdateCountryByIdPa
 		arg.IsoCode,
 		arg.IsActive,
 		arg.SortOrder,
+		arg.Currency,
 		arg.ID,
 	)
 	var i Country
@@ -203,6 +217,7 @@ func (q *Queries) UpdateCountryById(ctx context.Context, arg UpdateCountryByIdPa
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
 	)
 	return i, err
 }
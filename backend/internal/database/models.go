@@ -7,9 +7,11 @@ package database
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -56,6 +58,144 @@ func (ns NullCartStatus) Value() (driver.Value, error) {
 	return string(ns.CartStatus), nil
 }
 
+type CouponType string
+
+const (
+	CouponTypePercentage   CouponType = "percentage"
+	CouponTypeFixedAmount  CouponType = "fixed_amount"
+	CouponTypeFreeShipping CouponType = "free_shipping"
+)
+
+func (e *CouponType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CouponType(s)
+	case string:
+		*e = CouponType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CouponType: %T", src)
+	}
+	return nil
+}
+
+type NullCouponType struct {
+	CouponType CouponType `json:"coupon_type"`
+	Valid      bool       `json:"valid"` // Valid is true if CouponType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCouponType) Scan(value interface{}) error {
+	if value == nil {
+		ns.CouponType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CouponType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCouponType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CouponType), nil
+}
+
+type OrderEventActor string
+
+const (
+	OrderEventActorUser   OrderEventActor = "user"
+	OrderEventActorAdmin  OrderEventActor = "admin"
+	OrderEventActorSystem OrderEventActor = "system"
+)
+
+func (e *OrderEventActor) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderEventActor(s)
+	case string:
+		*e = OrderEventActor(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderEventActor: %T", src)
+	}
+	return nil
+}
+
+type NullOrderEventActor struct {
+	OrderEventActor OrderEventActor `json:"order_event_actor"`
+	Valid           bool            `json:"valid"` // Valid is true if OrderEventActor is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderEventActor) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderEventActor, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderEventActor.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderEventActor) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderEventActor), nil
+}
+
+type OrderEventType string
+
+const (
+	OrderEventTypeCreated              OrderEventType = "created"
+	OrderEventTypeStatusChanged        OrderEventType = "status_changed"
+	OrderEventTypePaymentStatusChanged OrderEventType = "payment_status_changed"
+	OrderEventTypeShipmentCreated      OrderEventType = "shipment_created"
+	OrderEventTypeShipmentUpdated      OrderEventType = "shipment_updated"
+	OrderEventTypeRefundCreated        OrderEventType = "refund_created"
+	OrderEventTypeAddressUpdated       OrderEventType = "address_updated"
+	OrderEventTypeOrderEdited          OrderEventType = "order_edited"
+	OrderEventTypeNoteAdded            OrderEventType = "note_added"
+	OrderEventTypeReturnCreated        OrderEventType = "return_created"
+	OrderEventTypeReturnStatusChanged  OrderEventType = "return_status_changed"
+	OrderEventTypeExpired              OrderEventType = "expired"
+)
+
+func (e *OrderEventType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderEventType(s)
+	case string:
+		*e = OrderEventType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderEventType: %T", src)
+	}
+	return nil
+}
+
+type NullOrderEventType struct {
+	OrderEventType OrderEventType `json:"order_event_type"`
+	Valid          bool           `json:"valid"` // Valid is true if OrderEventType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderEventType) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderEventType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderEventType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderEventType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderEventType), nil
+}
+
 type OrderStatus string
 
 const (
@@ -105,10 +245,11 @@ func (ns NullOrderStatus) Value() (driver.Value, error) {
 type PaymentStatus string
 
 const (
-	PaymentStatusPending  PaymentStatus = "pending"
-	PaymentStatusPaid     PaymentStatus = "paid"
-	PaymentStatusFailed   PaymentStatus = "failed"
-	PaymentStatusRefunded PaymentStatus = "refunded"
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusPaid              PaymentStatus = "paid"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
 )
 
 func (e *PaymentStatus) Scan(src interface{}) error {
@@ -146,19 +287,283 @@ func (ns NullPaymentStatus) Value() (driver.Value, error) {
 	return string(ns.PaymentStatus), nil
 }
 
+type PaymentTransactionStatus string
+
+const (
+	PaymentTransactionStatusPending   PaymentTransactionStatus = "pending"
+	PaymentTransactionStatusSucceeded PaymentTransactionStatus = "succeeded"
+	PaymentTransactionStatusFailed    PaymentTransactionStatus = "failed"
+)
+
+func (e *PaymentTransactionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentTransactionStatus(s)
+	case string:
+		*e = PaymentTransactionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentTransactionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentTransactionStatus struct {
+	PaymentTransactionStatus PaymentTransactionStatus `json:"payment_transaction_status"`
+	Valid                    bool                     `json:"valid"` // Valid is true if PaymentTransactionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentTransactionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentTransactionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentTransactionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentTransactionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentTransactionStatus), nil
+}
+
+type PaymentTransactionType string
+
+const (
+	PaymentTransactionTypeAuthorization PaymentTransactionType = "authorization"
+	PaymentTransactionTypeCapture       PaymentTransactionType = "capture"
+	PaymentTransactionTypeVoid          PaymentTransactionType = "void"
+	PaymentTransactionTypeRefund        PaymentTransactionType = "refund"
+)
+
+func (e *PaymentTransactionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentTransactionType(s)
+	case string:
+		*e = PaymentTransactionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentTransactionType: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentTransactionType struct {
+	PaymentTransactionType PaymentTransactionType `json:"payment_transaction_type"`
+	Valid                  bool                   `json:"valid"` // Valid is true if PaymentTransactionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentTransactionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentTransactionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentTransactionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentTransactionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentTransactionType), nil
+}
+
+type PaymentWebhookOutcome string
+
+const (
+	PaymentWebhookOutcomeReceived  PaymentWebhookOutcome = "received"
+	PaymentWebhookOutcomeProcessed PaymentWebhookOutcome = "processed"
+	PaymentWebhookOutcomeIgnored   PaymentWebhookOutcome = "ignored"
+	PaymentWebhookOutcomeUnmatched PaymentWebhookOutcome = "unmatched"
+)
+
+func (e *PaymentWebhookOutcome) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentWebhookOutcome(s)
+	case string:
+		*e = PaymentWebhookOutcome(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentWebhookOutcome: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentWebhookOutcome struct {
+	PaymentWebhookOutcome PaymentWebhookOutcome `json:"payment_webhook_outcome"`
+	Valid                 bool                  `json:"valid"` // Valid is true if PaymentWebhookOutcome is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentWebhookOutcome) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentWebhookOutcome, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentWebhookOutcome.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentWebhookOutcome) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentWebhookOutcome), nil
+}
+
+type PromotionTargetRole string
+
+const (
+	PromotionTargetRoleBuy PromotionTargetRole = "buy"
+	PromotionTargetRoleGet PromotionTargetRole = "get"
+)
+
+func (e *PromotionTargetRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PromotionTargetRole(s)
+	case string:
+		*e = PromotionTargetRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PromotionTargetRole: %T", src)
+	}
+	return nil
+}
+
+type NullPromotionTargetRole struct {
+	PromotionTargetRole PromotionTargetRole `json:"promotion_target_role"`
+	Valid               bool                `json:"valid"` // Valid is true if PromotionTargetRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPromotionTargetRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.PromotionTargetRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PromotionTargetRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPromotionTargetRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PromotionTargetRole), nil
+}
+
+type PromotionType string
+
+const (
+	PromotionTypeBuyXGetY       PromotionType = "buy_x_get_y"
+	PromotionTypeSpendThreshold PromotionType = "spend_threshold"
+	PromotionTypeBundlePrice    PromotionType = "bundle_price"
+)
+
+func (e *PromotionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PromotionType(s)
+	case string:
+		*e = PromotionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PromotionType: %T", src)
+	}
+	return nil
+}
+
+type NullPromotionType struct {
+	PromotionType PromotionType `json:"promotion_type"`
+	Valid         bool          `json:"valid"` // Valid is true if PromotionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPromotionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.PromotionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PromotionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPromotionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PromotionType), nil
+}
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusReceived  ReturnStatus = "received"
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+)
+
+func (e *ReturnStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReturnStatus(s)
+	case string:
+		*e = ReturnStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReturnStatus: %T", src)
+	}
+	return nil
+}
+
+type NullReturnStatus struct {
+	ReturnStatus ReturnStatus `json:"return_status"`
+	Valid        bool         `json:"valid"` // Valid is true if ReturnStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReturnStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReturnStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReturnStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReturnStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReturnStatus), nil
+}
+
 type Cart struct {
-	ID        uuid.UUID     `json:"id"`
-	UserID    uuid.NullUUID `json:"user_id"`
-	Status    CartStatus    `json:"status"`
-	CreatedAt sql.NullTime  `json:"created_at"`
-	UpdatedAt sql.NullTime  `json:"updated_at"`
+	ID           uuid.UUID      `json:"id"`
+	UserID       uuid.NullUUID  `json:"user_id"`
+	Status       CartStatus     `json:"status"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	UpdatedAt    sql.NullTime   `json:"updated_at"`
+	Currency     sql.NullString `json:"currency"`
+	ExchangeRate money.Rate     `json:"exchange_rate"`
+	CouponID     uuid.NullUUID  `json:"coupon_id"`
 }
 
 type CartsVariant struct {
 	CartID           uuid.UUID    `json:"cart_id"`
 	ProductVariantID uuid.UUID    `json:"product_variant_id"`
 	Quantity         int32        `json:"quantity"`
-	PricePerItem     float64      `json:"price_per_item"`
+	PricePerItem     money.Amount `json:"price_per_item"`
 	CreatedAt        sql.NullTime `json:"created_at"`
 	UpdatedAt        sql.NullTime `json:"updated_at"`
 }
@@ -171,61 +576,194 @@ type Category struct {
 	ParentID    uuid.NullUUID  `json:"parent_id"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 }
 
 type Country struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	IsoCode   string    `json:"iso_code"`
-	IsActive  bool      `json:"is_active"`
-	SortOrder int32     `json:"sort_order"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID      `json:"id"`
+	Name      string         `json:"name"`
+	IsoCode   string         `json:"iso_code"`
+	IsActive  bool           `json:"is_active"`
+	SortOrder int32          `json:"sort_order"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Currency  sql.NullString `json:"currency"`
+}
+
+type Coupon struct {
+	ID                    uuid.UUID      `json:"id"`
+	Code                  string         `json:"code"`
+	Description           sql.NullString `json:"description"`
+	Type                  CouponType     `json:"type"`
+	PercentOff            money.Percent  `json:"percent_off"`
+	AmountOff             money.Amount   `json:"amount_off"`
+	MinSubtotal           money.Amount   `json:"min_subtotal"`
+	StartsAt              sql.NullTime   `json:"starts_at"`
+	EndsAt                sql.NullTime   `json:"ends_at"`
+	UsageLimit            sql.NullInt32  `json:"usage_limit"`
+	UsageLimitPerCustomer sql.NullInt32  `json:"usage_limit_per_customer"`
+	TimesUsed             int32          `json:"times_used"`
+	IsActive              bool           `json:"is_active"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+}
+
+type CouponCategory struct {
+	CouponID   uuid.UUID `json:"coupon_id"`
+	CategoryID uuid.UUID `json:"category_id"`
+}
+
+type CouponProduct struct {
+	CouponID  uuid.UUID `json:"coupon_id"`
+	ProductID uuid.UUID `json:"product_id"`
+}
+
+type CouponRedemption struct {
+	ID            uuid.UUID     `json:"id"`
+	CouponID      uuid.UUID     `json:"coupon_id"`
+	OrderID       uuid.UUID     `json:"order_id"`
+	UserID        uuid.NullUUID `json:"user_id"`
+	CustomerEmail string        `json:"customer_email"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type ExchangeRate struct {
+	Currency  string     `json:"currency"`
+	Rate      money.Rate `json:"rate"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
 
 type Order struct {
-	ID                 uuid.UUID     `json:"id"`
-	UserID             uuid.NullUUID `json:"user_id"`
-	Status             OrderStatus   `json:"status"`
-	TotalPrice         float64       `json:"total_price"`
-	CreatedAt          time.Time     `json:"created_at"`
-	UpdatedAt          time.Time     `json:"updated_at"`
-	CustomerEmail      string        `json:"customer_email"`
-	ShippingName       string        `json:"shipping_name"`
-	ShippingAddress    string        `json:"shipping_address"`
-	ShippingCity       string        `json:"shipping_city"`
-	ShippingPostalCode string        `json:"shipping_postal_code"`
-	ShippingPhone      string        `json:"shipping_phone"`
-	BillingName        string        `json:"billing_name"`
-	BillingAddress     string        `json:"billing_address"`
-	BillingCity        string        `json:"billing_city"`
-	BillingPostalCode  string        `json:"billing_postal_code"`
-	ShippingOptionID   uuid.UUID     `json:"shipping_option_id"`
-	ShippingPrice      float64       `json:"shipping_price"`
-	PaymentOptionID    uuid.UUID     `json:"payment_option_id"`
-	ShippingCountryID  uuid.UUID     `json:"shipping_country_id"`
-	BillingCountryID   uuid.UUID     `json:"billing_country_id"`
-	PaymentStatus      PaymentStatus `json:"payment_status"`
+	ID                 uuid.UUID      `json:"id"`
+	UserID             uuid.NullUUID  `json:"user_id"`
+	Status             OrderStatus    `json:"status"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	CustomerEmail      string         `json:"customer_email"`
+	ShippingName       string         `json:"shipping_name"`
+	ShippingAddress    string         `json:"shipping_address"`
+	ShippingCity       string         `json:"shipping_city"`
+	ShippingPostalCode string         `json:"shipping_postal_code"`
+	ShippingPhone      string         `json:"shipping_phone"`
+	BillingName        string         `json:"billing_name"`
+	BillingAddress     string         `json:"billing_address"`
+	BillingCity        string         `json:"billing_city"`
+	BillingPostalCode  string         `json:"billing_postal_code"`
+	ShippingOptionID   uuid.UUID      `json:"shipping_option_id"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	PaymentOptionID    uuid.UUID      `json:"payment_option_id"`
+	ShippingCountryID  uuid.UUID      `json:"shipping_country_id"`
+	BillingCountryID   uuid.UUID      `json:"billing_country_id"`
+	PaymentStatus      PaymentStatus  `json:"payment_status"`
+	OrderNumber        string         `json:"order_number"`
+	CustomerNote       sql.NullString `json:"customer_note"`
+	PaymentProvider    sql.NullString `json:"payment_provider"`
+	PaymentReference   sql.NullString `json:"payment_reference"`
+	Currency           sql.NullString `json:"currency"`
+	ExchangeRate       money.Rate     `json:"exchange_rate"`
+	TaxTotal           money.Amount   `json:"tax_total"`
+	PricesIncludeTax   bool           `json:"prices_include_tax"`
+	VatID              sql.NullString `json:"vat_id"`
+	ReverseCharge      bool           `json:"reverse_charge"`
+	CouponID           uuid.NullUUID  `json:"coupon_id"`
+	CouponCode         sql.NullString `json:"coupon_code"`
+	DiscountTotal      money.Amount   `json:"discount_total"`
+}
+
+type OrderDiscountLine struct {
+	ID          uuid.UUID     `json:"id"`
+	OrderID     uuid.UUID     `json:"order_id"`
+	PromotionID uuid.NullUUID `json:"promotion_id"`
+	Name        string        `json:"name"`
+	Explanation string        `json:"explanation"`
+	Amount      money.Amount  `json:"amount"`
+	Position    int32         `json:"position"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type OrderEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	OrderID   uuid.UUID       `json:"order_id"`
+	EventType OrderEventType  `json:"event_type"`
+	ActorType OrderEventActor `json:"actor_type"`
+	ActorID   uuid.NullUUID   `json:"actor_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type OrderNote struct {
+	ID                uuid.UUID     `json:"id"`
+	OrderID           uuid.UUID     `json:"order_id"`
+	Body              string        `json:"body"`
+	IsCustomerVisible bool          `json:"is_customer_visible"`
+	CreatedBy         uuid.NullUUID `json:"created_by"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+type OrderTaxLine struct {
+	ID            uuid.UUID     `json:"id"`
+	OrderID       uuid.UUID     `json:"order_id"`
+	Name          string        `json:"name"`
+	Rate          money.Percent `json:"rate"`
+	IsShipping    bool          `json:"is_shipping"`
+	TaxableAmount money.Amount  `json:"taxable_amount"`
+	TaxAmount     money.Amount  `json:"tax_amount"`
+	CreatedAt     time.Time     `json:"created_at"`
 }
 
 type OrdersVariant struct {
 	OrderID          uuid.UUID    `json:"order_id"`
 	ProductVariantID uuid.UUID    `json:"product_variant_id"`
 	Quantity         int32        `json:"quantity"`
-	PricePerItem     float64      `json:"price_per_item"`
-	TotalPrice       float64      `json:"total_price"`
+	PricePerItem     money.Amount `json:"price_per_item"`
+	TotalPrice       money.Amount `json:"total_price"`
 	CreatedAt        sql.NullTime `json:"created_at"`
 	UpdatedAt        sql.NullTime `json:"updated_at"`
 }
 
 type PaymentOption struct {
-	ID          uuid.UUID      `json:"id"`
-	Name        string         `json:"name"`
-	Description sql.NullString `json:"description"`
-	IsActive    bool           `json:"is_active"`
-	SortOrder   sql.NullInt32  `json:"sort_order"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	ID             uuid.UUID       `json:"id"`
+	Name           string          `json:"name"`
+	Description    sql.NullString  `json:"description"`
+	IsActive       bool            `json:"is_active"`
+	SortOrder      sql.NullInt32   `json:"sort_order"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Provider       string          `json:"provider"`
+	ProviderConfig json.RawMessage `json:"provider_config"`
+}
+
+type PaymentTransaction struct {
+	ID                uuid.UUID                `json:"id"`
+	OrderID           uuid.UUID                `json:"order_id"`
+	Provider          string                   `json:"provider"`
+	Type              PaymentTransactionType   `json:"type"`
+	Status            PaymentTransactionStatus `json:"status"`
+	Amount            money.Amount             `json:"amount"`
+	Currency          string                   `json:"currency"`
+	ProviderReference sql.NullString           `json:"provider_reference"`
+	RawResponse       json.RawMessage          `json:"raw_response"`
+	ErrorMessage      sql.NullString           `json:"error_message"`
+	CreatedBy         uuid.NullUUID            `json:"created_by"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	IdempotencyKey    sql.NullString           `json:"idempotency_key"`
+}
+
+type PaymentWebhookEvent struct {
+	ID          uuid.UUID             `json:"id"`
+	Provider    string                `json:"provider"`
+	EventID     string                `json:"event_id"`
+	EventType   string                `json:"event_type"`
+	Reference   sql.NullString        `json:"reference"`
+	OrderID     uuid.NullUUID         `json:"order_id"`
+	Outcome     PaymentWebhookOutcome `json:"outcome"`
+	Payload     json.RawMessage       `json:"payload"`
+	ReceivedAt  time.Time             `json:"received_at"`
+	ProcessedAt sql.NullTime          `json:"processed_at"`
 }
 
 type Product struct {
@@ -237,13 +775,14 @@ type Product struct {
 	Description sql.NullString `json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 }
 
 type ProductVariant struct {
 	ID            uuid.UUID      `json:"id"`
 	ProductID     uuid.UUID      `json:"product_id"`
 	Sku           string         `json:"sku"`
-	Price         float64        `json:"price"`
+	Price         money.Amount   `json:"price"`
 	StockQuantity int32          `json:"stock_quantity"`
 	ImageUrl      sql.NullString `json:"image_url"`
 	VariantName   sql.NullString `json:"variant_name"`
@@ -251,6 +790,38 @@ type ProductVariant struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
+type Promotion struct {
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	Type        PromotionType  `json:"type"`
+	Priority    int32          `json:"priority"`
+	PercentOff  money.Percent  `json:"percent_off"`
+	AmountOff   money.Amount   `json:"amount_off"`
+	MinSubtotal money.Amount   `json:"min_subtotal"`
+	BuyQuantity int32          `json:"buy_quantity"`
+	GetQuantity int32          `json:"get_quantity"`
+	BundlePrice money.Amount   `json:"bundle_price"`
+	StartsAt    sql.NullTime   `json:"starts_at"`
+	EndsAt      sql.NullTime   `json:"ends_at"`
+	IsActive    bool           `json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+type PromotionCategory struct {
+	PromotionID uuid.UUID           `json:"promotion_id"`
+	CategoryID  uuid.UUID           `json:"category_id"`
+	Role        PromotionTargetRole `json:"role"`
+}
+
+type PromotionProduct struct {
+	PromotionID uuid.UUID           `json:"promotion_id"`
+	ProductID   uuid.UUID           `json:"product_id"`
+	Role        PromotionTargetRole `json:"role"`
+	Quantity    int32               `json:"quantity"`
+}
+
 type RefreshToken struct {
 	Token     string       `json:"token"`
 	CreatedAt time.Time    `json:"created_at"`
@@ -260,11 +831,70 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime `json:"revoked_at"`
 }
 
+type Refund struct {
+	ID                   uuid.UUID      `json:"id"`
+	OrderID              uuid.UUID      `json:"order_id"`
+	Amount               money.Amount   `json:"amount"`
+	ShippingAmount       money.Amount   `json:"shipping_amount"`
+	Reason               sql.NullString `json:"reason"`
+	Restock              bool           `json:"restock"`
+	CreatedBy            uuid.NullUUID  `json:"created_by"`
+	CreatedAt            time.Time      `json:"created_at"`
+	PaymentTransactionID uuid.NullUUID  `json:"payment_transaction_id"`
+}
+
+type RefundsVariant struct {
+	RefundID         uuid.UUID    `json:"refund_id"`
+	OrderID          uuid.UUID    `json:"order_id"`
+	ProductVariantID uuid.UUID    `json:"product_variant_id"`
+	Quantity         int32        `json:"quantity"`
+	Amount           money.Amount `json:"amount"`
+}
+
+type Return struct {
+	ID             uuid.UUID      `json:"id"`
+	OrderID        uuid.UUID      `json:"order_id"`
+	Status         ReturnStatus   `json:"status"`
+	Reason         string         `json:"reason"`
+	ResolutionNote sql.NullString `json:"resolution_note"`
+	Restocked      bool           `json:"restocked"`
+	RefundID       uuid.NullUUID  `json:"refund_id"`
+	CreatedBy      uuid.NullUUID  `json:"created_by"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type ReturnsVariant struct {
+	ReturnID         uuid.UUID `json:"return_id"`
+	OrderID          uuid.UUID `json:"order_id"`
+	ProductVariantID uuid.UUID `json:"product_variant_id"`
+	Quantity         int32     `json:"quantity"`
+}
+
+type Shipment struct {
+	ID                  uuid.UUID      `json:"id"`
+	OrderID             uuid.UUID      `json:"order_id"`
+	Carrier             string         `json:"carrier"`
+	TrackingNumber      sql.NullString `json:"tracking_number"`
+	TrackingUrlTemplate sql.NullString `json:"tracking_url_template"`
+	ShippedAt           time.Time      `json:"shipped_at"`
+	CreatedBy           uuid.NullUUID  `json:"created_by"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+}
+
+type ShipmentsVariant struct {
+	ShipmentID       uuid.UUID `json:"shipment_id"`
+	OrderID          uuid.UUID `json:"order_id"`
+	ProductVariantID uuid.UUID `json:"product_variant_id"`
+	Quantity         int32     `json:"quantity"`
+}
+
 type ShippingOption struct {
 	ID            uuid.UUID      `json:"id"`
 	Name          string         `json:"name"`
 	Description   sql.NullString `json:"description"`
-	Price         float64        `json:"price"`
+	Price         money.Amount   `json:"price"`
 	EstimatedDays string         `json:"estimated_days"`
 	SortOrder     int32          `json:"sort_order"`
 	IsActive      bool           `json:"is_active"`
@@ -272,6 +902,23 @@ type ShippingOption struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
+type TaxClass struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TaxRate struct {
+	ID         uuid.UUID     `json:"id"`
+	CountryID  uuid.UUID     `json:"country_id"`
+	TaxClassID uuid.NullUUID `json:"tax_class_id"`
+	Name       string        `json:"name"`
+	Rate       money.Percent `json:"rate"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
 type User struct {
 	ID           uuid.UUID    `json:"id"`
 	Email        string       `json:"email"`
@@ -299,4 +946,5 @@ type UsersAddress struct {
 	IsBillingDefault  bool           `json:"is_billing_default"`
 	CreatedAt         sql.NullTime   `json:"created_at"`
 	UpdatedAt         sql.NullTime   `json:"updated_at"`
+	VatID             sql.NullString `json:"vat_id"`
 }
@@ -10,9 +10,51 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
+const addOrderItem = `-- name: AddOrderItem :one
+INSERT INTO orders_variants (order_id, product_variant_id, quantity, price_per_item, total_price)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING order_id, product_variant_id, quantity, price_per_item, total_price, created_at, updated_at
+`
+
+type AddOrderItemParams struct {
+	OrderID          uuid.UUID    `json:"order_id"`
+	ProductVariantID uuid.UUID    `json:"product_variant_id"`
+	Quantity         int32        `json:"quantity"`
+	PricePerItem     money.Amount `json:"price_per_item"`
+	TotalPrice       money.Amount `json:"total_price"`
+}
+
+func (q *Queries) AddOrderItem(ctx context.Context, arg AddOrderItemParams) (OrdersVariant, error) {
+	row := q.db.QueryRowContext(ctx, addOrderItem,
+		arg.OrderID,
+		arg.ProductVariantID,
+		arg.Quantity,
+		arg.PricePerItem,
+		arg.TotalPrice,
+	)
+	var i OrdersVariant
+	err := row.Scan(
+		&i.OrderID,
+		&i.ProductVariantID,
+		&i.Quantity,
+		&i.PricePerItem,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const copyCartDataIntoOrder = `-- name: CopyCartDataIntoOrder :many
 INSERT INTO orders_variants (order_id, product_variant_id, quantity, price_per_item, total_price)
 SELECT 
@@ -69,6 +111,7 @@ LEFT JOIN users u ON u.id = o.user_id
 WHERE
   (
     $1::text IS NULL
+    OR o.order_number ILIKE '%' || $1 || '%'
     OR o.customer_email ILIKE '%' || $1 || '%'
     OR o.shipping_name ILIKE '%' || $1 || '%'
     OR o.billing_name ILIKE '%' || $1 || '%'
@@ -113,7 +156,29 @@ func (q *Queries) CountOrders(ctx context.Context, arg CountOrdersParams) (int64
 	return count, err
 }
 
+const countOrdersByUserId = `-- name: CountOrdersByUserId :one
+SELECT COUNT(*)
+FROM orders
+WHERE user_id = $1
+`
+
+func (q *Queries) CountOrdersByUserId(ctx context.Context, userID uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOrdersByUserId, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createOrder = `-- name: CreateOrder :one
+WITH next_number AS (
+    INSERT INTO order_number_counters (period, last_value)
+    VALUES (
+        CASE WHEN $1::text LIKE '%{year}%' THEN EXTRACT(YEAR FROM CURRENT_DATE)::int ELSE 0 END,
+        1
+    )
+    ON CONFLICT (period) DO UPDATE SET last_value = order_number_counters.last_value + 1
+    RETURNING period, last_value
+)
 INSERT INTO orders (
     user_id, 
     total_price, 
@@ -131,10 +196,20 @@ INSERT INTO orders (
     billing_country_id,
     shipping_option_id,
     shipping_price,
-    payment_option_id
+    payment_option_id,
+    customer_note,
+    currency,
+    exchange_rate,
+    tax_total,
+    prices_include_tax,
+    vat_id,
+    reverse_charge,
+    coupon_id,
+    coupon_code,
+    discount_total,
+    order_number
 )
-VALUES (
-    $1, 
+SELECT
     $2, 
     $3, 
     $4, 
@@ -147,36 +222,65 @@ VALUES (
     $11, 
     $12, 
     $13, 
-    $14,
+    $14, 
     $15,
     $16,
-    $17
-)
-RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status
+    $17,
+    $18,
+    $19,
+    $20,
+    $21,
+    $22,
+    $23,
+    $24,
+    $25,
+    $26,
+    $27,
+    $28,
+    replace(
+        replace($1::text, '{year}', next_number.period::text),
+        '{seq}',
+        lpad(next_number.last_value::text, GREATEST($29::int, length(next_number.last_value::text)), '0')
+    )
+FROM next_number
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
 `
 
 type CreateOrderParams struct {
-	UserID             uuid.NullUUID `json:"user_id"`
-	TotalPrice         float64       `json:"total_price"`
-	CustomerEmail      string        `json:"customer_email"`
-	ShippingName       string        `json:"shipping_name"`
-	ShippingAddress    string        `json:"shipping_address"`
-	ShippingCity       string        `json:"shipping_city"`
-	ShippingPostalCode string        `json:"shipping_postal_code"`
-	ShippingCountryID  uuid.UUID     `json:"shipping_country_id"`
-	ShippingPhone      string        `json:"shipping_phone"`
-	BillingName        string        `json:"billing_name"`
-	BillingAddress     string        `json:"billing_address"`
-	BillingCity        string        `json:"billing_city"`
-	BillingPostalCode  string        `json:"billing_postal_code"`
-	BillingCountryID   uuid.UUID     `json:"billing_country_id"`
-	ShippingOptionID   uuid.UUID     `json:"shipping_option_id"`
-	ShippingPrice      float64       `json:"shipping_price"`
-	PaymentOptionID    uuid.UUID     `json:"payment_option_id"`
+	OrderNumberFormat  string         `json:"order_number_format"`
+	UserID             uuid.NullUUID  `json:"user_id"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	CustomerEmail      string         `json:"customer_email"`
+	ShippingName       string         `json:"shipping_name"`
+	ShippingAddress    string         `json:"shipping_address"`
+	ShippingCity       string         `json:"shipping_city"`
+	ShippingPostalCode string         `json:"shipping_postal_code"`
+	ShippingCountryID  uuid.UUID      `json:"shipping_country_id"`
+	ShippingPhone      string         `json:"shipping_phone"`
+	BillingName        string         `json:"billing_name"`
+	BillingAddress     string         `json:"billing_address"`
+	BillingCity        string         `json:"billing_city"`
+	BillingPostalCode  string         `json:"billing_postal_code"`
+	BillingCountryID   uuid.UUID      `json:"billing_country_id"`
+	ShippingOptionID   uuid.UUID      `json:"shipping_option_id"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	PaymentOptionID    uuid.UUID      `json:"payment_option_id"`
+	CustomerNote       sql.NullString `json:"customer_note"`
+	Currency           sql.NullString `json:"currency"`
+	ExchangeRate       money.Rate     `json:"exchange_rate"`
+	TaxTotal           money.Amount   `json:"tax_total"`
+	PricesIncludeTax   bool           `json:"prices_include_tax"`
+	VatID              sql.NullString `json:"vat_id"`
+	ReverseCharge      bool           `json:"reverse_charge"`
+	CouponID           uuid.NullUUID  `json:"coupon_id"`
+	CouponCode         sql.NullString `json:"coupon_code"`
+	DiscountTotal      money.Amount   `json:"discount_total"`
+	OrderNumberDigits  int32          `json:"order_number_digits"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
 	row := q.db.QueryRowContext(ctx, createOrder,
+		arg.OrderNumberFormat,
 		arg.UserID,
 		arg.TotalPrice,
 		arg.CustomerEmail,
@@ -194,6 +298,17 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.ShippingOptionID,
 		arg.ShippingPrice,
 		arg.PaymentOptionID,
+		arg.CustomerNote,
+		arg.Currency,
+		arg.ExchangeRate,
+		arg.TaxTotal,
+		arg.PricesIncludeTax,
+		arg.VatID,
+		arg.ReverseCharge,
+		arg.CouponID,
+		arg.CouponCode,
+		arg.DiscountTotal,
+		arg.OrderNumberDigits,
 	)
 	var i Order
 	err := row.Scan(
@@ -219,129 +334,160 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.ShippingCountryID,
 		&i.BillingCountryID,
 		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
 	)
 	return i, err
 }
 
-const getOrderById = `-- name: GetOrderById :one
-SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status FROM orders
-WHERE id = $1
+const deleteOrderItem = `-- name: DeleteOrderItem :exec
+DELETE FROM orders_variants
+WHERE order_id = $1 AND product_variant_id = $2
 `
 
-func (q *Queries) GetOrderById(ctx context.Context, id uuid.UUID) (Order, error) {
-	row := q.db.QueryRowContext(ctx, getOrderById, id)
-	var i Order
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Status,
-		&i.TotalPrice,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.CustomerEmail,
-		&i.ShippingName,
-		&i.ShippingAddress,
-		&i.ShippingCity,
-		&i.ShippingPostalCode,
-		&i.ShippingPhone,
-		&i.BillingName,
-		&i.BillingAddress,
-		&i.BillingCity,
-		&i.BillingPostalCode,
-		&i.ShippingOptionID,
-		&i.ShippingPrice,
-		&i.PaymentOptionID,
-		&i.ShippingCountryID,
-		&i.BillingCountryID,
-		&i.PaymentStatus,
-	)
-	return i, err
+type DeleteOrderItemParams struct {
+	OrderID          uuid.UUID `json:"order_id"`
+	ProductVariantID uuid.UUID `json:"product_variant_id"`
 }
 
-const getOrderItemsByOrderId = `-- name: GetOrderItemsByOrderId :many
-SELECT order_id, product_variant_id, quantity, price_per_item, total_price, created_at, updated_at FROM orders_variants
-WHERE order_id = $1
-`
-
-func (q *Queries) GetOrderItemsByOrderId(ctx context.Context, orderID uuid.UUID) ([]OrdersVariant, error) {
-	rows, err := q.db.QueryContext(ctx, getOrderItemsByOrderId, orderID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []OrdersVariant
-	for rows.Next() {
-		var i OrdersVariant
-		if err := rows.Scan(
-			&i.OrderID,
-			&i.ProductVariantID,
-			&i.Quantity,
-			&i.PricePerItem,
-			&i.TotalPrice,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) DeleteOrderItem(ctx context.Context, arg DeleteOrderItemParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOrderItem, arg.OrderID, arg.ProductVariantID)
+	return err
 }
 
-const getOrderItemsByOrderIdWithVariants = `-- name: GetOrderItemsByOrderIdWithVariants :many
+const exportOrderItems = `-- name: ExportOrderItems :many
 SELECT
-  ov.order_id,
+  o.id AS order_id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.created_at,
+  o.customer_email,
   ov.product_variant_id,
+  v.sku,
+  pr.name AS product_name,
+  v.variant_name,
   ov.quantity,
   ov.price_per_item,
-  pv.sku,
-  pv.variant_name AS variant_name,
-  pv.price,
-  pv.image_url,
-  p.name AS product_name
-  FROM orders_variants ov
-  JOIN product_variants pv ON pv.id = ov.product_variant_id
-  JOIN products p ON p.id = pv.product_id
-  WHERE ov.order_id = $1
+  o.currency,
+  o.exchange_rate
+FROM
+  orders_variants ov
+JOIN orders o ON o.id = ov.order_id
+JOIN product_variants v ON v.id = ov.product_variant_id
+JOIN products pr ON pr.id = v.product_id
+LEFT JOIN users u ON u.id = o.user_id
+WHERE
+  (
+    $1::text IS NULL
+    OR o.order_number ILIKE '%' || $1 || '%'
+    OR o.customer_email ILIKE '%' || $1 || '%'
+    OR o.shipping_name ILIKE '%' || $1 || '%'
+    OR o.billing_name ILIKE '%' || $1 || '%'
+    OR u.email ILIKE '%' || $1 || '%'
+  )
+  AND (
+    $2::order_status IS NULL
+    OR o.status = $2
+  )
+  AND (
+    $3::payment_status IS NULL
+    OR o.payment_status = $3
+  )
+  AND (
+    $4::timestamp IS NULL
+    OR o.created_at >= $4
+  )
+  AND (
+    $5::timestamp IS NULL
+    OR o.created_at <= $5
+  )
+  AND (
+    $6::timestamp IS NULL
+    OR (o.created_at, o.id) < ($6, $7::uuid)
+    OR (
+      (o.created_at, o.id) = ($6, $7::uuid)
+      AND v.sku > $8::text
+    )
+  )
+ORDER BY o.created_at DESC, o.id DESC, v.sku
+LIMIT $9
 `
 
-type GetOrderItemsByOrderIdWithVariantsRow struct {
+type ExportOrderItemsParams struct {
+	Search         sql.NullString    `json:"search"`
+	Status         NullOrderStatus   `json:"status"`
+	PaymentStatus  NullPaymentStatus `json:"payment_status"`
+	DateFrom       sql.NullTime      `json:"date_from"`
+	DateTo         sql.NullTime      `json:"date_to"`
+	AfterCreatedAt sql.NullTime      `json:"after_created_at"`
+	AfterOrderID   uuid.NullUUID     `json:"after_order_id"`
+	AfterSku       sql.NullString    `json:"after_sku"`
+	PageSize       int32             `json:"page_size"`
+}
+
+type ExportOrderItemsRow struct {
 	OrderID          uuid.UUID      `json:"order_id"`
+	OrderNumber      string         `json:"order_number"`
+	Status           OrderStatus    `json:"status"`
+	PaymentStatus    PaymentStatus  `json:"payment_status"`
+	CreatedAt        time.Time      `json:"created_at"`
+	CustomerEmail    string         `json:"customer_email"`
 	ProductVariantID uuid.UUID      `json:"product_variant_id"`
-	Quantity         int32          `json:"quantity"`
-	PricePerItem     float64        `json:"price_per_item"`
 	Sku              string         `json:"sku"`
-	VariantName      sql.NullString `json:"variant_name"`
-	Price            float64        `json:"price"`
-	ImageUrl         sql.NullString `json:"image_url"`
 	ProductName      string         `json:"product_name"`
+	VariantName      sql.NullString `json:"variant_name"`
+	Quantity         int32          `json:"quantity"`
+	PricePerItem     money.Amount   `json:"price_per_item"`
+	Currency         sql.NullString `json:"currency"`
+	ExchangeRate     money.Rate     `json:"exchange_rate"`
 }
 
-func (q *Queries) GetOrderItemsByOrderIdWithVariants(ctx context.Context, orderID uuid.UUID) ([]GetOrderItemsByOrderIdWithVariantsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getOrderItemsByOrderIdWithVariants, orderID)
+func (q *Queries) ExportOrderItems(ctx context.Context, arg ExportOrderItemsParams) ([]ExportOrderItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportOrderItems,
+		arg.Search,
+		arg.Status,
+		arg.PaymentStatus,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.AfterCreatedAt,
+		arg.AfterOrderID,
+		arg.AfterSku,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetOrderItemsByOrderIdWithVariantsRow
+	var items []ExportOrderItemsRow
 	for rows.Next() {
-		var i GetOrderItemsByOrderIdWithVariantsRow
+		var i ExportOrderItemsRow
 		if err := rows.Scan(
 			&i.OrderID,
+			&i.OrderNumber,
+			&i.Status,
+			&i.PaymentStatus,
+			&i.CreatedAt,
+			&i.CustomerEmail,
 			&i.ProductVariantID,
-			&i.Quantity,
-			&i.PricePerItem,
 			&i.Sku,
-			&i.VariantName,
-			&i.Price,
-			&i.ImageUrl,
 			&i.ProductName,
+			&i.VariantName,
+			&i.Quantity,
+			&i.PricePerItem,
+			&i.Currency,
+			&i.ExchangeRate,
 		); err != nil {
 			return nil, err
 		}
@@ -356,71 +502,750 @@ func (q *Queries) GetOrderItemsByOrderIdWithVariants(ctx context.Context, orderI
 	return items, nil
 }
 
-const getOrderWithUserById = `-- name: GetOrderWithUserById :one
-SELECT 
+const exportOrders = `-- name: ExportOrders :many
+SELECT
   o.id,
-  o.user_id,
+  o.order_number,
   o.status,
   o.payment_status,
-  o.total_price,
   o.created_at,
-  o.updated_at,
   o.customer_email,
+  u.email AS user_email,
   o.shipping_name,
   o.shipping_address,
   o.shipping_city,
   o.shipping_postal_code,
+  sc.iso_code AS shipping_country_code,
   o.shipping_phone,
   o.billing_name,
   o.billing_address,
   o.billing_city,
   o.billing_postal_code,
-  o.shipping_option_id,
-  o.shipping_price,
-  o.payment_option_id,
-  o.shipping_country_id,
-  o.billing_country_id,
+  bc.iso_code AS billing_country_code,
+  o.vat_id,
   s.name AS shipping_method_name,
   p.name AS payment_method_name,
-  u.email AS user_email,
-  u.full_name AS user_name,
-  u.created_at AS user_created_at
+  (
+    SELECT COALESCE(SUM(ov.quantity), 0)
+    FROM orders_variants ov
+    WHERE ov.order_id = o.id
+  )::int AS item_count,
+  o.shipping_price,
+  o.tax_total,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.total_price,
+  o.currency,
+  o.exchange_rate,
+  o.customer_note
 FROM
   orders o
 LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
 LEFT JOIN payment_options p ON p.id = o.payment_option_id
 LEFT JOIN users u ON u.id = o.user_id
-WHERE o.id = $1
+LEFT JOIN countries sc ON sc.id = o.shipping_country_id
+LEFT JOIN countries bc ON bc.id = o.billing_country_id
+WHERE
+  (
+    $1::text IS NULL
+    OR o.order_number ILIKE '%' || $1 || '%'
+    OR o.customer_email ILIKE '%' || $1 || '%'
+    OR o.shipping_name ILIKE '%' || $1 || '%'
+    OR o.billing_name ILIKE '%' || $1 || '%'
+    OR u.email ILIKE '%' || $1 || '%'
+  )
+  AND (
+    $2::order_status IS NULL
+    OR o.status = $2
+  )
+  AND (
+    $3::payment_status IS NULL
+    OR o.payment_status = $3
+  )
+  AND (
+    $4::timestamp IS NULL
+    OR o.created_at >= $4
+  )
+  AND (
+    $5::timestamp IS NULL
+    OR o.created_at <= $5
+  )
+  AND (
+    $6::timestamp IS NULL
+    OR (o.created_at, o.id) < ($6, $7::uuid)
+  )
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT $8
 `
 
-type GetOrderWithUserByIdRow struct {
-	ID                 uuid.UUID      `json:"id"`
-	UserID             uuid.NullUUID  `json:"user_id"`
-	Status             OrderStatus    `json:"status"`
-	PaymentStatus      PaymentStatus  `json:"payment_status"`
-	TotalPrice         float64        `json:"total_price"`
-	CreatedAt          time.Time      `json:"created_at"`
-	UpdatedAt          time.Time      `json:"updated_at"`
-	CustomerEmail      string         `json:"customer_email"`
-	ShippingName       string         `json:"shipping_name"`
-	ShippingAddress    string         `json:"shipping_address"`
-	ShippingCity       string         `json:"shipping_city"`
-	ShippingPostalCode string         `json:"shipping_postal_code"`
-	ShippingPhone      string         `json:"shipping_phone"`
-	BillingName        string         `json:"billing_name"`
-	BillingAddress     string         `json:"billing_address"`
-	BillingCity        string         `json:"billing_city"`
-	BillingPostalCode  string         `json:"billing_postal_code"`
-	ShippingOptionID   uuid.UUID      `json:"shipping_option_id"`
-	ShippingPrice      float64        `json:"shipping_price"`
-	PaymentOptionID    uuid.UUID      `json:"payment_option_id"`
-	ShippingCountryID  uuid.UUID      `json:"shipping_country_id"`
-	BillingCountryID   uuid.UUID      `json:"billing_country_id"`
-	ShippingMethodName sql.NullString `json:"shipping_method_name"`
-	PaymentMethodName  sql.NullString `json:"payment_method_name"`
-	UserEmail          sql.NullString `json:"user_email"`
-	UserName           sql.NullString `json:"user_name"`
-	UserCreatedAt      sql.NullTime   `json:"user_created_at"`
+type ExportOrdersParams struct {
+	Search         sql.NullString    `json:"search"`
+	Status         NullOrderStatus   `json:"status"`
+	PaymentStatus  NullPaymentStatus `json:"payment_status"`
+	DateFrom       sql.NullTime      `json:"date_from"`
+	DateTo         sql.NullTime      `json:"date_to"`
+	AfterCreatedAt sql.NullTime      `json:"after_created_at"`
+	AfterID        uuid.NullUUID     `json:"after_id"`
+	PageSize       int32             `json:"page_size"`
+}
+
+type ExportOrdersRow struct {
+	ID                  uuid.UUID      `json:"id"`
+	OrderNumber         string         `json:"order_number"`
+	Status              OrderStatus    `json:"status"`
+	PaymentStatus       PaymentStatus  `json:"payment_status"`
+	CreatedAt           time.Time      `json:"created_at"`
+	CustomerEmail       string         `json:"customer_email"`
+	UserEmail           sql.NullString `json:"user_email"`
+	ShippingName        string         `json:"shipping_name"`
+	ShippingAddress     string         `json:"shipping_address"`
+	ShippingCity        string         `json:"shipping_city"`
+	ShippingPostalCode  string         `json:"shipping_postal_code"`
+	ShippingCountryCode sql.NullString `json:"shipping_country_code"`
+	ShippingPhone       string         `json:"shipping_phone"`
+	BillingName         string         `json:"billing_name"`
+	BillingAddress      string         `json:"billing_address"`
+	BillingCity         string         `json:"billing_city"`
+	BillingPostalCode   string         `json:"billing_postal_code"`
+	BillingCountryCode  sql.NullString `json:"billing_country_code"`
+	VatID               sql.NullString `json:"vat_id"`
+	ShippingMethodName  sql.NullString `json:"shipping_method_name"`
+	PaymentMethodName   sql.NullString `json:"payment_method_name"`
+	ItemCount           int32          `json:"item_count"`
+	ShippingPrice       money.Amount   `json:"shipping_price"`
+	TaxTotal            money.Amount   `json:"tax_total"`
+	ReverseCharge       bool           `json:"reverse_charge"`
+	CouponCode          sql.NullString `json:"coupon_code"`
+	DiscountTotal       money.Amount   `json:"discount_total"`
+	TotalPrice          money.Amount   `json:"total_price"`
+	Currency            sql.NullString `json:"currency"`
+	ExchangeRate        money.Rate     `json:"exchange_rate"`
+	CustomerNote        sql.NullString `json:"customer_note"`
+}
+
+func (q *Queries) ExportOrders(ctx context.Context, arg ExportOrdersParams) ([]ExportOrdersRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportOrders,
+		arg.Search,
+		arg.Status,
+		arg.PaymentStatus,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportOrdersRow
+	for rows.Next() {
+		var i ExportOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderNumber,
+			&i.Status,
+			&i.PaymentStatus,
+			&i.CreatedAt,
+			&i.CustomerEmail,
+			&i.UserEmail,
+			&i.ShippingName,
+			&i.ShippingAddress,
+			&i.ShippingCity,
+			&i.ShippingPostalCode,
+			&i.ShippingCountryCode,
+			&i.ShippingPhone,
+			&i.BillingName,
+			&i.BillingAddress,
+			&i.BillingCity,
+			&i.BillingPostalCode,
+			&i.BillingCountryCode,
+			&i.VatID,
+			&i.ShippingMethodName,
+			&i.PaymentMethodName,
+			&i.ItemCount,
+			&i.ShippingPrice,
+			&i.TaxTotal,
+			&i.ReverseCharge,
+			&i.CouponCode,
+			&i.DiscountTotal,
+			&i.TotalPrice,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.CustomerNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderById = `-- name: GetOrderById :one
+SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total FROM orders
+WHERE id = $1
+`
+
+func (q *Queries) GetOrderById(ctx context.Context, id uuid.UUID) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderById, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const getOrderByIdForUpdate = `-- name: GetOrderByIdForUpdate :one
+SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total FROM orders
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderByIdForUpdate(ctx context.Context, id uuid.UUID) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderByIdForUpdate, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const getOrderByPaymentReferenceForUpdate = `-- name: GetOrderByPaymentReferenceForUpdate :one
+SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total FROM orders
+WHERE payment_provider = $1
+  AND payment_reference = $2
+FOR UPDATE
+`
+
+type GetOrderByPaymentReferenceForUpdateParams struct {
+	PaymentProvider  sql.NullString `json:"payment_provider"`
+	PaymentReference sql.NullString `json:"payment_reference"`
+}
+
+func (q *Queries) GetOrderByPaymentReferenceForUpdate(ctx context.Context, arg GetOrderByPaymentReferenceForUpdateParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderByPaymentReferenceForUpdate, arg.PaymentProvider, arg.PaymentReference)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const getOrderItemsByOrderId = `-- name: GetOrderItemsByOrderId :many
+SELECT order_id, product_variant_id, quantity, price_per_item, total_price, created_at, updated_at FROM orders_variants
+WHERE order_id = $1
+`
+
+func (q *Queries) GetOrderItemsByOrderId(ctx context.Context, orderID uuid.UUID) ([]OrdersVariant, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderItemsByOrderId, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrdersVariant
+	for rows.Next() {
+		var i OrdersVariant
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.ProductVariantID,
+			&i.Quantity,
+			&i.PricePerItem,
+			&i.TotalPrice,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderItemsByOrderIdWithVariants = `-- name: GetOrderItemsByOrderIdWithVariants :many
+SELECT
+  ov.order_id,
+  ov.product_variant_id,
+  ov.quantity,
+  ov.price_per_item,
+  pv.sku,
+  pv.variant_name AS variant_name,
+  pv.price,
+  pv.image_url,
+  p.name AS product_name
+  FROM orders_variants ov
+  JOIN product_variants pv ON pv.id = ov.product_variant_id
+  JOIN products p ON p.id = pv.product_id
+  WHERE ov.order_id = $1
+`
+
+type GetOrderItemsByOrderIdWithVariantsRow struct {
+	OrderID          uuid.UUID      `json:"order_id"`
+	ProductVariantID uuid.UUID      `json:"product_variant_id"`
+	Quantity         int32          `json:"quantity"`
+	PricePerItem     money.Amount   `json:"price_per_item"`
+	Sku              string         `json:"sku"`
+	VariantName      sql.NullString `json:"variant_name"`
+	Price            money.Amount   `json:"price"`
+	ImageUrl         sql.NullString `json:"image_url"`
+	ProductName      string         `json:"product_name"`
+}
+
+func (q *Queries) GetOrderItemsByOrderIdWithVariants(ctx context.Context, orderID uuid.UUID) ([]GetOrderItemsByOrderIdWithVariantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderItemsByOrderIdWithVariants, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderItemsByOrderIdWithVariantsRow
+	for rows.Next() {
+		var i GetOrderItemsByOrderIdWithVariantsRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.ProductVariantID,
+			&i.Quantity,
+			&i.PricePerItem,
+			&i.Sku,
+			&i.VariantName,
+			&i.Price,
+			&i.ImageUrl,
+			&i.ProductName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderItemsForUserOrders = `-- name: GetOrderItemsForUserOrders :many
+SELECT
+  ov.order_id,
+  ov.product_variant_id,
+  ov.quantity,
+  ov.price_per_item,
+  pv.sku,
+  pv.variant_name AS variant_name,
+  pv.price,
+  pv.image_url,
+  p.name AS product_name
+  FROM orders_variants ov
+  JOIN product_variants pv ON pv.id = ov.product_variant_id
+  JOIN products p ON p.id = pv.product_id
+  WHERE ov.order_id IN (
+    SELECT o.id
+    FROM orders o
+    WHERE o.user_id = $1
+    ORDER BY o.created_at DESC, o.id DESC
+    LIMIT $2
+    OFFSET $3
+  )
+`
+
+type GetOrderItemsForUserOrdersParams struct {
+	UserID uuid.NullUUID `json:"user_id"`
+	Limit  int64         `json:"limit"`
+	Offset int64         `json:"offset"`
+}
+
+type GetOrderItemsForUserOrdersRow struct {
+	OrderID          uuid.UUID      `json:"order_id"`
+	ProductVariantID uuid.UUID      `json:"product_variant_id"`
+	Quantity         int32          `json:"quantity"`
+	PricePerItem     money.Amount   `json:"price_per_item"`
+	Sku              string         `json:"sku"`
+	VariantName      sql.NullString `json:"variant_name"`
+	Price            money.Amount   `json:"price"`
+	ImageUrl         sql.NullString `json:"image_url"`
+	ProductName      string         `json:"product_name"`
+}
+
+func (q *Queries) GetOrderItemsForUserOrders(ctx context.Context, arg GetOrderItemsForUserOrdersParams) ([]GetOrderItemsForUserOrdersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderItemsForUserOrders, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderItemsForUserOrdersRow
+	for rows.Next() {
+		var i GetOrderItemsForUserOrdersRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.ProductVariantID,
+			&i.Quantity,
+			&i.PricePerItem,
+			&i.Sku,
+			&i.VariantName,
+			&i.Price,
+			&i.ImageUrl,
+			&i.ProductName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderStatusById = `-- name: GetOrderStatusById :one
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.created_at,
+  o.updated_at,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.id = $1
+`
+
+type GetOrderStatusByIdRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	OrderNumber        string         `json:"order_number"`
+	Status             OrderStatus    `json:"status"`
+	PaymentStatus      PaymentStatus  `json:"payment_status"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	Currency           sql.NullString `json:"currency"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	TaxTotal           money.Amount   `json:"tax_total"`
+	PricesIncludeTax   bool           `json:"prices_include_tax"`
+	VatID              sql.NullString `json:"vat_id"`
+	ReverseCharge      bool           `json:"reverse_charge"`
+	CouponCode         sql.NullString `json:"coupon_code"`
+	DiscountTotal      money.Amount   `json:"discount_total"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	ShippingMethodName sql.NullString `json:"shipping_method_name"`
+	PaymentMethodName  sql.NullString `json:"payment_method_name"`
+}
+
+func (q *Queries) GetOrderStatusById(ctx context.Context, id uuid.UUID) (GetOrderStatusByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrderStatusById, id)
+	var i GetOrderStatusByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.Status,
+		&i.PaymentStatus,
+		&i.TotalPrice,
+		&i.Currency,
+		&i.ShippingPrice,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponCode,
+		&i.DiscountTotal,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShippingMethodName,
+		&i.PaymentMethodName,
+	)
+	return i, err
+}
+
+const getOrderStatusByNumberAndEmail = `-- name: GetOrderStatusByNumberAndEmail :one
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.created_at,
+  o.updated_at,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.order_number = $1
+  AND LOWER(o.customer_email) = LOWER($2)
+`
+
+type GetOrderStatusByNumberAndEmailParams struct {
+	OrderNumber   string `json:"order_number"`
+	CustomerEmail string `json:"customer_email"`
+}
+
+type GetOrderStatusByNumberAndEmailRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	OrderNumber        string         `json:"order_number"`
+	Status             OrderStatus    `json:"status"`
+	PaymentStatus      PaymentStatus  `json:"payment_status"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	Currency           sql.NullString `json:"currency"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	TaxTotal           money.Amount   `json:"tax_total"`
+	PricesIncludeTax   bool           `json:"prices_include_tax"`
+	VatID              sql.NullString `json:"vat_id"`
+	ReverseCharge      bool           `json:"reverse_charge"`
+	CouponCode         sql.NullString `json:"coupon_code"`
+	DiscountTotal      money.Amount   `json:"discount_total"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	ShippingMethodName sql.NullString `json:"shipping_method_name"`
+	PaymentMethodName  sql.NullString `json:"payment_method_name"`
+}
+
+func (q *Queries) GetOrderStatusByNumberAndEmail(ctx context.Context, arg GetOrderStatusByNumberAndEmailParams) (GetOrderStatusByNumberAndEmailRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrderStatusByNumberAndEmail, arg.OrderNumber, arg.CustomerEmail)
+	var i GetOrderStatusByNumberAndEmailRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.Status,
+		&i.PaymentStatus,
+		&i.TotalPrice,
+		&i.Currency,
+		&i.ShippingPrice,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponCode,
+		&i.DiscountTotal,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ShippingMethodName,
+		&i.PaymentMethodName,
+	)
+	return i, err
+}
+
+const getOrderWithUserById = `-- name: GetOrderWithUserById :one
+SELECT 
+  o.id,
+  o.order_number,
+  o.user_id,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.exchange_rate,
+  o.created_at,
+  o.updated_at,
+  o.customer_email,
+  o.shipping_name,
+  o.shipping_address,
+  o.shipping_city,
+  o.shipping_postal_code,
+  o.shipping_phone,
+  o.billing_name,
+  o.billing_address,
+  o.billing_city,
+  o.billing_postal_code,
+  o.shipping_option_id,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.payment_option_id,
+  o.shipping_country_id,
+  o.billing_country_id,
+  o.customer_note,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name,
+  u.email AS user_email,
+  u.full_name AS user_name,
+  u.created_at AS user_created_at
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+LEFT JOIN users u ON u.id = o.user_id
+WHERE o.id = $1
+`
+
+type GetOrderWithUserByIdRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	OrderNumber        string         `json:"order_number"`
+	UserID             uuid.NullUUID  `json:"user_id"`
+	Status             OrderStatus    `json:"status"`
+	PaymentStatus      PaymentStatus  `json:"payment_status"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	Currency           sql.NullString `json:"currency"`
+	ExchangeRate       money.Rate     `json:"exchange_rate"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	CustomerEmail      string         `json:"customer_email"`
+	ShippingName       string         `json:"shipping_name"`
+	ShippingAddress    string         `json:"shipping_address"`
+	ShippingCity       string         `json:"shipping_city"`
+	ShippingPostalCode string         `json:"shipping_postal_code"`
+	ShippingPhone      string         `json:"shipping_phone"`
+	BillingName        string         `json:"billing_name"`
+	BillingAddress     string         `json:"billing_address"`
+	BillingCity        string         `json:"billing_city"`
+	BillingPostalCode  string         `json:"billing_postal_code"`
+	ShippingOptionID   uuid.UUID      `json:"shipping_option_id"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	TaxTotal           money.Amount   `json:"tax_total"`
+	PricesIncludeTax   bool           `json:"prices_include_tax"`
+	VatID              sql.NullString `json:"vat_id"`
+	ReverseCharge      bool           `json:"reverse_charge"`
+	CouponCode         sql.NullString `json:"coupon_code"`
+	DiscountTotal      money.Amount   `json:"discount_total"`
+	PaymentOptionID    uuid.UUID      `json:"payment_option_id"`
+	ShippingCountryID  uuid.UUID      `json:"shipping_country_id"`
+	BillingCountryID   uuid.UUID      `json:"billing_country_id"`
+	CustomerNote       sql.NullString `json:"customer_note"`
+	ShippingMethodName sql.NullString `json:"shipping_method_name"`
+	PaymentMethodName  sql.NullString `json:"payment_method_name"`
+	UserEmail          sql.NullString `json:"user_email"`
+	UserName           sql.NullString `json:"user_name"`
+	UserCreatedAt      sql.NullTime   `json:"user_created_at"`
 }
 
 func (q *Queries) GetOrderWithUserById(ctx context.Context, id uuid.UUID) (GetOrderWithUserByIdRow, error) {
@@ -428,10 +1253,13 @@ func (q *Queries) GetOrderWithUserById(ctx context.Context, id uuid.UUID) (GetOr
 	var i GetOrderWithUserByIdRow
 	err := row.Scan(
 		&i.ID,
+		&i.OrderNumber,
 		&i.UserID,
 		&i.Status,
 		&i.PaymentStatus,
 		&i.TotalPrice,
+		&i.Currency,
+		&i.ExchangeRate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CustomerEmail,
@@ -446,9 +1274,16 @@ func (q *Queries) GetOrderWithUserById(ctx context.Context, id uuid.UUID) (GetOr
 		&i.BillingPostalCode,
 		&i.ShippingOptionID,
 		&i.ShippingPrice,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponCode,
+		&i.DiscountTotal,
 		&i.PaymentOptionID,
 		&i.ShippingCountryID,
 		&i.BillingCountryID,
+		&i.CustomerNote,
 		&i.ShippingMethodName,
 		&i.PaymentMethodName,
 		&i.UserEmail,
@@ -459,7 +1294,7 @@ func (q *Queries) GetOrderWithUserById(ctx context.Context, id uuid.UUID) (GetOr
 }
 
 const getOrders = `-- name: GetOrders :many
-SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status FROM orders
+SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total FROM orders
 ORDER BY created_at DESC
 `
 
@@ -495,6 +1330,19 @@ func (q *Queries) GetOrders(ctx context.Context) ([]Order, error) {
 			&i.ShippingCountryID,
 			&i.BillingCountryID,
 			&i.PaymentStatus,
+			&i.OrderNumber,
+			&i.CustomerNote,
+			&i.PaymentProvider,
+			&i.PaymentReference,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.TaxTotal,
+			&i.PricesIncludeTax,
+			&i.VatID,
+			&i.ReverseCharge,
+			&i.CouponID,
+			&i.CouponCode,
+			&i.DiscountTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -510,7 +1358,7 @@ func (q *Queries) GetOrders(ctx context.Context) ([]Order, error) {
 }
 
 const getOrdersByOwnerUserId = `-- name: GetOrdersByOwnerUserId :many
-SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status FROM orders
+SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total FROM orders
 WHERE user_id = $1
 ORDER BY created_at DESC
 `
@@ -547,6 +1395,19 @@ func (q *Queries) GetOrdersByOwnerUserId(ctx context.Context, userID uuid.NullUU
 			&i.ShippingCountryID,
 			&i.BillingCountryID,
 			&i.PaymentStatus,
+			&i.OrderNumber,
+			&i.CustomerNote,
+			&i.PaymentProvider,
+			&i.PaymentReference,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.TaxTotal,
+			&i.PricesIncludeTax,
+			&i.VatID,
+			&i.ReverseCharge,
+			&i.CouponID,
+			&i.CouponCode,
+			&i.DiscountTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -562,7 +1423,7 @@ func (q *Queries) GetOrdersByOwnerUserId(ctx context.Context, userID uuid.NullUU
 }
 
 const getOrdersByStatus = `-- name: GetOrdersByStatus :many
-SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status FROM orders
+SELECT id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total FROM orders
 WHERE status IN ($1)
 ORDER BY created_at DESC
 `
@@ -599,6 +1460,19 @@ func (q *Queries) GetOrdersByStatus(ctx context.Context, status OrderStatus) ([]
 			&i.ShippingCountryID,
 			&i.BillingCountryID,
 			&i.PaymentStatus,
+			&i.OrderNumber,
+			&i.CustomerNote,
+			&i.PaymentProvider,
+			&i.PaymentReference,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.TaxTotal,
+			&i.PricesIncludeTax,
+			&i.VatID,
+			&i.ReverseCharge,
+			&i.CouponID,
+			&i.CouponCode,
+			&i.DiscountTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -613,13 +1487,172 @@ func (q *Queries) GetOrdersByStatus(ctx context.Context, status OrderStatus) ([]
 	return items, nil
 }
 
+const getUnpaidOrderIdsCreatedBefore = `-- name: GetUnpaidOrderIdsCreatedBefore :many
+SELECT id FROM orders
+WHERE status = 'pending'
+  AND payment_status IN ('pending', 'failed')
+  AND created_at < $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetUnpaidOrderIdsCreatedBefore(ctx context.Context, createdBefore time.Time) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getUnpaidOrderIdsCreatedBefore, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserOrderById = `-- name: GetUserOrderById :one
+SELECT
+  o.id,
+  o.order_number,
+  o.user_id,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.created_at,
+  o.updated_at,
+  o.customer_email,
+  o.shipping_name,
+  o.shipping_address,
+  o.shipping_city,
+  o.shipping_postal_code,
+  o.shipping_phone,
+  o.billing_name,
+  o.billing_address,
+  o.billing_city,
+  o.billing_postal_code,
+  o.shipping_option_id,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.payment_option_id,
+  o.shipping_country_id,
+  o.billing_country_id,
+  o.customer_note,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.id = $1
+  AND o.user_id = $2
+`
+
+type GetUserOrderByIdParams struct {
+	ID     uuid.UUID     `json:"id"`
+	UserID uuid.NullUUID `json:"user_id"`
+}
+
+type GetUserOrderByIdRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	OrderNumber        string         `json:"order_number"`
+	UserID             uuid.NullUUID  `json:"user_id"`
+	Status             OrderStatus    `json:"status"`
+	PaymentStatus      PaymentStatus  `json:"payment_status"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	Currency           sql.NullString `json:"currency"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	CustomerEmail      string         `json:"customer_email"`
+	ShippingName       string         `json:"shipping_name"`
+	ShippingAddress    string         `json:"shipping_address"`
+	ShippingCity       string         `json:"shipping_city"`
+	ShippingPostalCode string         `json:"shipping_postal_code"`
+	ShippingPhone      string         `json:"shipping_phone"`
+	BillingName        string         `json:"billing_name"`
+	BillingAddress     string         `json:"billing_address"`
+	BillingCity        string         `json:"billing_city"`
+	BillingPostalCode  string         `json:"billing_postal_code"`
+	ShippingOptionID   uuid.UUID      `json:"shipping_option_id"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	TaxTotal           money.Amount   `json:"tax_total"`
+	PricesIncludeTax   bool           `json:"prices_include_tax"`
+	VatID              sql.NullString `json:"vat_id"`
+	ReverseCharge      bool           `json:"reverse_charge"`
+	CouponCode         sql.NullString `json:"coupon_code"`
+	DiscountTotal      money.Amount   `json:"discount_total"`
+	PaymentOptionID    uuid.UUID      `json:"payment_option_id"`
+	ShippingCountryID  uuid.UUID      `json:"shipping_country_id"`
+	BillingCountryID   uuid.UUID      `json:"billing_country_id"`
+	CustomerNote       sql.NullString `json:"customer_note"`
+	ShippingMethodName sql.NullString `json:"shipping_method_name"`
+	PaymentMethodName  sql.NullString `json:"payment_method_name"`
+}
+
+func (q *Queries) GetUserOrderById(ctx context.Context, arg GetUserOrderByIdParams) (GetUserOrderByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserOrderById, arg.ID, arg.UserID)
+	var i GetUserOrderByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.OrderNumber,
+		&i.UserID,
+		&i.Status,
+		&i.PaymentStatus,
+		&i.TotalPrice,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponCode,
+		&i.DiscountTotal,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.CustomerNote,
+		&i.ShippingMethodName,
+		&i.PaymentMethodName,
+	)
+	return i, err
+}
+
 const listOrders = `-- name: ListOrders :many
 SELECT
   o.id,
+  o.order_number,
   o.user_id,
   o.status,
   o.payment_status,
   o.total_price,
+  o.currency,
+  o.exchange_rate,
   o.created_at,
   o.updated_at,
   o.customer_email,
@@ -649,6 +1682,7 @@ LEFT JOIN users u ON u.id = o.user_id
 WHERE
   (
     $3::text IS NULL
+    OR o.order_number ILIKE '%' || $3 || '%'
     OR o.customer_email ILIKE '%' || $3 || '%'
     OR o.shipping_name ILIKE '%' || $3 || '%'
     OR o.billing_name ILIKE '%' || $3 || '%'
@@ -687,10 +1721,13 @@ type ListOrdersParams struct {
 
 type ListOrdersRow struct {
 	ID                 uuid.UUID      `json:"id"`
+	OrderNumber        string         `json:"order_number"`
 	UserID             uuid.NullUUID  `json:"user_id"`
 	Status             OrderStatus    `json:"status"`
 	PaymentStatus      PaymentStatus  `json:"payment_status"`
-	TotalPrice         float64        `json:"total_price"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	Currency           sql.NullString `json:"currency"`
+	ExchangeRate       money.Rate     `json:"exchange_rate"`
 	CreatedAt          time.Time      `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
 	CustomerEmail      string         `json:"customer_email"`
@@ -704,7 +1741,7 @@ type ListOrdersRow struct {
 	BillingCity        string         `json:"billing_city"`
 	BillingPostalCode  string         `json:"billing_postal_code"`
 	ShippingOptionID   uuid.UUID      `json:"shipping_option_id"`
-	ShippingPrice      float64        `json:"shipping_price"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
 	PaymentOptionID    uuid.UUID      `json:"payment_option_id"`
 	ShippingCountryID  uuid.UUID      `json:"shipping_country_id"`
 	BillingCountryID   uuid.UUID      `json:"billing_country_id"`
@@ -733,10 +1770,13 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]ListO
 		var i ListOrdersRow
 		if err := rows.Scan(
 			&i.ID,
+			&i.OrderNumber,
 			&i.UserID,
 			&i.Status,
 			&i.PaymentStatus,
 			&i.TotalPrice,
+			&i.Currency,
+			&i.ExchangeRate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CustomerEmail,
@@ -772,11 +1812,455 @@ func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]ListO
 	return items, nil
 }
 
+const listOrdersByUserId = `-- name: ListOrdersByUserId :many
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.shipping_price,
+  o.created_at,
+  o.updated_at,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name,
+  (
+    SELECT COALESCE(SUM(ov.quantity), 0)
+    FROM orders_variants ov
+    WHERE ov.order_id = o.id
+  )::int AS item_count
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.user_id = $1
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListOrdersByUserIdParams struct {
+	UserID uuid.NullUUID `json:"user_id"`
+	Limit  int64         `json:"limit"`
+	Offset int64         `json:"offset"`
+}
+
+type ListOrdersByUserIdRow struct {
+	ID                 uuid.UUID      `json:"id"`
+	OrderNumber        string         `json:"order_number"`
+	Status             OrderStatus    `json:"status"`
+	PaymentStatus      PaymentStatus  `json:"payment_status"`
+	TotalPrice         money.Amount   `json:"total_price"`
+	Currency           sql.NullString `json:"currency"`
+	ShippingPrice      money.Amount   `json:"shipping_price"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	ShippingMethodName sql.NullString `json:"shipping_method_name"`
+	PaymentMethodName  sql.NullString `json:"payment_method_name"`
+	ItemCount          int32          `json:"item_count"`
+}
+
+func (q *Queries) ListOrdersByUserId(ctx context.Context, arg ListOrdersByUserIdParams) ([]ListOrdersByUserIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrdersByUserId, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrdersByUserIdRow
+	for rows.Next() {
+		var i ListOrdersByUserIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderNumber,
+			&i.Status,
+			&i.PaymentStatus,
+			&i.TotalPrice,
+			&i.Currency,
+			&i.ShippingPrice,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ShippingMethodName,
+			&i.PaymentMethodName,
+			&i.ItemCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateOrderAddresses = `-- name: UpdateOrderAddresses :one
+UPDATE orders
+SET
+    shipping_name = $1,
+    shipping_address = $2,
+    shipping_city = $3,
+    shipping_postal_code = $4,
+    shipping_country_id = $5,
+    shipping_phone = $6,
+    billing_name = $7,
+    billing_address = $8,
+    billing_city = $9,
+    billing_postal_code = $10,
+    billing_country_id = $11
+WHERE id = $12
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
+`
+
+type UpdateOrderAddressesParams struct {
+	ShippingName       string    `json:"shipping_name"`
+	ShippingAddress    string    `json:"shipping_address"`
+	ShippingCity       string    `json:"shipping_city"`
+	ShippingPostalCode string    `json:"shipping_postal_code"`
+	ShippingCountryID  uuid.UUID `json:"shipping_country_id"`
+	ShippingPhone      string    `json:"shipping_phone"`
+	BillingName        string    `json:"billing_name"`
+	BillingAddress     string    `json:"billing_address"`
+	BillingCity        string    `json:"billing_city"`
+	BillingPostalCode  string    `json:"billing_postal_code"`
+	BillingCountryID   uuid.UUID `json:"billing_country_id"`
+	ID                 uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateOrderAddresses(ctx context.Context, arg UpdateOrderAddressesParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderAddresses,
+		arg.ShippingName,
+		arg.ShippingAddress,
+		arg.ShippingCity,
+		arg.ShippingPostalCode,
+		arg.ShippingCountryID,
+		arg.ShippingPhone,
+		arg.BillingName,
+		arg.BillingAddress,
+		arg.BillingCity,
+		arg.BillingPostalCode,
+		arg.BillingCountryID,
+		arg.ID,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const updateOrderItemQuantity = `-- name: UpdateOrderItemQuantity :one
+UPDATE orders_variants
+SET
+    quantity = $1,
+    total_price = $1 * price_per_item,
+    updated_at = NOW()
+WHERE order_id = $2 AND product_variant_id = $3
+RETURNING order_id, product_variant_id, quantity, price_per_item, total_price, created_at, updated_at
+`
+
+type UpdateOrderItemQuantityParams struct {
+	Quantity         int32     `json:"quantity"`
+	OrderID          uuid.UUID `json:"order_id"`
+	ProductVariantID uuid.UUID `json:"product_variant_id"`
+}
+
+func (q *Queries) UpdateOrderItemQuantity(ctx context.Context, arg UpdateOrderItemQuantityParams) (OrdersVariant, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderItemQuantity, arg.Quantity, arg.OrderID, arg.ProductVariantID)
+	var i OrdersVariant
+	err := row.Scan(
+		&i.OrderID,
+		&i.ProductVariantID,
+		&i.Quantity,
+		&i.PricePerItem,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateOrderPaymentReference = `-- name: UpdateOrderPaymentReference :one
+UPDATE orders
+SET
+    payment_provider = $1,
+    payment_reference = $2
+WHERE id = $3
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
+`
+
+type UpdateOrderPaymentReferenceParams struct {
+	PaymentProvider  sql.NullString `json:"payment_provider"`
+	PaymentReference sql.NullString `json:"payment_reference"`
+	ID               uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) UpdateOrderPaymentReference(ctx context.Context, arg UpdateOrderPaymentReferenceParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderPaymentReference, arg.PaymentProvider, arg.PaymentReference, arg.ID)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const updateOrderPaymentStatus = `-- name: UpdateOrderPaymentStatus :one
+UPDATE orders
+SET payment_status = $1
+WHERE id = $2
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
+`
+
+type UpdateOrderPaymentStatusParams struct {
+	PaymentStatus PaymentStatus `json:"payment_status"`
+	ID            uuid.UUID     `json:"id"`
+}
+
+func (q *Queries) UpdateOrderPaymentStatus(ctx context.Context, arg UpdateOrderPaymentStatusParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderPaymentStatus, arg.PaymentStatus, arg.ID)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const updateOrderReverseCharge = `-- name: UpdateOrderReverseCharge :one
+UPDATE orders
+SET reverse_charge = $1
+WHERE id = $2
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
+`
+
+type UpdateOrderReverseChargeParams struct {
+	ReverseCharge bool      `json:"reverse_charge"`
+	ID            uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateOrderReverseCharge(ctx context.Context, arg UpdateOrderReverseChargeParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderReverseCharge, arg.ReverseCharge, arg.ID)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
+const updateOrderShippingAndTotal = `-- name: UpdateOrderShippingAndTotal :one
+UPDATE orders
+SET
+    shipping_option_id = $1,
+    shipping_price = $2,
+    total_price = $3,
+    tax_total = $4,
+    discount_total = $5
+WHERE id = $6
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
+`
+
+type UpdateOrderShippingAndTotalParams struct {
+	ShippingOptionID uuid.UUID    `json:"shipping_option_id"`
+	ShippingPrice    money.Amount `json:"shipping_price"`
+	TotalPrice       money.Amount `json:"total_price"`
+	TaxTotal         money.Amount `json:"tax_total"`
+	DiscountTotal    money.Amount `json:"discount_total"`
+	ID               uuid.UUID    `json:"id"`
+}
+
+func (q *Queries) UpdateOrderShippingAndTotal(ctx context.Context, arg UpdateOrderShippingAndTotalParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderShippingAndTotal,
+		arg.ShippingOptionID,
+		arg.ShippingPrice,
+		arg.TotalPrice,
+		arg.TaxTotal,
+		arg.DiscountTotal,
+		arg.ID,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomerEmail,
+		&i.ShippingName,
+		&i.ShippingAddress,
+		&i.ShippingCity,
+		&i.ShippingPostalCode,
+		&i.ShippingPhone,
+		&i.BillingName,
+		&i.BillingAddress,
+		&i.BillingCity,
+		&i.BillingPostalCode,
+		&i.ShippingOptionID,
+		&i.ShippingPrice,
+		&i.PaymentOptionID,
+		&i.ShippingCountryID,
+		&i.BillingCountryID,
+		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
+	)
+	return i, err
+}
+
 const updateOrderStatus = `-- name: UpdateOrderStatus :one
 UPDATE orders
 SET status = $1
 WHERE id = $2
-RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status
+RETURNING id, user_id, status, total_price, created_at, updated_at, customer_email, shipping_name, shipping_address, shipping_city, shipping_postal_code, shipping_phone, billing_name, billing_address, billing_city, billing_postal_code, shipping_option_id, shipping_price, payment_option_id, shipping_country_id, billing_country_id, payment_status, order_number, customer_note, payment_provider, payment_reference, currency, exchange_rate, tax_total, prices_include_tax, vat_id, reverse_charge, coupon_id, coupon_code, discount_total
 `
 
 type UpdateOrderStatusParams struct {
@@ -810,6 +2294,19 @@ func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusPa
 		&i.ShippingCountryID,
 		&i.BillingCountryID,
 		&i.PaymentStatus,
+		&i.OrderNumber,
+		&i.CustomerNote,
+		&i.PaymentProvider,
+		&i.PaymentReference,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.TaxTotal,
+		&i.PricesIncludeTax,
+		&i.VatID,
+		&i.ReverseCharge,
+		&i.CouponID,
+		&i.CouponCode,
+		&i.DiscountTotal,
 	)
 	return i, err
 }
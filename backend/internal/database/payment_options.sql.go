@@ -8,26 +8,32 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const createPaymentOption = `-- name: CreatePaymentOption :one
-INSERT INTO payment_options (name, description, is_active, sort_order)
+INSERT INTO payment_options (name, description, is_active, sort_order, provider, provider_config)
 VALUES(
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
-RETURNING id, name, description, is_active, sort_order, created_at, updated_at
+RETURNING id, name, description, is_active, sort_order, created_at, updated_at, provider, provider_config
 `
 
 type CreatePaymentOptionParams struct {
-	Name        string         `json:"name"`
-	Description sql.NullString `json:"description"`
-	IsActive    bool           `json:"is_active"`
-	SortOrder   sql.NullInt32  `json:"sort_order"`
+	Name           string          `json:"name"`
+	Description    sql.NullString  `json:"description"`
+	IsActive       bool            `json:"is_active"`
+	SortOrder      sql.NullInt32   `json:"sort_order"`
+	Provider       string          `json:"provider"`
+	ProviderConfig json.RawMessage `json:"provider_config"`
 }
 
 func (q *Queries) CreatePaymentOption(ctx context.Context, arg CreatePaymentOptionParams) (PaymentOption, error) {
@@ -36,6 +42,8 @@ func (q *Queries) CreatePaymentOption(ctx context.Context, arg CreatePaymentOpti
 		arg.Description,
 		arg.IsActive,
 		arg.SortOrder,
+		arg.Provider,
+		arg.ProviderConfig,
 	)
 	var i PaymentOption
 	err := row.Scan(
@@ -46,6 +54,8 @@ func (q *Queries) CreatePaymentOption(ctx context.Context, arg CreatePaymentOpti
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Provider,
+		&i.ProviderConfig,
 	)
 	return i, err
 }
@@ -61,26 +71,39 @@ func (q *Queries) DeletePaymentOption(ctx context.Context, id uuid.UUID) error {
 }
 
 const getActivePaymentOptions = `-- name: GetActivePaymentOptions :many
-SELECT id, name, description, is_active, sort_order, created_at, updated_at FROM payment_options
+SELECT id, name, description, is_active, sort_order, provider, created_at, updated_at
+FROM payment_options
 WHERE is_active = true
 ORDER BY sort_order ASC
 `
 
-func (q *Queries) GetActivePaymentOptions(ctx context.Context) ([]PaymentOption, error) {
+type GetActivePaymentOptionsRow struct {
+	ID          uuid.UUID      `json:"id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	IsActive    bool           `json:"is_active"`
+	SortOrder   sql.NullInt32  `json:"sort_order"`
+	Provider    string         `json:"provider"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func (q *Queries) GetActivePaymentOptions(ctx context.Context) ([]GetActivePaymentOptionsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getActivePaymentOptions)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []PaymentOption
+	var items []GetActivePaymentOptionsRow
 	for rows.Next() {
-		var i PaymentOption
+		var i GetActivePaymentOptionsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
 			&i.Description,
 			&i.IsActive,
 			&i.SortOrder,
+			&i.Provider,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -98,7 +121,7 @@ func (q *Queries) GetActivePaymentOptions(ctx context.Context) ([]PaymentOption,
 }
 
 const getPaymentOptionById = `-- name: GetPaymentOptionById :one
-SELECT id, name, description, is_active, sort_order, created_at, updated_at FROM payment_options WHERE id = $1
+SELECT id, name, description, is_active, sort_order, created_at, updated_at, provider, provider_config FROM payment_options WHERE id = $1
 `
 
 func (q *Queries) GetPaymentOptionById(ctx context.Context, id uuid.UUID) (PaymentOption, error) {
@@ -112,12 +135,14 @@ func (q *Queries) GetPaymentOptionById(ctx context.Context, id uuid.UUID) (Payme
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Provider,
+		&i.ProviderConfig,
 	)
 	return i, err
 }
 
 const getPaymentOptions = `-- name: GetPaymentOptions :many
-SELECT id, name, description, is_active, sort_order, created_at, updated_at FROM payment_options
+SELECT id, name, description, is_active, sort_order, created_at, updated_at, provider, provider_config FROM payment_options
 ORDER BY sort_order ASC
 `
 
@@ -138,6 +163,47 @@ func (q *Queries) GetPaymentOptions(ctx context.Context) ([]PaymentOption, error
 			&i.SortOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Provider,
+			&i.ProviderConfig,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentOptionsByProvider = `-- name: GetPaymentOptionsByProvider :many
+SELECT id, name, description, is_active, sort_order, created_at, updated_at, provider, provider_config FROM payment_options
+WHERE provider = $1
+ORDER BY sort_order ASC
+`
+
+func (q *Queries) GetPaymentOptionsByProvider(ctx context.Context, provider string) ([]PaymentOption, error) {
+	rows, err := q.db.QueryContext(ctx, getPaymentOptionsByProvider, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PaymentOption
+	for rows.Next() {
+		var i PaymentOption
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.IsActive,
+			&i.SortOrder,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Provider,
+			&i.ProviderConfig,
 		); err != nil {
 			return nil, err
 		}
@@ -157,16 +223,20 @@ UPDATE payment_options
 SET name = $1,
 description = $2,
 sort_order = $3,
-is_active = $4
-where id = $5
+is_active = $4,
+provider = $5,
+provider_config = $6
+where id = $7
 `
 
 type UpdatePaymentOptionParams struct {
-	Name        string         `json:"name"`
-	Description sql.NullString `json:"description"`
-	SortOrder   sql.NullInt32  `json:"sort_order"`
-	IsActive    bool           `json:"is_active"`
-	ID          uuid.UUID      `json:"id"`
+	Name           string          `json:"name"`
+	Description    sql.NullString  `json:"description"`
+	SortOrder      sql.NullInt32   `json:"sort_order"`
+	IsActive       bool            `json:"is_active"`
+	Provider       string          `json:"provider"`
+	ProviderConfig json.RawMessage `json:"provider_config"`
+	ID             uuid.UUID       `json:"id"`
 }
 
 func (q *Queries) UpdatePaymentOption(ctx context.Context, arg UpdatePaymentOptionParams) error {
@@ -175,6 +245,8 @@ func (q *Queries) UpdatePaymentOption(ctx context.Context, arg UpdatePaymentOpti
 		arg.Description,
 		arg.SortOrder,
 		arg.IsActive,
+		arg.Provider,
+		arg.ProviderConfig,
 		arg.ID,
 	)
 	return err
@@ -9,6 +9,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -37,15 +38,16 @@ func (q *Queries) CountProducts(ctx context.Context) (int64, error) {
 }
 
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (name, slug, description, image_url, category_id)
+INSERT INTO products (name, slug, description, image_url, category_id, tax_class_id)
 VALUES (
   $1,
   $2,
   $3,
   $4,
-  $5
+  $5,
+  $6
 )
-RETURNING id, category_id, name, slug, image_url, description, created_at, updated_at
+RETURNING id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id
 `
 
 type CreateProductParams struct {
@@ -54,6 +56,7 @@ type CreateProductParams struct {
 	Description sql.NullString `json:"description"`
 	ImageUrl    sql.NullString `json:"image_url"`
 	CategoryID  uuid.UUID      `json:"category_id"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
@@ -63,6 +66,7 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		arg.Description,
 		arg.ImageUrl,
 		arg.CategoryID,
+		arg.TaxClassID,
 	)
 	var i Product
 	err := row.Scan(
@@ -74,6 +78,7 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
@@ -95,7 +100,7 @@ type CreateProductVariantParams struct {
 	ProductID     uuid.UUID      `json:"product_id"`
 	Name          sql.NullString `json:"name"`
 	Sku           string         `json:"sku"`
-	Price         float64        `json:"price"`
+	Price         money.Amount   `json:"price"`
 	ImageUrl      sql.NullString `json:"image_url"`
 	StockQuantity int32          `json:"stock_quantity"`
 }
@@ -142,7 +147,7 @@ RETURNING id, product_id, sku, price, stock_quantity, image_url, variant_name, c
 type CreateVariantParams struct {
 	ProductID     uuid.UUID      `json:"product_id"`
 	Sku           string         `json:"sku"`
-	Price         float64        `json:"price"`
+	Price         money.Amount   `json:"price"`
 	StockQuantity int32          `json:"stock_quantity"`
 	ImageUrl      sql.NullString `json:"image_url"`
 	VariantName   sql.NullString `json:"variant_name"`
@@ -218,7 +223,7 @@ func (q *Queries) DeleteVariant(ctx context.Context, id uuid.UUID) (int64, error
 }
 
 const getProductById = `-- name: GetProductById :one
-SELECT id, category_id, name, slug, image_url, description, created_at, updated_at FROM products WHERE id = $1
+SELECT id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id FROM products WHERE id = $1
 `
 
 func (q *Queries) GetProductById(ctx context.Context, id uuid.UUID) (Product, error) {
@@ -233,12 +238,13 @@ func (q *Queries) GetProductById(ctx context.Context, id uuid.UUID) (Product, er
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
 
 const getProductBySlug = `-- name: GetProductBySlug :one
-SELECT id, category_id, name, slug, image_url, description, created_at, updated_at FROM products WHERE slug = $1
+SELECT id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id FROM products WHERE slug = $1
 `
 
 func (q *Queries) GetProductBySlug(ctx context.Context, slug string) (Product, error) {
@@ -253,6 +259,7 @@ func (q *Queries) GetProductBySlug(ctx context.Context, slug string) (Product, e
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
@@ -392,8 +399,24 @@ func (q *Queries) GetVariantsByProductID(ctx context.Context, productID uuid.UUI
 	return items, nil
 }
 
+const increaseVariantStock = `-- name: IncreaseVariantStock :exec
+UPDATE product_variants
+SET stock_quantity = stock_quantity + $1
+WHERE id = $2
+`
+
+type IncreaseVariantStockParams struct {
+	Quantity  int32     `json:"quantity"`
+	VariantID uuid.UUID `json:"variant_id"`
+}
+
+func (q *Queries) IncreaseVariantStock(ctx context.Context, arg IncreaseVariantStockParams) error {
+	_, err := q.db.ExecContext(ctx, increaseVariantStock, arg.Quantity, arg.VariantID)
+	return err
+}
+
 const listProducts = `-- name: ListProducts :many
-SELECT id, category_id, name, slug, image_url, description, created_at, updated_at FROM products ORDER BY name ASC
+SELECT id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id FROM products ORDER BY name ASC
 `
 
 func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
@@ -414,6 +437,7 @@ func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TaxClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -429,7 +453,7 @@ func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
 }
 
 const listProductsByCategory = `-- name: ListProductsByCategory :many
-SELECT id, category_id, name, slug, image_url, description, created_at, updated_at FROM products
+SELECT id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id FROM products
 WHERE category_id = (SELECT id FROM categories WHERE slug = $1)
 ORDER BY name ASC
 `
@@ -452,6 +476,7 @@ func (q *Queries) ListProductsByCategory(ctx context.Context, categorySlug sql.N
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TaxClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -474,7 +499,7 @@ WITH RECURSIVE subcategories AS (
   FROM categories c
   INNER JOIN subcategories s ON c.parent_id = s.id
 )
-SELECT id, category_id, name, slug, image_url, description, created_at, updated_at FROM products
+SELECT id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id FROM products
 WHERE category_id IN (SELECT id FROM subcategories)
 `
 
@@ -496,6 +521,7 @@ func (q *Queries) ListProductsByCategoryRecursive(ctx context.Context, slug sql.
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TaxClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -703,9 +729,10 @@ SET
   description = $3,
   category_id = $4,
     image_url = $5,
+    tax_class_id = $6,
     updated_at = NOW()
-WHERE id = $6
-RETURNING id, category_id, name, slug, image_url, description, created_at, updated_at
+WHERE id = $7
+RETURNING id, category_id, name, slug, image_url, description, created_at, updated_at, tax_class_id
 `
 
 type UpdateProductParams struct {
@@ -714,6 +741,7 @@ type UpdateProductParams struct {
 	Description sql.NullString `json:"description"`
 	CategoryID  uuid.UUID      `json:"category_id"`
 	ImageUrl    sql.NullString `json:"image_url"`
+	TaxClassID  uuid.NullUUID  `json:"tax_class_id"`
 	ID          uuid.UUID      `json:"id"`
 }
 
@@ -724,6 +752,7 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		arg.Description,
 		arg.CategoryID,
 		arg.ImageUrl,
+		arg.TaxClassID,
 		arg.ID,
 	)
 	var i Product
@@ -736,6 +765,7 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.TaxClassID,
 	)
 	return i, err
 }
@@ -755,7 +785,7 @@ RETURNING id, product_id, sku, price, stock_quantity, image_url, variant_name, c
 
 type UpdateVariantParams struct {
 	Sku           string         `json:"sku"`
-	Price         float64        `json:"price"`
+	Price         money.Amount   `json:"price"`
 	StockQuantity int32          `json:"stock_quantity"`
 	ImageUrl      sql.NullString `json:"image_url"`
 	VariantName   sql.NullString `json:"variant_name"`
@@ -9,6 +9,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -21,7 +22,7 @@ RETURNING id, name, description, price, estimated_days, sort_order, is_active, c
 type CreateShippingOptionParams struct {
 	Name          string         `json:"name"`
 	Description   sql.NullString `json:"description"`
-	Price         string         `json:"price"`
+	Price         money.Amount   `json:"price"`
 	EstimatedDays string         `json:"estimated_days"`
 	IsActive      bool           `json:"is_active"`
 }
@@ -199,7 +200,7 @@ RETURNING id, name, description, price, estimated_days, sort_order, is_active, c
 type UpdateShippingOptionParams struct {
 	Name          string         `json:"name"`
 	Description   sql.NullString `json:"description"`
-	Price         string         `json:"price"`
+	Price         money.Amount   `json:"price"`
 	EstimatedDays string         `json:"estimated_days"`
 	SortOrder     int32          `json:"sort_order"`
 	IsActive      bool           `json:"is_active"`
@@ -23,7 +23,8 @@ INSERT INTO users_addresses (
     is_shipping, 
     is_shipping_default,
     is_billing,
-    is_billing_default
+    is_billing_default,
+    vat_id
 )
 VALUES (
     $1, 
@@ -35,9 +36,10 @@ VALUES (
     $7, 
     $8,
     $9,
-    $10
+    $10,
+    $11
 )
-RETURNING id, user_id, name, address, city, postal_code, country_id, phone, is_shipping, is_shipping_default, is_billing, is_billing_default, created_at, updated_at
+RETURNING id, user_id, name, address, city, postal_code, country_id, phone, is_shipping, is_shipping_default, is_billing, is_billing_default, created_at, updated_at, vat_id
 `
 
 type AddUserAddressParams struct {
@@ -51,6 +53,7 @@ type AddUserAddressParams struct {
 	IsShippingDefault bool           `json:"is_shipping_default"`
 	IsBilling         bool           `json:"is_billing"`
 	IsBillingDefault  bool           `json:"is_billing_default"`
+	VatID             sql.NullString `json:"vat_id"`
 }
 
 func (q *Queries) AddUserAddress(ctx context.Context, arg AddUserAddressParams) (UsersAddress, error) {
@@ -65,6 +68,7 @@ func (q *Queries) AddUserAddress(ctx context.Context, arg AddUserAddressParams)
 		arg.IsShippingDefault,
 		arg.IsBilling,
 		arg.IsBillingDefault,
+		arg.VatID,
 	)
 	var i UsersAddress
 	err := row.Scan(
@@ -82,6 +86,7 @@ func (q *Queries) AddUserAddress(ctx context.Context, arg AddUserAddressParams)
 		&i.IsBillingDefault,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.VatID,
 	)
 	return i, err
 }
@@ -97,7 +102,7 @@ func (q *Queries) DeleteUserAddress(ctx context.Context, id uuid.UUID) error {
 }
 
 const getUserAddressById = `-- name: GetUserAddressById :one
-SELECT ua.id, ua.user_id, ua.name, ua.address, ua.city, ua.postal_code, ua.country_id, ua.phone, ua.is_shipping, ua.is_shipping_default, ua.is_billing, ua.is_billing_default, ua.created_at, ua.updated_at, c.name AS country FROM users_addresses ua
+SELECT ua.id, ua.user_id, ua.name, ua.address, ua.city, ua.postal_code, ua.country_id, ua.phone, ua.is_shipping, ua.is_shipping_default, ua.is_billing, ua.is_billing_default, ua.created_at, ua.updated_at, ua.vat_id, c.name AS country FROM users_addresses ua
 JOIN countries c ON ua.country_id = c.id
 WHERE ua.id = $1
 `
@@ -117,6 +122,7 @@ type GetUserAddressByIdRow struct {
 	IsBillingDefault  bool           `json:"is_billing_default"`
 	CreatedAt         sql.NullTime   `json:"created_at"`
 	UpdatedAt         sql.NullTime   `json:"updated_at"`
+	VatID             sql.NullString `json:"vat_id"`
 	Country           string         `json:"country"`
 }
 
@@ -138,13 +144,14 @@ func (q *Queries) GetUserAddressById(ctx context.Context, id uuid.UUID) (GetUser
 		&i.IsBillingDefault,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.VatID,
 		&i.Country,
 	)
 	return i, err
 }
 
 const getUserAddresses = `-- name: GetUserAddresses :many
-SELECT ua.id, ua.user_id, ua.name, ua.address, ua.city, ua.postal_code, ua.country_id, ua.phone, ua.is_shipping, ua.is_shipping_default, ua.is_billing, ua.is_billing_default, ua.created_at, ua.updated_at, c.name AS country FROM users_addresses ua
+SELECT ua.id, ua.user_id, ua.name, ua.address, ua.city, ua.postal_code, ua.country_id, ua.phone, ua.is_shipping, ua.is_shipping_default, ua.is_billing, ua.is_billing_default, ua.created_at, ua.updated_at, ua.vat_id, c.name AS country FROM users_addresses ua
 JOIN countries c ON ua.country_id = c.id
 WHERE ua.user_id = $1
 ORDER BY created_at DESC
@@ -165,6 +172,7 @@ type GetUserAddressesRow struct {
 	IsBillingDefault  bool           `json:"is_billing_default"`
 	CreatedAt         sql.NullTime   `json:"created_at"`
 	UpdatedAt         sql.NullTime   `json:"updated_at"`
+	VatID             sql.NullString `json:"vat_id"`
 	Country           string         `json:"country"`
 }
 
@@ -192,6 +200,7 @@ func (q *Queries) GetUserAddresses(ctx context.Context, userID uuid.UUID) ([]Get
 			&i.IsBillingDefault,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.VatID,
 			&i.Country,
 		); err != nil {
 			return nil, err
@@ -253,9 +262,10 @@ SET address = $1,
     is_shipping = $6,
     is_shipping_default = $7,
     is_billing = $8,
-    is_billing_default = $9
-WHERE id = $10
-RETURNING id, user_id, name, address, city, postal_code, country_id, phone, is_shipping, is_shipping_default, is_billing, is_billing_default, created_at, updated_at
+    is_billing_default = $9,
+    vat_id = $10
+WHERE id = $11
+RETURNING id, user_id, name, address, city, postal_code, country_id, phone, is_shipping, is_shipping_default, is_billing, is_billing_default, created_at, updated_at, vat_id
 `
 
 type UpdateUserAddressParams struct {
@@ -268,6 +278,7 @@ type UpdateUserAddressParams struct {
 	IsShippingDefault bool           `json:"is_shipping_default"`
 	IsBilling         bool           `json:"is_billing"`
 	IsBillingDefault  bool           `json:"is_billing_default"`
+	VatID             sql.NullString `json:"vat_id"`
 	ID                uuid.UUID      `json:"id"`
 }
 
@@ -282,6 +293,7 @@ func (q *Queries) UpdateUserAddress(ctx context.Context, arg UpdateUserAddressPa
 		arg.IsShippingDefault,
 		arg.IsBilling,
 		arg.IsBillingDefault,
+		arg.VatID,
 		arg.ID,
 	)
 	var i UsersAddress
@@ -300,6 +312,7 @@ func (q *Queries) UpdateUserAddress(ctx context.Context, arg UpdateUserAddressPa
 		&i.IsBillingDefault,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.VatID,
 	)
 	return i, err
 }
@@ -0,0 +1,219 @@
+// Package money represents prices exactly, as integer minor units (cents).
+//
+// Amounts are stored in NUMERIC(10, 2) columns and exchanged in JSON as decimal
+// numbers with two places, so float64 never touches a price. Whenever a value has
+// to be rounded to cents, it is rounded half away from zero.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a monetary value in minor units.
+type Amount int64
+
+const Zero Amount = 0
+
+var ErrInvalidAmount = errors.New("invalid money amount")
+
+// FromCents returns the amount for a number of minor units.
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// FromFloat converts a float to the nearest cent. It exists for values that
+// arrive as floats from outside. The float's shortest decimal form is rounded, so
+// 1.005 becomes 1.01 rather than whatever its binary approximation suggests.
+func FromFloat(f float64) Amount {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	a, _ := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	return a
+}
+
+// Parse reads a decimal string such as "12", "12.5" or "-0.99". More than two
+// decimal places are rounded.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	return fromRat(r.Mul(r, big.NewRat(100, 1)))
+}
+
+// MustParse is Parse for constants; it panics on invalid input.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Float64 is for handing amounts to code that only takes floats, such as PDF
+// layout. Do not calculate with the result.
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a < 0
+}
+
+func (a Amount) IsPositive() bool {
+	return a > 0
+}
+
+// Mul multiplies the amount by a whole quantity.
+func (a Amount) Mul(quantity int64) Amount {
+	return a * Amount(quantity)
+}
+
+// MulRat multiplies the amount by num/den, rounding the result to a cent. Use it
+// for percentages and rates: 23% is MulRat(23, 100).
+func (a Amount) MulRat(num, den int64) Amount {
+	r := new(big.Rat).SetFrac(big.NewInt(int64(a)), big.NewInt(1))
+	r.Mul(r, big.NewRat(num, den))
+	result, _ := fromRat(r)
+	return result
+}
+
+// MulDecimal multiplies the amount by a decimal string such as "1.0825", rounding
+// the result to a cent.
+func (a Amount) MulDecimal(factor string) (Amount, error) {
+	f, ok := new(big.Rat).SetString(strings.TrimSpace(factor))
+	if !ok {
+		return 0, fmt.Errorf("%w: factor %q", ErrInvalidAmount, factor)
+	}
+	r := new(big.Rat).SetFrac(big.NewInt(int64(a)), big.NewInt(1))
+	return fromRat(r.Mul(r, f))
+}
+
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Amount) Amount {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// String formats the amount with exactly two decimal places, e.g. "12.30".
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	} else if !json.Valid(data) {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, s)
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Scan reads NUMERIC values, which the driver returns as text. NULL scans as zero.
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	case int64:
+		*a = Amount(v * 100)
+		return nil
+	case float64:
+		*a = FromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("unsupported scan type for money.Amount: %T", src)
+	}
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// fromRat rounds a value in minor units to the nearest whole unit, half away from
+// zero.
+func fromRat(r *big.Rat) (Amount, error) {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	if remainder.Mul(remainder, big.NewInt(2)).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if negative {
+		quotient.Neg(quotient)
+	}
+
+	if !quotient.IsInt64() {
+		return 0, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+	}
+	return Amount(quotient.Int64()), nil
+}
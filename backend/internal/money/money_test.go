@@ -0,0 +1,210 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "12", want: 1200},
+		{in: "12.5", want: 1250},
+		{in: " 3.10 ", want: 310},
+		{in: "-0.99", want: -99},
+		{in: "0.005", want: 1},
+		{in: "-0.005", want: -1},
+		{in: "0.0049", want: 0},
+		{in: "1.015", want: 102},
+		{in: "-1.015", want: -102},
+		{in: "1e2", want: 10000},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Fatalf("Parse(%q) error = %v, want ErrInvalidAmount", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{amount: 0, want: "0.00"},
+		{amount: 1, want: "0.01"},
+		{amount: -5, want: "-0.05"},
+		{amount: 99, want: "0.99"},
+		{amount: 100, want: "1.00"},
+		{amount: -105, want: "-1.05"},
+		{amount: 123456789, want: "1234567.89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.amount.String(); got != tt.want {
+				t.Fatalf("String() = %q, want %q", got, tt.want)
+			}
+			parsed, err := Parse(tt.amount.String())
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.want, err)
+			}
+			if parsed != tt.amount {
+				t.Errorf("Parse(String()) = %d, want %d", parsed, tt.amount)
+			}
+
+			data, err := json.Marshal(tt.amount)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var decoded Amount
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", data, err)
+			}
+			if decoded != tt.amount {
+				t.Errorf("JSON round trip = %d, want %d", decoded, tt.amount)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: `12.3`, want: 1230},
+		{in: `"12.30"`, want: 1230},
+		{in: `-0.5`, want: -50},
+		{in: `0.125`, want: 13},
+		{in: `null`, want: 0},
+		{in: `"twelve"`, wantErr: true},
+		{in: `12.3.4`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Amount
+			err := got.UnmarshalJSON([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("UnmarshalJSON(%s) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Amount
+		quantity int64
+		want     Amount
+	}{
+		{name: "several", amount: 1999, quantity: 3, want: 5997},
+		{name: "one", amount: 1999, quantity: 1, want: 1999},
+		{name: "none", amount: 1999, quantity: 0, want: 0},
+		{name: "negative amount", amount: -250, quantity: 2, want: -500},
+		{name: "negative quantity", amount: 250, quantity: -2, want: -500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Mul(tt.quantity); got != tt.want {
+				t.Errorf("%d.Mul(%d) = %d, want %d", tt.amount, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRat(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Amount
+		num, den int64
+		want     Amount
+	}{
+		{name: "exact", amount: 1000, num: 23, den: 100, want: 230},
+		{name: "half cent rounds up", amount: 1, num: 1, den: 2, want: 1},
+		{name: "negative half cent rounds down", amount: -1, num: 1, den: 2, want: -1},
+		{name: "one and a half", amount: 3, num: 1, den: 2, want: 2},
+		{name: "negative one and a half", amount: -3, num: 1, den: 2, want: -2},
+		{name: "below half", amount: 1, num: 1, den: 3, want: 0},
+		{name: "above half", amount: 2, num: 1, den: 3, want: 1},
+		{name: "thirds", amount: 1000, num: 1, den: 3, want: 333},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.MulRat(tt.num, tt.den); got != tt.want {
+				t.Errorf("%d.MulRat(%d, %d) = %d, want %d", tt.amount, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want Amount
+	}{
+		{in: 1.005, want: 101},
+		{in: -1.005, want: -101},
+		{in: 0.1 + 0.2, want: 30},
+		{in: 19.99, want: 1999},
+	}
+
+	for _, tt := range tests {
+		if got := FromFloat(tt.in); got != tt.want {
+			t.Errorf("FromFloat(%v) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want Amount
+	}{
+		{name: "numeric text", src: []byte("12.34"), want: 1234},
+		{name: "string", src: "-0.50", want: -50},
+		{name: "integer", src: int64(5), want: 500},
+		{name: "null", src: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Amount(42)
+			if err := got.Scan(tt.src); err != nil {
+				t.Fatalf("Scan(%v): %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// PercentScale is the number of decimal places a percentage is stored with.
+const PercentScale = 4
+
+const percentUnit = 10000 // 10^PercentScale
+
+var ErrInvalidPercent = errors.New("invalid percentage")
+
+// Percent is an exact, non-negative percentage such as a tax rate, in
+// ten-thousandths of a percent. The zero value is 0%.
+type Percent int64
+
+// HundredPercent is the whole of an amount.
+const HundredPercent Percent = 100 * percentUnit
+
+// ParsePercent reads a decimal string such as "23" or "8.875". Digits beyond
+// PercentScale are rounded away, matching the NUMERIC column rates are stored in.
+func ParsePercent(s string) (Percent, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPercent, s)
+	}
+
+	units, err := fromRat(r.Mul(r, big.NewRat(percentUnit, 1)))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPercent, s)
+	}
+	return Percent(units), nil
+}
+
+func (p Percent) IsZero() bool {
+	return p == 0
+}
+
+func (p Percent) rat() *big.Rat {
+	return big.NewRat(int64(p), 100*percentUnit)
+}
+
+// String formats the percentage without trailing zeros, e.g. "23" or "8.875".
+func (p Percent) String() string {
+	s := fmt.Sprintf("%d.%04d", int64(p)/percentUnit, int64(p)%percentUnit)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Percent returns p percent of the amount, rounded to a cent.
+func (a Amount) Percent(p Percent) Amount {
+	v := new(big.Rat).SetInt64(int64(a))
+	result, _ := fromRat(v.Mul(v, p.rat()))
+	return result
+}
+
+// PercentIncluded returns the part of a gross amount that was added on top of the
+// net amount at p percent: the tax contained in a tax-inclusive price.
+func (a Amount) PercentIncluded(p Percent) Amount {
+	v := new(big.Rat).SetInt64(int64(a))
+	r := p.rat()
+	v.Mul(v, r)
+	result, _ := fromRat(v.Quo(v, r.Add(r, big.NewRat(1, 1))))
+	return result
+}
+
+func (p Percent) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (p *Percent) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParsePercent(s)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// Scan reads NUMERIC values, which the driver returns as text.
+func (p *Percent) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return p.scanString(string(v))
+	case string:
+		return p.scanString(v)
+	case int64:
+		*p = Percent(v * percentUnit)
+		return nil
+	default:
+		return fmt.Errorf("unsupported scan type for money.Percent: %T", src)
+	}
+}
+
+func (p *Percent) scanString(s string) error {
+	parsed, err := ParsePercent(s)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+func (p Percent) Value() (driver.Value, error) {
+	return p.String(), nil
+}
@@ -0,0 +1,128 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Percent
+		str     string
+		wantErr bool
+	}{
+		{in: "23", want: 230000, str: "23"},
+		{in: "8.875", want: 88750, str: "8.875"},
+		{in: "0", want: 0, str: "0"},
+		{in: "100", want: HundredPercent, str: "100"},
+		{in: "0.00005", want: 1, str: "0.0001"},
+		{in: "0.00004", want: 0, str: "0"},
+		{in: "-1", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePercent(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPercent) {
+					t.Fatalf("ParsePercent(%q) error = %v, want ErrInvalidPercent", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePercent(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePercent(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+			if got.String() != tt.str {
+				t.Errorf("String() = %q, want %q", got.String(), tt.str)
+			}
+
+			again, err := ParsePercent(got.String())
+			if err != nil || again != got {
+				t.Errorf("ParsePercent(String()) = %d, %v, want %d", again, err, got)
+			}
+		})
+	}
+}
+
+func TestAmountPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Amount
+		percent string
+		want    Amount
+	}{
+		{name: "exact", amount: 1000, percent: "23", want: 230},
+		{name: "half cent rounds up", amount: 5, percent: "10", want: 1},
+		{name: "negative half cent rounds down", amount: -5, percent: "10", want: -1},
+		{name: "one and a half cents", amount: 15, percent: "10", want: 2},
+		{name: "fractional rate", amount: 1999, percent: "8.875", want: 177},
+		{name: "whole", amount: 1999, percent: "100", want: 1999},
+		{name: "none", amount: 1999, percent: "0", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePercent(tt.percent)
+			if err != nil {
+				t.Fatalf("ParsePercent(%q): %v", tt.percent, err)
+			}
+			if got := tt.amount.Percent(p); got != tt.want {
+				t.Errorf("%d.Percent(%s) = %d, want %d", tt.amount, p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountPercentIncluded(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Amount
+		percent string
+		want    Amount
+	}{
+		{name: "exact", amount: 12300, percent: "23", want: 2300},
+		{name: "rounds up", amount: 100, percent: "23", want: 19},
+		{name: "rounds down", amount: 1000, percent: "8", want: 74},
+		{name: "half cent", amount: 21, percent: "5", want: 1},
+		{name: "negative", amount: -12300, percent: "23", want: -2300},
+		{name: "zero rate", amount: 1000, percent: "0", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePercent(tt.percent)
+			if err != nil {
+				t.Fatalf("ParsePercent(%q): %v", tt.percent, err)
+			}
+			if got := tt.amount.PercentIncluded(p); got != tt.want {
+				t.Errorf("%d.PercentIncluded(%s) = %d, want %d", tt.amount, p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentJSON(t *testing.T) {
+	for _, in := range []string{`8.875`, `"8.875"`} {
+		var p Percent
+		if err := json.Unmarshal([]byte(in), &p); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", in, err)
+		}
+		if p != 88750 {
+			t.Errorf("Unmarshal(%s) = %d, want 88750", in, p)
+		}
+
+		data, err := json.Marshal(p)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if string(data) != "8.875" {
+			t.Errorf("Marshal = %s, want 8.875", data)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateScale is the number of decimal places an exchange rate is stored with.
+const RateScale = 8
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// Rate is an exact, positive exchange rate: how many units of one currency buy
+// one unit of another. The zero value is a rate of 1.
+type Rate struct {
+	r *big.Rat
+}
+
+// IdentityRate converts an amount to itself.
+var IdentityRate = Rate{}
+
+// ParseRate reads a positive decimal string such as "4.3125". Digits beyond
+// RateScale are rounded away, matching the NUMERIC column rates are stored in.
+func ParseRate(s string) (Rate, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+
+	r, ok = new(big.Rat).SetString(r.FloatString(RateScale))
+	if !ok || r.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	return Rate{r: r}, nil
+}
+
+func (r Rate) rat() *big.Rat {
+	if r.r == nil {
+		return big.NewRat(1, 1)
+	}
+	return r.r
+}
+
+func (r Rate) IsIdentity() bool {
+	return r.rat().Cmp(big.NewRat(1, 1)) == 0
+}
+
+// String formats the rate without trailing zeros, e.g. "1.0825" or "1".
+func (r Rate) String() string {
+	s := r.rat().FloatString(RateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Convert multiplies the amount by the rate, rounding the result to a cent.
+func (a Amount) Convert(r Rate) Amount {
+	v := new(big.Rat).SetInt64(int64(a))
+	result, _ := fromRat(v.Mul(v, r.rat()))
+	return result
+}
+
+// ConvertBack divides the amount by the rate, undoing Convert up to rounding.
+func (a Amount) ConvertBack(r Rate) Amount {
+	v := new(big.Rat).SetInt64(int64(a))
+	result, _ := fromRat(v.Quo(v, r.rat()))
+	return result
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Scan reads NUMERIC values, which the driver returns as text.
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return r.scanString(string(v))
+	case string:
+		return r.scanString(v)
+	case int64:
+		*r = Rate{r: new(big.Rat).SetInt64(v)}
+		return nil
+	default:
+		return fmt.Errorf("unsupported scan type for money.Rate: %T", src)
+	}
+}
+
+func (r *Rate) scanString(s string) error {
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
@@ -0,0 +1,125 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "4.3125", want: "4.3125"},
+		{in: "1", want: "1"},
+		{in: "1.00000000", want: "1"},
+		{in: " 0.25 ", want: "0.25"},
+		{in: "1.000000005", want: "1.00000001"},
+		{in: "1.000000004", want: "1"},
+		{in: "0", wantErr: true},
+		{in: "0.000000004", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRate) {
+					t.Fatalf("ParseRate(%q) error = %v, want ErrInvalidRate", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRate(%q): %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseRate(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+
+			again, err := ParseRate(got.String())
+			if err != nil || again.String() != got.String() {
+				t.Errorf("ParseRate(String()) = %s, %v, want %s", again, err, got)
+			}
+		})
+	}
+}
+
+func TestRateIdentity(t *testing.T) {
+	if !IdentityRate.IsIdentity() {
+		t.Errorf("IdentityRate.IsIdentity() = false")
+	}
+	if got := IdentityRate.String(); got != "1" {
+		t.Errorf("IdentityRate.String() = %q, want 1", got)
+	}
+	if got := Amount(1234).Convert(IdentityRate); got != 1234 {
+		t.Errorf("Convert(IdentityRate) = %d, want 1234", got)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Amount
+		rate   string
+		want   Amount
+		back   Amount
+	}{
+		{name: "rounds half up", amount: 1000, rate: "4.3125", want: 4313, back: 1000},
+		{name: "negative rounds half down", amount: -1000, rate: "4.3125", want: -4313, back: -1000},
+		{name: "half cent", amount: 1, rate: "0.5", want: 1, back: 2},
+		{name: "negative half cent", amount: -1, rate: "0.5", want: -1, back: -2},
+		{name: "below half", amount: 1999, rate: "0.23", want: 460, back: 2000},
+		{name: "zero", amount: 0, rate: "1.0825", want: 0, back: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			if err != nil {
+				t.Fatalf("ParseRate(%q): %v", tt.rate, err)
+			}
+
+			got := tt.amount.Convert(rate)
+			if got != tt.want {
+				t.Errorf("%d.Convert(%s) = %d, want %d", tt.amount, rate, got, tt.want)
+			}
+			if back := got.ConvertBack(rate); back != tt.back {
+				t.Errorf("%d.ConvertBack(%s) = %d, want %d", got, rate, back, tt.back)
+			}
+		})
+	}
+}
+
+func TestRateJSON(t *testing.T) {
+	rate, err := ParseRate("1.0825")
+	if err != nil {
+		t.Fatalf("ParseRate: %v", err)
+	}
+
+	data, err := json.Marshal(rate)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != "1.0825" {
+		t.Errorf("Marshal = %s, want 1.0825", data)
+	}
+
+	for _, in := range []string{`1.0825`, `"1.0825"`} {
+		var decoded Rate
+		if err := json.Unmarshal([]byte(in), &decoded); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", in, err)
+		}
+		if decoded.String() != rate.String() {
+			t.Errorf("Unmarshal(%s) = %s, want %s", in, decoded, rate)
+		}
+	}
+
+	var invalid Rate
+	if err := json.Unmarshal([]byte(`0`), &invalid); !errors.Is(err, ErrInvalidRate) {
+		t.Errorf("Unmarshal(0) error = %v, want ErrInvalidRate", err)
+	}
+}
@@ -0,0 +1,141 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+const FakeKey = "fake"
+
+type FakeConfig struct {
+	// Outcome is "succeed" (default), "decline", "redirect" or "capture".
+	Outcome     string `json:"outcome"`
+	RedirectURL string `json:"redirect_url"`
+	// Status is what Status reports for any reference; defaults to authorized.
+	Status Status `json:"status"`
+	// WebhookSecret signs webhooks as hex HMAC-SHA256 of the body in X-Fake-Signature.
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+type fakeWebhookBody struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`
+	Reference string       `json:"reference"`
+	Status    Status       `json:"status"`
+	Amount    money.Amount `json:"amount"`
+}
+
+// Fake is a deterministic provider for development and tests. References are
+// derived from the order ID and no network calls are made. It is not part of
+// DefaultRegistry, since any payment it reports is made up.
+type Fake struct {
+	config FakeConfig
+}
+
+func NewFake(config json.RawMessage) (Provider, error) {
+	f := &Fake{}
+	if err := decodeConfig(config, &f.config); err != nil {
+		return nil, err
+	}
+
+	switch f.config.Outcome {
+	case "":
+		f.config.Outcome = "succeed"
+	case "succeed", "decline", "capture":
+	case "redirect":
+		if f.config.RedirectURL == "" {
+			return nil, fmt.Errorf("invalid provider config: redirect_url is required for the redirect outcome")
+		}
+	default:
+		return nil, fmt.Errorf("invalid provider config: unknown outcome %q", f.config.Outcome)
+	}
+
+	if f.config.Status == "" {
+		f.config.Status = StatusAuthorized
+	}
+
+	return f, nil
+}
+
+func (f *Fake) Key() string {
+	return FakeKey
+}
+
+func (f *Fake) Authorize(ctx context.Context, order Order) (Result, error) {
+	reference := "fake_" + order.ID.String()
+
+	switch f.config.Outcome {
+	case "decline":
+		return Result{Reference: reference, Status: StatusFailed, Amount: order.Amount}, ErrDeclined
+	case "redirect":
+		url := strings.ReplaceAll(f.config.RedirectURL, "{reference}", reference)
+		return Result{
+			Reference:  reference,
+			Status:     StatusPending,
+			Amount:     order.Amount,
+			NextAction: NextAction{Type: ActionRedirect, RedirectURL: url},
+		}, nil
+	case "capture":
+		return Result{Reference: reference, Status: StatusCaptured, Amount: order.Amount, NextAction: NextAction{Type: ActionNone}}, nil
+	default:
+		return Result{
+			Reference:  reference,
+			Status:     StatusAuthorized,
+			Amount:     order.Amount,
+			NextAction: NextAction{Type: ActionClientSecret, ClientSecret: reference + "_secret"},
+		}, nil
+	}
+}
+
+func (f *Fake) Capture(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error) {
+	return Result{Reference: reference, Status: StatusCaptured, Amount: amount}, nil
+}
+
+func (f *Fake) Void(ctx context.Context, reference string, idempotencyKey string) (Result, error) {
+	return Result{Reference: reference, Status: StatusVoided}, nil
+}
+
+func (f *Fake) Refund(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error) {
+	return Result{Reference: reference, Status: StatusRefunded, Amount: amount}, nil
+}
+
+func (f *Fake) Status(ctx context.Context, reference string) (Result, error) {
+	return Result{Reference: reference, Status: f.config.Status}, nil
+}
+
+func (f *Fake) ParseWebhook(header http.Header, body []byte) (WebhookEvent, error) {
+	if f.config.WebhookSecret == "" {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(f.config.WebhookSecret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Fake-Signature"))) {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	var event fakeWebhookBody
+	if err := json.Unmarshal(body, &event); err != nil {
+		return WebhookEvent{}, fmt.Errorf("invalid webhook body: %w", err)
+	}
+	if event.ID == "" {
+		return WebhookEvent{}, fmt.Errorf("invalid webhook body: missing id")
+	}
+
+	return WebhookEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		Reference: event.Reference,
+		Status:    event.Status,
+		Amount:    event.Amount,
+	}, nil
+}
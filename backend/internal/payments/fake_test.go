@@ -0,0 +1,128 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+func TestDefaultRegistryExcludesFake(t *testing.T) {
+	r := DefaultRegistry()
+
+	if _, err := r.New(FakeKey, nil); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("New(%q) error = %v, want ErrUnknownProvider", FakeKey, err)
+	}
+
+	r.Register(FakeKey, NewFake)
+	if _, err := r.New(FakeKey, nil); err != nil {
+		t.Fatalf("New(%q) after Register error = %v", FakeKey, err)
+	}
+}
+
+func TestNewFakeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{name: "empty", config: `{}`},
+		{name: "decline", config: `{"outcome":"decline"}`},
+		{name: "redirect", config: `{"outcome":"redirect","redirect_url":"https://example.com/{reference}"}`},
+		{name: "redirect without url", config: `{"outcome":"redirect"}`, wantErr: true},
+		{name: "unknown outcome", config: `{"outcome":"explode"}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFake(json.RawMessage(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFake(%s) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFakeAuthorize(t *testing.T) {
+	order := Order{ID: uuid.MustParse("6f1c2a8e-4d3b-4c1e-9a57-0b8e2f6d9c11"), Amount: money.FromCents(1999)}
+	reference := "fake_" + order.ID.String()
+
+	tests := []struct {
+		name       string
+		config     string
+		wantStatus Status
+		wantAction ActionType
+		wantErr    error
+	}{
+		{name: "succeed", config: `{}`, wantStatus: StatusAuthorized, wantAction: ActionClientSecret},
+		{name: "capture", config: `{"outcome":"capture"}`, wantStatus: StatusCaptured, wantAction: ActionNone},
+		{name: "redirect", config: `{"outcome":"redirect","redirect_url":"https://example.com/{reference}"}`, wantStatus: StatusPending, wantAction: ActionRedirect},
+		{name: "decline", config: `{"outcome":"decline"}`, wantStatus: StatusFailed, wantErr: ErrDeclined},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewFake(json.RawMessage(tt.config))
+			if err != nil {
+				t.Fatalf("NewFake: %v", err)
+			}
+
+			result, err := provider.Authorize(context.Background(), order)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Reference != reference {
+				t.Errorf("Reference = %q, want %q", result.Reference, reference)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.Amount != order.Amount {
+				t.Errorf("Amount = %s, want %s", result.Amount, order.Amount)
+			}
+			if tt.wantErr == nil && result.NextAction.Type != tt.wantAction {
+				t.Errorf("NextAction.Type = %q, want %q", result.NextAction.Type, tt.wantAction)
+			}
+			if tt.wantAction == ActionRedirect && result.NextAction.RedirectURL != "https://example.com/"+reference {
+				t.Errorf("RedirectURL = %q", result.NextAction.RedirectURL)
+			}
+		})
+	}
+}
+
+func TestFakeParseWebhook(t *testing.T) {
+	provider, err := NewFake(json.RawMessage(`{"webhook_secret":"whsec_test"}`))
+	if err != nil {
+		t.Fatalf("NewFake: %v", err)
+	}
+	parser := provider.(WebhookParser)
+
+	body := []byte(`{"id":"evt_1","type":"payment.captured","reference":"fake_1","status":"captured","amount":"19.99"}`)
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	header := http.Header{}
+	header.Set("X-Fake-Signature", signature)
+	event, err := parser.ParseWebhook(header, body)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	want := WebhookEvent{ID: "evt_1", Type: "payment.captured", Reference: "fake_1", Status: StatusCaptured, Amount: money.FromCents(1999)}
+	if event != want {
+		t.Errorf("event = %+v, want %+v", event, want)
+	}
+
+	header.Set("X-Fake-Signature", "00"+signature[2:])
+	if _, err := parser.ParseWebhook(header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("ParseWebhook with bad signature error = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,94 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+const ManualKey = "manual"
+
+type ManualConfig struct {
+	// Instructions may use {order_number}, {amount} and {currency}.
+	Instructions  string `json:"instructions"`
+	AccountHolder string `json:"account_holder"`
+	AccountNumber string `json:"account_number"`
+	BankName      string `json:"bank_name"`
+	SwiftCode     string `json:"swift_code"`
+}
+
+// Manual covers bank transfers and other offline payments. Nothing is sent to a
+// processor; the customer gets instructions and an admin confirms the payment.
+type Manual struct {
+	config ManualConfig
+}
+
+func NewManual(config json.RawMessage) (Provider, error) {
+	m := &Manual{}
+	if err := decodeConfig(config, &m.config); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manual) Key() string {
+	return ManualKey
+}
+
+func (m *Manual) Authorize(ctx context.Context, order Order) (Result, error) {
+	amount := order.Amount.String()
+	instructions := m.config.Instructions
+	if instructions == "" {
+		instructions = "Please transfer {amount} {currency} and use {order_number} as the payment reference."
+	}
+	instructions = strings.NewReplacer(
+		"{order_number}", order.Number,
+		"{amount}", amount,
+		"{currency}", order.Currency,
+	).Replace(instructions)
+
+	var lines []string
+	for _, line := range []struct{ label, value string }{
+		{"Account holder", m.config.AccountHolder},
+		{"Account number", m.config.AccountNumber},
+		{"Bank", m.config.BankName},
+		{"SWIFT", m.config.SwiftCode},
+	} {
+		if line.value != "" {
+			lines = append(lines, line.label+": "+line.value)
+		}
+	}
+	if len(lines) > 0 {
+		instructions += "\n" + strings.Join(lines, "\n")
+	}
+
+	return Result{
+		Reference: order.Number,
+		Status:    StatusPending,
+		Amount:    order.Amount,
+		NextAction: NextAction{
+			Type:         ActionInstructions,
+			Instructions: instructions,
+		},
+	}, nil
+}
+
+func (m *Manual) Capture(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error) {
+	return Result{Reference: reference, Status: StatusCaptured, Amount: amount}, nil
+}
+
+func (m *Manual) Void(ctx context.Context, reference string, idempotencyKey string) (Result, error) {
+	return Result{Reference: reference, Status: StatusVoided}, nil
+}
+
+// Refund only records the intent; the money is sent back outside the shop.
+func (m *Manual) Refund(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error) {
+	return Result{Reference: reference, Status: StatusRefunded, Amount: amount}, nil
+}
+
+// Status is unknown to the shop for offline payments.
+func (m *Manual) Status(ctx context.Context, reference string) (Result, error) {
+	return Result{}, ErrNotSupported
+}
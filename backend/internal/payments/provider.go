@@ -0,0 +1,144 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	ErrNotSupported    = errors.New("operation not supported by payment provider")
+	ErrDeclined        = errors.New("payment declined")
+	ErrRejected        = errors.New("payment request rejected")
+)
+
+// IsFinal reports whether a provider error is a definite answer. Declined or
+// rejected requests will not succeed when retried; anything else, such as a
+// timeout, may have gone through and is retried with the same idempotency key.
+func IsFinal(err error) bool {
+	return errors.Is(err, ErrDeclined) || errors.Is(err, ErrRejected) || errors.Is(err, ErrNotSupported)
+}
+
+// Status is the provider-side state of a payment.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	StatusVoided     Status = "voided"
+	StatusRefunded   Status = "refunded"
+	StatusFailed     Status = "failed"
+)
+
+// ActionType tells the client what it has to do to complete a payment.
+type ActionType string
+
+const (
+	ActionNone         ActionType = "none"
+	ActionRedirect     ActionType = "redirect"
+	ActionClientSecret ActionType = "client_secret"
+	ActionInstructions ActionType = "instructions"
+)
+
+type NextAction struct {
+	Type           ActionType `json:"type"`
+	RedirectURL    string     `json:"redirect_url,omitempty"`
+	ClientSecret   string     `json:"client_secret,omitempty"`
+	PublishableKey string     `json:"publishable_key,omitempty"`
+	Instructions   string     `json:"instructions,omitempty"`
+}
+
+// Order is the part of an order a provider needs to start a payment.
+type Order struct {
+	ID            uuid.UUID
+	Number        string
+	Amount        money.Amount
+	Currency      string
+	CustomerEmail string
+	ReturnURL     string
+}
+
+// Result describes a payment after a provider call. Reference is the provider's
+// own identifier and is what later calls and webhooks refer to. Providers that
+// give refunds their own identifier return it as TransactionReference, so the
+// refund can be recognised when a webhook reports it back. Raw is the
+// provider's response body, if any, kept for the payment ledger.
+type Result struct {
+	Reference            string
+	TransactionReference string
+	Status               Status
+	Amount               money.Amount
+	NextAction           NextAction
+	Raw                  json.RawMessage
+}
+
+// Provider is a payment gateway. Capture, Void and Refund take an idempotency
+// key, which the caller keeps stable across retries of the same operation so a
+// provider that supports keys carries it out at most once.
+type Provider interface {
+	Key() string
+	Authorize(ctx context.Context, order Order) (Result, error)
+	Capture(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error)
+	Void(ctx context.Context, reference string, idempotencyKey string) (Result, error)
+	Refund(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error)
+	Status(ctx context.Context, reference string) (Result, error)
+}
+
+// Factory builds a provider from a payment option's JSON config. It should reject
+// configs that are missing required settings.
+type Factory func(config json.RawMessage) (Provider, error)
+
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: map[string]Factory{}}
+}
+
+// DefaultRegistry returns a registry with the built-in providers that take real
+// payments. The fake provider has to be registered explicitly.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(ManualKey, NewManual)
+	r.Register(StripeKey, NewStripe)
+	return r
+}
+
+func (r *Registry) Register(key string, factory Factory) {
+	r.factories[key] = factory
+}
+
+func (r *Registry) New(key string, config json.RawMessage) (Provider, error) {
+	factory, ok := r.factories[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, key)
+	}
+	if len(config) == 0 {
+		config = json.RawMessage("{}")
+	}
+	return factory(config)
+}
+
+func (r *Registry) Keys() []string {
+	keys := make([]string, 0, len(r.factories))
+	for key := range r.factories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func decodeConfig(config json.RawMessage, v any) error {
+	if err := json.Unmarshal(config, v); err != nil {
+		return fmt.Errorf("invalid provider config: %w", err)
+	}
+	return nil
+}
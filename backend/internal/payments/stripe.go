@@ -0,0 +1,442 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+const StripeKey = "stripe"
+
+const (
+	stripeDefaultBaseURL   = "https://api.stripe.com"
+	stripeRequestTimeout   = 30 * time.Second
+	stripeWebhookTolerance = 5 * time.Minute
+)
+
+// Currencies Stripe expects in whole units rather than cents.
+var stripeZeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true, "krw": true, "mga": true,
+	"pyg": true, "rwf": true, "ugx": true, "vnd": true, "vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+type StripeConfig struct {
+	SecretKey      string `json:"secret_key"`
+	PublishableKey string `json:"publishable_key"`
+	WebhookSecret  string `json:"webhook_secret"`
+	// CaptureMethod is "automatic" (default) or "manual" to authorize at checkout
+	// and capture later.
+	CaptureMethod string `json:"capture_method"`
+	// BaseURL overrides the API host, e.g. to point at a local stub server.
+	BaseURL string `json:"base_url"`
+}
+
+// Stripe takes card payments through the PaymentIntents API. The intent is created
+// at checkout and confirmed by the client with its client secret; the outcome
+// arrives by webhook.
+type Stripe struct {
+	config StripeConfig
+	client *http.Client
+}
+
+type stripePaymentIntent struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	Amount         int64  `json:"amount"`
+	AmountReceived int64  `json:"amount_received"`
+	Currency       string `json:"currency"`
+	ClientSecret   string `json:"client_secret"`
+	NextAction     *struct {
+		RedirectToURL *struct {
+			URL string `json:"url"`
+		} `json:"redirect_to_url"`
+	} `json:"next_action"`
+}
+
+type stripeRefund struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	PaymentIntent string `json:"payment_intent"`
+}
+
+type stripeError struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+func NewStripe(config json.RawMessage) (Provider, error) {
+	s := &Stripe{client: &http.Client{Timeout: stripeRequestTimeout}}
+	if err := decodeConfig(config, &s.config); err != nil {
+		return nil, err
+	}
+
+	if s.config.SecretKey == "" {
+		return nil, fmt.Errorf("invalid provider config: secret_key is required")
+	}
+
+	switch s.config.CaptureMethod {
+	case "":
+		s.config.CaptureMethod = "automatic"
+	case "automatic", "manual":
+	default:
+		return nil, fmt.Errorf("invalid provider config: unknown capture_method %q", s.config.CaptureMethod)
+	}
+
+	if s.config.BaseURL == "" {
+		s.config.BaseURL = stripeDefaultBaseURL
+	}
+	s.config.BaseURL = strings.TrimRight(s.config.BaseURL, "/")
+
+	return s, nil
+}
+
+func (s *Stripe) Key() string {
+	return StripeKey
+}
+
+func (s *Stripe) Authorize(ctx context.Context, order Order) (Result, error) {
+	currency := strings.ToLower(order.Currency)
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(toStripeAmount(order.Amount, currency), 10))
+	form.Set("currency", currency)
+	form.Set("capture_method", s.config.CaptureMethod)
+	form.Set("automatic_payment_methods[enabled]", "true")
+	form.Set("description", "Order "+order.Number)
+	form.Set("metadata[order_id]", order.ID.String())
+	form.Set("metadata[order_number]", order.Number)
+	if order.CustomerEmail != "" {
+		form.Set("receipt_email", order.CustomerEmail)
+	}
+
+	var intent stripePaymentIntent
+	raw, err := s.do(ctx, http.MethodPost, "/v1/payment_intents", form, "order-"+order.ID.String(), &intent)
+	if err != nil {
+		return Result{Status: StatusFailed, Amount: order.Amount, Raw: raw}, err
+	}
+
+	return s.intentResult(intent, raw), nil
+}
+
+func (s *Stripe) Capture(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error) {
+	intent, raw, err := s.getIntent(ctx, reference)
+	if err != nil {
+		return Result{Reference: reference, Raw: raw}, err
+	}
+
+	form := url.Values{}
+	if amount.IsPositive() {
+		form.Set("amount_to_capture", strconv.FormatInt(toStripeAmount(amount, intent.Currency), 10))
+	}
+
+	raw, err = s.do(ctx, http.MethodPost, "/v1/payment_intents/"+url.PathEscape(reference)+"/capture", form, idempotencyKey, &intent)
+	if err != nil {
+		return Result{Reference: reference, Status: StatusFailed, Amount: amount, Raw: raw}, err
+	}
+
+	return s.intentResult(intent, raw), nil
+}
+
+func (s *Stripe) Void(ctx context.Context, reference string, idempotencyKey string) (Result, error) {
+	var intent stripePaymentIntent
+	raw, err := s.do(ctx, http.MethodPost, "/v1/payment_intents/"+url.PathEscape(reference)+"/cancel", url.Values{}, idempotencyKey, &intent)
+	if err != nil {
+		return Result{Reference: reference, Raw: raw}, err
+	}
+
+	return s.intentResult(intent, raw), nil
+}
+
+// Refund refunds part or all of a captured intent. Stripe settles most refunds
+// asynchronously, so a pending refund is reported as refunded.
+func (s *Stripe) Refund(ctx context.Context, reference string, amount money.Amount, idempotencyKey string) (Result, error) {
+	intent, raw, err := s.getIntent(ctx, reference)
+	if err != nil {
+		return Result{Reference: reference, Raw: raw}, err
+	}
+
+	form := url.Values{}
+	form.Set("payment_intent", reference)
+	form.Set("amount", strconv.FormatInt(toStripeAmount(amount, intent.Currency), 10))
+
+	var refund stripeRefund
+	raw, err = s.do(ctx, http.MethodPost, "/v1/refunds", form, idempotencyKey, &refund)
+	if err != nil {
+		return Result{Reference: reference, Status: StatusFailed, Amount: amount, Raw: raw}, err
+	}
+
+	switch refund.Status {
+	case "succeeded", "pending", "requires_action":
+		return Result{
+			Reference:            reference,
+			TransactionReference: refund.ID,
+			Status:               StatusRefunded,
+			Amount:               fromStripeAmount(refund.Amount, refund.Currency),
+			Raw:                  raw,
+		}, nil
+	default:
+		return Result{Reference: reference, Status: StatusFailed, Amount: amount, Raw: raw}, fmt.Errorf("%w: stripe refund %s is %s", ErrRejected, refund.ID, refund.Status)
+	}
+}
+
+func (s *Stripe) Status(ctx context.Context, reference string) (Result, error) {
+	intent, raw, err := s.getIntent(ctx, reference)
+	if err != nil {
+		return Result{Reference: reference, Raw: raw}, err
+	}
+
+	return s.intentResult(intent, raw), nil
+}
+
+// ParseWebhook verifies the Stripe-Signature header ("t=<unix>,v1=<hex>") against
+// the signing secret and maps payment intent and refund events.
+func (s *Stripe) ParseWebhook(header http.Header, body []byte) (WebhookEvent, error) {
+	if s.config.WebhookSecret == "" {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get("Stripe-Signature"), ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(signedAt, 0)); age > stripeWebhookTolerance || age < -stripeWebhookTolerance {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.config.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return WebhookEvent{}, fmt.Errorf("invalid webhook body: %w", err)
+	}
+	if event.ID == "" {
+		return WebhookEvent{}, fmt.Errorf("invalid webhook body: missing id")
+	}
+
+	result := WebhookEvent{ID: event.ID, Type: event.Type}
+
+	if strings.HasPrefix(event.Type, "payment_intent.") {
+		var intent stripePaymentIntent
+		if err := json.Unmarshal(event.Data.Object, &intent); err != nil {
+			return WebhookEvent{}, fmt.Errorf("invalid webhook body: %w", err)
+		}
+		result.Reference = intent.ID
+		result.Amount = fromStripeAmount(intent.Amount, intent.Currency)
+
+		switch event.Type {
+		case "payment_intent.succeeded":
+			result.Status = StatusCaptured
+			result.Amount = fromStripeAmount(intent.AmountReceived, intent.Currency)
+		case "payment_intent.amount_capturable_updated":
+			result.Status = StatusAuthorized
+		case "payment_intent.payment_failed":
+			result.Status = StatusFailed
+		case "payment_intent.canceled":
+			result.Status = StatusVoided
+		default:
+			result.Status = intentStatus(intent.Status)
+		}
+		return result, nil
+	}
+
+	// Refund events carry the refund itself, so each refund is reported under its
+	// own ID however many events mention it. charge.refunded carries the charge
+	// and is left unmapped, as the refund events already cover it.
+	switch event.Type {
+	case "refund.created", "refund.updated", "charge.refund.updated":
+		var refund stripeRefund
+		if err := json.Unmarshal(event.Data.Object, &refund); err != nil {
+			return WebhookEvent{}, fmt.Errorf("invalid webhook body: %w", err)
+		}
+		result.Reference = refund.PaymentIntent
+		result.TransactionReference = refund.ID
+		result.Amount = fromStripeAmount(refund.Amount, refund.Currency)
+		switch refund.Status {
+		case "succeeded", "pending", "requires_action":
+			result.Status = StatusRefunded
+		case "failed", "canceled":
+			result.Status = StatusFailed
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Stripe) getIntent(ctx context.Context, reference string) (stripePaymentIntent, json.RawMessage, error) {
+	var intent stripePaymentIntent
+	raw, err := s.do(ctx, http.MethodGet, "/v1/payment_intents/"+url.PathEscape(reference), nil, "", &intent)
+	return intent, raw, err
+}
+
+func (s *Stripe) intentResult(intent stripePaymentIntent, raw json.RawMessage) Result {
+	result := Result{
+		Reference:  intent.ID,
+		Status:     intentStatus(intent.Status),
+		Amount:     fromStripeAmount(intent.Amount, intent.Currency),
+		NextAction: NextAction{Type: ActionNone},
+		Raw:        raw,
+	}
+
+	if result.Status == StatusPending {
+		if intent.NextAction != nil && intent.NextAction.RedirectToURL != nil {
+			result.NextAction = NextAction{Type: ActionRedirect, RedirectURL: intent.NextAction.RedirectToURL.URL}
+		} else if intent.ClientSecret != "" {
+			result.NextAction = NextAction{
+				Type:           ActionClientSecret,
+				ClientSecret:   intent.ClientSecret,
+				PublishableKey: s.config.PublishableKey,
+			}
+		}
+	}
+
+	return result
+}
+
+// do sends a form-encoded request and decodes a successful JSON response into v.
+// The raw body is returned either way so it can be kept on the ledger. Card
+// errors are reported as ErrDeclined and other client errors as ErrRejected;
+// conflicts, rate limits and server errors are left for a retry.
+func (s *Stripe) do(ctx context.Context, method, path string, form url.Values, idempotencyKey string, v any) (json.RawMessage, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.config.BaseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build stripe request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.SecretKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stripe response: %w", err)
+	}
+
+	var raw json.RawMessage
+	if json.Valid(data) {
+		raw = data
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr stripeError
+		if err := json.Unmarshal(data, &apiErr); err != nil || apiErr.Error.Message == "" {
+			if stripeRejected(resp.StatusCode) {
+				return raw, fmt.Errorf("%w: stripe returned status %d", ErrRejected, resp.StatusCode)
+			}
+			return raw, fmt.Errorf("stripe returned status %d", resp.StatusCode)
+		}
+		if apiErr.Error.Type == "card_error" {
+			return raw, fmt.Errorf("%w: %s", ErrDeclined, apiErr.Error.Message)
+		}
+		if stripeRejected(resp.StatusCode) {
+			return raw, fmt.Errorf("%w: stripe: %s", ErrRejected, apiErr.Error.Message)
+		}
+		return raw, errors.New("stripe: " + apiErr.Error.Message)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return raw, fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+
+	return raw, nil
+}
+
+// stripeRejected reports whether Stripe refused a request outright. A conflict
+// means another request with the same idempotency key is still running.
+func stripeRejected(status int) bool {
+	return status >= 400 && status < 500 && status != http.StatusConflict && status != http.StatusTooManyRequests
+}
+
+func intentStatus(status string) Status {
+	switch status {
+	case "requires_capture":
+		return StatusAuthorized
+	case "succeeded":
+		return StatusCaptured
+	case "canceled":
+		return StatusVoided
+	default:
+		return StatusPending
+	}
+}
+
+func toStripeAmount(amount money.Amount, currency string) int64 {
+	if stripeZeroDecimalCurrencies[strings.ToLower(currency)] {
+		return amount.MulRat(1, 100).Cents()
+	}
+	return amount.Cents()
+}
+
+func fromStripeAmount(amount int64, currency string) money.Amount {
+	if stripeZeroDecimalCurrencies[strings.ToLower(currency)] {
+		return money.FromCents(amount).Mul(100)
+	}
+	return money.FromCents(amount)
+}
@@ -0,0 +1,440 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// stripeStub serves canned responses keyed by "METHOD /path" and records the
+// form of every request it receives.
+type stripeStub struct {
+	t         *testing.T
+	responses map[string]stripeStubResponse
+	requests  map[string]*http.Request
+}
+
+type stripeStubResponse struct {
+	status int
+	body   string
+}
+
+func newStripeStub(t *testing.T, responses map[string]stripeStubResponse) (*stripeStub, Provider) {
+	t.Helper()
+
+	stub := &stripeStub{t: t, responses: responses, requests: map[string]*http.Request{}}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	provider, err := NewStripe(json.RawMessage(fmt.Sprintf(`{"secret_key":"sk_test","publishable_key":"pk_test","base_url":%q}`, server.URL)))
+	if err != nil {
+		t.Fatalf("NewStripe: %v", err)
+	}
+	return stub, provider
+}
+
+func (s *stripeStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	if got := r.Header.Get("Authorization"); got != "Bearer sk_test" {
+		s.t.Errorf("%s: Authorization = %q", key, got)
+	}
+	if err := r.ParseForm(); err != nil {
+		s.t.Errorf("%s: ParseForm: %v", key, err)
+	}
+	s.requests[key] = r
+
+	resp, ok := s.responses[key]
+	if !ok {
+		s.t.Errorf("unexpected request %s", key)
+		http.NotFound(w, r)
+		return
+	}
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	w.Write([]byte(resp.body))
+}
+
+func (s *stripeStub) form(key, field string) string {
+	s.t.Helper()
+	r, ok := s.requests[key]
+	if !ok {
+		s.t.Fatalf("no request %s", key)
+	}
+	return r.PostForm.Get(field)
+}
+
+func TestStripeAuthorize(t *testing.T) {
+	order := Order{
+		ID:            uuid.MustParse("0b9f3c1e-5a2d-4e8f-b6c7-1d2e3f4a5b6c"),
+		Number:        "BZ-2026-000042",
+		Amount:        money.FromCents(1999),
+		Currency:      "EUR",
+		CustomerEmail: "buyer@example.com",
+	}
+
+	tests := []struct {
+		name       string
+		response   stripeStubResponse
+		wantStatus Status
+		wantAction NextAction
+		wantErr    error
+	}{
+		{
+			name:       "requires confirmation",
+			response:   stripeStubResponse{body: `{"id":"pi_1","status":"requires_payment_method","amount":1999,"currency":"eur","client_secret":"pi_1_secret"}`},
+			wantStatus: StatusPending,
+			wantAction: NextAction{Type: ActionClientSecret, ClientSecret: "pi_1_secret", PublishableKey: "pk_test"},
+		},
+		{
+			name:       "redirect",
+			response:   stripeStubResponse{body: `{"id":"pi_1","status":"requires_action","amount":1999,"currency":"eur","next_action":{"redirect_to_url":{"url":"https://bank.example/3ds"}}}`},
+			wantStatus: StatusPending,
+			wantAction: NextAction{Type: ActionRedirect, RedirectURL: "https://bank.example/3ds"},
+		},
+		{
+			name:       "card declined",
+			response:   stripeStubResponse{status: http.StatusPaymentRequired, body: `{"error":{"type":"card_error","code":"card_declined","message":"Your card was declined."}}`},
+			wantStatus: StatusFailed,
+			wantErr:    ErrDeclined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, provider := newStripeStub(t, map[string]stripeStubResponse{"POST /v1/payment_intents": tt.response})
+
+			result, err := provider.Authorize(context.Background(), order)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if tt.wantErr == nil && result.NextAction != tt.wantAction {
+				t.Errorf("NextAction = %+v, want %+v", result.NextAction, tt.wantAction)
+			}
+
+			key := "POST /v1/payment_intents"
+			if got := stub.form(key, "amount"); got != "1999" {
+				t.Errorf("amount = %q, want 1999", got)
+			}
+			if got := stub.form(key, "currency"); got != "eur" {
+				t.Errorf("currency = %q, want eur", got)
+			}
+			if got := stub.form(key, "capture_method"); got != "automatic" {
+				t.Errorf("capture_method = %q, want automatic", got)
+			}
+			if got := stub.form(key, "metadata[order_id]"); got != order.ID.String() {
+				t.Errorf("metadata[order_id] = %q", got)
+			}
+			if got := stub.requests[key].Header.Get("Idempotency-Key"); got != "order-"+order.ID.String() {
+				t.Errorf("Idempotency-Key = %q", got)
+			}
+		})
+	}
+}
+
+func TestStripeCapture(t *testing.T) {
+	tests := []struct {
+		name       string
+		currency   string
+		amount     money.Amount
+		wantAmount string
+	}{
+		{name: "partial", currency: "eur", amount: money.FromCents(1500), wantAmount: "1500"},
+		{name: "full", currency: "eur", amount: 0, wantAmount: ""},
+		{name: "zero decimal currency", currency: "jpy", amount: money.FromCents(150000), wantAmount: "1500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intent := fmt.Sprintf(`{"id":"pi_1","status":"requires_capture","amount":1999,"currency":%q}`, tt.currency)
+			captured := fmt.Sprintf(`{"id":"pi_1","status":"succeeded","amount":1999,"amount_received":1999,"currency":%q}`, tt.currency)
+			stub, provider := newStripeStub(t, map[string]stripeStubResponse{
+				"GET /v1/payment_intents/pi_1":          {body: intent},
+				"POST /v1/payment_intents/pi_1/capture": {body: captured},
+			})
+
+			result, err := provider.Capture(context.Background(), "pi_1", tt.amount, "capture-1")
+			if err != nil {
+				t.Fatalf("Capture: %v", err)
+			}
+			if result.Status != StatusCaptured {
+				t.Errorf("Status = %q, want %q", result.Status, StatusCaptured)
+			}
+			if result.Reference != "pi_1" {
+				t.Errorf("Reference = %q, want pi_1", result.Reference)
+			}
+			if got := stub.form("POST /v1/payment_intents/pi_1/capture", "amount_to_capture"); got != tt.wantAmount {
+				t.Errorf("amount_to_capture = %q, want %q", got, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestStripeRefund(t *testing.T) {
+	tests := []struct {
+		name       string
+		refund     string
+		wantStatus Status
+		wantErr    bool
+	}{
+		{name: "succeeded", refund: `{"id":"re_1","status":"succeeded","amount":500,"currency":"eur","payment_intent":"pi_1"}`, wantStatus: StatusRefunded},
+		{name: "pending", refund: `{"id":"re_1","status":"pending","amount":500,"currency":"eur","payment_intent":"pi_1"}`, wantStatus: StatusRefunded},
+		{name: "failed", refund: `{"id":"re_1","status":"failed","amount":500,"currency":"eur","payment_intent":"pi_1"}`, wantStatus: StatusFailed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, provider := newStripeStub(t, map[string]stripeStubResponse{
+				"GET /v1/payment_intents/pi_1": {body: `{"id":"pi_1","status":"succeeded","amount":1999,"currency":"eur"}`},
+				"POST /v1/refunds":             {body: tt.refund},
+			})
+
+			result, err := provider.Refund(context.Background(), "pi_1", money.FromCents(500), "refund-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Refund error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if !tt.wantErr {
+				if result.TransactionReference != "re_1" {
+					t.Errorf("TransactionReference = %q, want re_1", result.TransactionReference)
+				}
+				if result.Amount != money.FromCents(500) {
+					t.Errorf("Amount = %s, want 5.00", result.Amount)
+				}
+			}
+			if got := stub.form("POST /v1/refunds", "payment_intent"); got != "pi_1" {
+				t.Errorf("payment_intent = %q, want pi_1", got)
+			}
+			if got := stub.form("POST /v1/refunds", "amount"); got != "500" {
+				t.Errorf("amount = %q, want 500", got)
+			}
+		})
+	}
+}
+
+func TestStripeVoid(t *testing.T) {
+	_, provider := newStripeStub(t, map[string]stripeStubResponse{
+		"POST /v1/payment_intents/pi_1/cancel": {body: `{"id":"pi_1","status":"canceled","amount":1999,"currency":"eur"}`},
+	})
+
+	result, err := provider.Void(context.Background(), "pi_1", "void-1")
+	if err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	if result.Status != StatusVoided {
+		t.Errorf("Status = %q, want %q", result.Status, StatusVoided)
+	}
+
+	_, provider = newStripeStub(t, map[string]stripeStubResponse{
+		"POST /v1/payment_intents/pi_1/cancel": {status: http.StatusBadRequest, body: `{"error":{"type":"invalid_request_error","message":"This PaymentIntent has already been captured."}}`},
+	})
+	if _, err := provider.Void(context.Background(), "pi_1", "void-1"); !errors.Is(err, ErrRejected) || errors.Is(err, ErrDeclined) {
+		t.Errorf("Void of a captured intent error = %v, want ErrRejected", err)
+	}
+}
+
+func TestStripeFinalErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantFinal bool
+	}{
+		{name: "invalid request", status: http.StatusBadRequest, body: `{"error":{"type":"invalid_request_error","message":"Charge has already been refunded."}}`, wantFinal: true},
+		{name: "refund failed", status: http.StatusOK, body: `{"id":"re_1","status":"failed","amount":500,"currency":"eur","payment_intent":"pi_1"}`, wantFinal: true},
+		{name: "idempotency conflict", status: http.StatusConflict, body: `{"error":{"type":"idempotency_error","message":"Request in progress."}}`},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{"error":{"type":"rate_limit_error","message":"Too many requests."}}`},
+		{name: "server error", status: http.StatusInternalServerError, body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, provider := newStripeStub(t, map[string]stripeStubResponse{
+				"GET /v1/payment_intents/pi_1": {body: `{"id":"pi_1","status":"succeeded","amount":1999,"currency":"eur"}`},
+				"POST /v1/refunds":             {status: tt.status, body: tt.body},
+			})
+
+			_, err := provider.Refund(context.Background(), "pi_1", money.FromCents(500), "refund-1")
+			if err == nil {
+				t.Fatal("Refund succeeded, want an error")
+			}
+			if got := IsFinal(err); got != tt.wantFinal {
+				t.Errorf("IsFinal(%v) = %v, want %v", err, got, tt.wantFinal)
+			}
+		})
+	}
+}
+
+func TestStripeIdempotencyKeys(t *testing.T) {
+	stub, provider := newStripeStub(t, map[string]stripeStubResponse{
+		"GET /v1/payment_intents/pi_1":          {body: `{"id":"pi_1","status":"requires_capture","amount":1999,"currency":"eur"}`},
+		"POST /v1/payment_intents/pi_1/capture": {body: `{"id":"pi_1","status":"succeeded","amount":1999,"currency":"eur"}`},
+		"POST /v1/payment_intents/pi_1/cancel":  {body: `{"id":"pi_1","status":"canceled","amount":1999,"currency":"eur"}`},
+		"POST /v1/refunds":                      {body: `{"id":"re_1","status":"succeeded","amount":500,"currency":"eur","payment_intent":"pi_1"}`},
+	})
+	ctx := context.Background()
+
+	calls := []struct {
+		request string
+		key     string
+		call    func(key string) error
+	}{
+		{
+			request: "POST /v1/payment_intents/pi_1/capture",
+			key:     "capture-7",
+			call: func(key string) error {
+				_, err := provider.Capture(ctx, "pi_1", 0, key)
+				return err
+			},
+		},
+		{
+			request: "POST /v1/payment_intents/pi_1/cancel",
+			key:     "void-7",
+			call: func(key string) error {
+				_, err := provider.Void(ctx, "pi_1", key)
+				return err
+			},
+		},
+		{
+			request: "POST /v1/refunds",
+			key:     "refund-7",
+			call: func(key string) error {
+				_, err := provider.Refund(ctx, "pi_1", money.FromCents(500), key)
+				return err
+			},
+		},
+	}
+
+	for _, c := range calls {
+		t.Run(c.request, func(t *testing.T) {
+			if err := c.call(c.key); err != nil {
+				t.Fatalf("%s: %v", c.request, err)
+			}
+			if got := stub.requests[c.request].Header.Get("Idempotency-Key"); got != c.key {
+				t.Errorf("Idempotency-Key = %q, want %q", got, c.key)
+			}
+		})
+	}
+}
+
+func signStripeWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestStripeParseWebhookSignature(t *testing.T) {
+	provider, err := NewStripe(json.RawMessage(`{"secret_key":"sk_test","webhook_secret":"whsec_test"}`))
+	if err != nil {
+		t.Fatalf("NewStripe: %v", err)
+	}
+	parser := provider.(WebhookParser)
+
+	body := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_1","status":"succeeded","amount":1999,"amount_received":1999,"currency":"eur"}}}`)
+	now := time.Now().Unix()
+	stale := time.Now().Add(-stripeWebhookTolerance - time.Minute).Unix()
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr error
+	}{
+		{name: "valid", header: fmt.Sprintf("t=%d,v1=%s", now, signStripeWebhook("whsec_test", now, body))},
+		{name: "valid among several", header: fmt.Sprintf("t=%d,v1=%s,v1=%s", now, signStripeWebhook("whsec_old", now, body), signStripeWebhook("whsec_test", now, body))},
+		{name: "bad v1", header: fmt.Sprintf("t=%d,v1=%s", now, signStripeWebhook("whsec_other", now, body)), wantErr: ErrInvalidSignature},
+		{name: "stale timestamp", header: fmt.Sprintf("t=%d,v1=%s", stale, signStripeWebhook("whsec_test", stale, body)), wantErr: ErrInvalidSignature},
+		{name: "timestamp not signed", header: fmt.Sprintf("t=%d,v1=%s", now+1, signStripeWebhook("whsec_test", now, body)), wantErr: ErrInvalidSignature},
+		{name: "missing v1", header: fmt.Sprintf("t=%d", now), wantErr: ErrInvalidSignature},
+		{name: "missing header", header: "", wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Stripe-Signature", tt.header)
+
+			event, err := parser.ParseWebhook(header, body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseWebhook error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			want := WebhookEvent{ID: "evt_1", Type: "payment_intent.succeeded", Reference: "pi_1", Status: StatusCaptured, Amount: money.FromCents(1999)}
+			if event != want {
+				t.Errorf("event = %+v, want %+v", event, want)
+			}
+		})
+	}
+}
+
+func TestStripeParseWebhookEvents(t *testing.T) {
+	provider, err := NewStripe(json.RawMessage(`{"secret_key":"sk_test","webhook_secret":"whsec_test"}`))
+	if err != nil {
+		t.Fatalf("NewStripe: %v", err)
+	}
+	parser := provider.(WebhookParser)
+
+	tests := []struct {
+		name string
+		body string
+		want WebhookEvent
+	}{
+		{
+			name: "authorized",
+			body: `{"id":"evt_1","type":"payment_intent.amount_capturable_updated","data":{"object":{"id":"pi_1","status":"requires_capture","amount":1999,"currency":"eur"}}}`,
+			want: WebhookEvent{ID: "evt_1", Type: "payment_intent.amount_capturable_updated", Reference: "pi_1", Status: StatusAuthorized, Amount: money.FromCents(1999)},
+		},
+		{
+			name: "canceled",
+			body: `{"id":"evt_1","type":"payment_intent.canceled","data":{"object":{"id":"pi_1","status":"canceled","amount":1999,"currency":"eur"}}}`,
+			want: WebhookEvent{ID: "evt_1", Type: "payment_intent.canceled", Reference: "pi_1", Status: StatusVoided, Amount: money.FromCents(1999)},
+		},
+		{
+			name: "refund created",
+			body: `{"id":"evt_1","type":"refund.created","data":{"object":{"id":"re_1","status":"succeeded","amount":500,"currency":"eur","payment_intent":"pi_1"}}}`,
+			want: WebhookEvent{ID: "evt_1", Type: "refund.created", Reference: "pi_1", TransactionReference: "re_1", Status: StatusRefunded, Amount: money.FromCents(500)},
+		},
+		{
+			name: "charge refunded",
+			body: `{"id":"evt_1","type":"charge.refunded","data":{"object":{"id":"ch_1","amount":1999,"amount_refunded":500,"currency":"eur","payment_intent":"pi_1"}}}`,
+			want: WebhookEvent{ID: "evt_1", Type: "charge.refunded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().Unix()
+			header := http.Header{}
+			header.Set("Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", now, signStripeWebhook("whsec_test", now, []byte(tt.body))))
+
+			event, err := parser.ParseWebhook(header, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseWebhook: %v", err)
+			}
+			if event != tt.want {
+				t.Errorf("event = %+v, want %+v", event, tt.want)
+			}
+		})
+	}
+}
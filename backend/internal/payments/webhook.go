@@ -0,0 +1,29 @@
+package payments
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// WebhookEvent is a provider notification reduced to what the shop acts on.
+// Reference is the payment the event is about; TransactionReference is set for
+// refunds that have their own identifier, as in Result.
+type WebhookEvent struct {
+	ID                   string
+	Type                 string
+	Reference            string
+	TransactionReference string
+	Status               Status
+	Amount               money.Amount
+}
+
+// WebhookParser is implemented by providers that send webhooks. ParseWebhook must
+// verify the signature before trusting anything in the body, and return
+// ErrInvalidSignature when it does not match.
+type WebhookParser interface {
+	ParseWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
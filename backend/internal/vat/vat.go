@@ -0,0 +1,104 @@
+// Package vat checks EU VAT identification numbers.
+package vat
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrUnsupportedCountry = errors.New("VAT IDs are not supported for this country")
+	ErrInvalidFormat      = errors.New("invalid VAT ID format")
+	ErrNotRegistered      = errors.New("VAT ID is not registered")
+)
+
+type format struct {
+	prefix  string
+	pattern *regexp.Regexp
+}
+
+// formats maps ISO 3166 country codes of EU member states to the prefix and
+// shape of their VAT IDs. Greece uses EL rather than its ISO code.
+var formats = map[string]format{
+	"AT": {"AT", regexp.MustCompile(`^ATU\d{8}$`)},
+	"BE": {"BE", regexp.MustCompile(`^BE[01]\d{9}$`)},
+	"BG": {"BG", regexp.MustCompile(`^BG\d{9,10}$`)},
+	"CY": {"CY", regexp.MustCompile(`^CY\d{8}[A-Z]$`)},
+	"CZ": {"CZ", regexp.MustCompile(`^CZ\d{8,10}$`)},
+	"DE": {"DE", regexp.MustCompile(`^DE\d{9}$`)},
+	"DK": {"DK", regexp.MustCompile(`^DK\d{8}$`)},
+	"EE": {"EE", regexp.MustCompile(`^EE\d{9}$`)},
+	"ES": {"ES", regexp.MustCompile(`^ES[A-Z0-9]\d{7}[A-Z0-9]$`)},
+	"FI": {"FI", regexp.MustCompile(`^FI\d{8}$`)},
+	"FR": {"FR", regexp.MustCompile(`^FR[A-HJ-NP-Z0-9]{2}\d{9}$`)},
+	"GR": {"EL", regexp.MustCompile(`^EL\d{9}$`)},
+	"HR": {"HR", regexp.MustCompile(`^HR\d{11}$`)},
+	"HU": {"HU", regexp.MustCompile(`^HU\d{8}$`)},
+	"IE": {"IE", regexp.MustCompile(`^IE(\d{7}[A-W][A-IW]?|\d[A-Z+*]\d{5}[A-W])$`)},
+	"IT": {"IT", regexp.MustCompile(`^IT\d{11}$`)},
+	"LT": {"LT", regexp.MustCompile(`^LT(\d{9}|\d{12})$`)},
+	"LU": {"LU", regexp.MustCompile(`^LU\d{8}$`)},
+	"LV": {"LV", regexp.MustCompile(`^LV\d{11}$`)},
+	"MT": {"MT", regexp.MustCompile(`^MT\d{8}$`)},
+	"NL": {"NL", regexp.MustCompile(`^NL\d{9}B\d{2}$`)},
+	"PL": {"PL", regexp.MustCompile(`^PL\d{10}$`)},
+	"PT": {"PT", regexp.MustCompile(`^PT\d{9}$`)},
+	"RO": {"RO", regexp.MustCompile(`^RO\d{2,10}$`)},
+	"SE": {"SE", regexp.MustCompile(`^SE\d{12}$`)},
+	"SI": {"SI", regexp.MustCompile(`^SI\d{8}$`)},
+	"SK": {"SK", regexp.MustCompile(`^SK\d{10}$`)},
+}
+
+// IsEU reports whether a country code belongs to an EU member state.
+func IsEU(countryCode string) bool {
+	_, ok := formats[strings.ToUpper(countryCode)]
+	return ok
+}
+
+// Normalize uppercases a VAT ID and drops the spaces, dots and dashes people
+// type into it. The country prefix is added when the ID starts with a digit.
+func Normalize(countryCode, id string) string {
+	id = strings.ToUpper(id)
+	id = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-':
+			return -1
+		}
+		return r
+	}, id)
+
+	if f, ok := formats[strings.ToUpper(countryCode)]; ok && id != "" && id[0] >= '0' && id[0] <= '9' {
+		id = f.prefix + id
+	}
+	return id
+}
+
+// CheckFormat reports whether a normalized ID has the shape of a VAT ID issued
+// by the given country.
+func CheckFormat(countryCode, id string) error {
+	f, ok := formats[strings.ToUpper(countryCode)]
+	if !ok {
+		return ErrUnsupportedCountry
+	}
+	if !f.pattern.MatchString(id) {
+		return ErrInvalidFormat
+	}
+	return nil
+}
+
+// Validator confirms with a registry that a well-formed ID is actually issued.
+// It returns ErrNotRegistered for unknown IDs; any other error means the
+// registry could not be asked.
+type Validator interface {
+	Validate(ctx context.Context, id string) error
+}
+
+// VIES stands in for the European Commission's VIES service. It does not call
+// the service yet and accepts every ID that passed CheckFormat.
+type VIES struct{}
+
+func (VIES) Validate(ctx context.Context, id string) error {
+	return nil
+}
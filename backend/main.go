@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
+	"github.com/bzelaznicki/bzCommerce/internal/vat"
 	"github.com/joho/godotenv"
 
 	_ "github.com/lib/pq"
@@ -27,6 +30,19 @@ type apiConfig struct {
 	cartTimeoutMinutes int
 	cartCookieKey      []byte
 	maxCartQuantity    int
+	orderNumberFormat  string
+	orderNumberDigits  int32
+	orderAccessSecret  []byte
+	orderAccessTTL     time.Duration
+	storeCurrency      string
+	payments           *payments.Registry
+	unpaidOrderTimeout time.Duration
+	pricesIncludeTax   bool
+	taxShipping        bool
+	storeCountry       string
+	vatValidator       vat.Validator
+	lookupIPLimiter    *rateLimiter
+	lookupEmailLimiter *rateLimiter
 }
 
 func main() {
@@ -73,6 +89,10 @@ func main() {
 	if storeName == "" {
 		storeName = "bzCommerce" // fallback default
 	}
+	storeCurrency := strings.ToUpper(os.Getenv("STORE_CURRENCY"))
+	if storeCurrency == "" {
+		storeCurrency = defaultStoreCurrency
+	}
 	timeoutStr := os.Getenv("CART_TIMEOUT_MINUTES")
 	timeoutMinutes := 60
 	if timeoutStr != "" {
@@ -91,6 +111,78 @@ func main() {
 		}
 	}
 
+	orderNumberFormat := os.Getenv("ORDER_NUMBER_FORMAT")
+	if orderNumberFormat == "" {
+		orderNumberFormat = defaultOrderNumberFormat
+	}
+	if !strings.Contains(orderNumberFormat, "{seq}") {
+		log.Fatal("ORDER_NUMBER_FORMAT must contain {seq}")
+	}
+
+	orderNumberStr := os.Getenv("ORDER_NUMBER_DIGITS")
+	orderNumberDigits := defaultOrderNumberDigits
+	if orderNumberStr != "" {
+		if parsed, err := strconv.Atoi(orderNumberStr); err == nil && parsed > 0 && parsed <= 20 {
+			orderNumberDigits = parsed
+		}
+	}
+
+	// Order access links fall back to the cart secret; the signed message is
+	// prefixed, so a cart signature can never pass as an order token.
+	orderAccessSecret := os.Getenv("ORDER_ACCESS_SECRET")
+	if orderAccessSecret == "" {
+		orderAccessSecret = cartCookieKey
+	}
+
+	orderAccessTTLStr := os.Getenv("ORDER_ACCESS_TTL_HOURS")
+	orderAccessTTLHours := defaultOrderAccessTTLHours
+	if orderAccessTTLStr != "" {
+		if parsed, err := strconv.Atoi(orderAccessTTLStr); err == nil && parsed > 0 {
+			orderAccessTTLHours = parsed
+		}
+	}
+
+	// Zero disables expiring unpaid orders.
+	unpaidOrderTimeoutStr := os.Getenv("UNPAID_ORDER_TIMEOUT_MINUTES")
+	unpaidOrderTimeoutMinutes := defaultUnpaidOrderTimeoutMinutes
+	if unpaidOrderTimeoutStr != "" {
+		if parsed, err := strconv.Atoi(unpaidOrderTimeoutStr); err == nil && parsed >= 0 {
+			unpaidOrderTimeoutMinutes = parsed
+		}
+	}
+
+	// Catalogue and shipping prices are net of tax unless PRICES_INCLUDE_TAX is
+	// set, and shipping is taxed at the destination's standard rate by default.
+	pricesIncludeTax := false
+	if parsed, err := strconv.ParseBool(os.Getenv("PRICES_INCLUDE_TAX")); err == nil {
+		pricesIncludeTax = parsed
+	}
+	taxShipping := true
+	if parsed, err := strconv.ParseBool(os.Getenv("TAX_SHIPPING")); err == nil {
+		taxShipping = parsed
+	}
+
+	// STORE_COUNTRY is the ISO code the store is registered for VAT in. Reverse
+	// charge only applies to EU buyers outside it, so it stays off when unset.
+	storeCountry := strings.ToUpper(os.Getenv("STORE_COUNTRY"))
+
+	// VAT IDs are always format checked; VAT_VALIDATOR=vies also asks VIES.
+	var vatValidator vat.Validator
+	switch os.Getenv("VAT_VALIDATOR") {
+	case "":
+	case "vies":
+		vatValidator = vat.VIES{}
+	default:
+		log.Fatal("VAT_VALIDATOR must be empty or vies")
+	}
+
+	// The fake provider completes payments without taking any money, so it is
+	// only offered in development.
+	paymentRegistry := payments.DefaultRegistry()
+	if platform == "dev" {
+		paymentRegistry.Register(payments.FakeKey, payments.NewFake)
+	}
+
 	templates := template.Must(template.ParseFiles(
 		"templates/base.html",
 	))
@@ -106,6 +198,19 @@ func main() {
 		cartTimeoutMinutes: timeoutMinutes,
 		cartCookieKey:      []byte(cartCookieKey),
 		maxCartQuantity:    maxCart,
+		orderNumberFormat:  orderNumberFormat,
+		orderNumberDigits:  int32(orderNumberDigits),
+		orderAccessSecret:  []byte(orderAccessSecret),
+		orderAccessTTL:     time.Duration(orderAccessTTLHours) * time.Hour,
+		storeCurrency:      storeCurrency,
+		payments:           paymentRegistry,
+		unpaidOrderTimeout: time.Duration(unpaidOrderTimeoutMinutes) * time.Minute,
+		pricesIncludeTax:   pricesIncludeTax,
+		taxShipping:        taxShipping,
+		storeCountry:       storeCountry,
+		vatValidator:       vatValidator,
+		lookupIPLimiter:    newRateLimiter(orderLookupsPerIP, orderLookupWindow),
+		lookupEmailLimiter: newRateLimiter(orderLookupsPerEmail, orderLookupWindow),
 	}
 
 	mux := http.NewServeMux()
@@ -120,6 +225,8 @@ func main() {
 	}
 
 	cfg.startCartExpirationWorker()
+	cfg.startOrderExpirationWorker()
+	cfg.startPaymentOperationWorker()
 	fmt.Printf("serving on port %s\n", port)
 
 	log.Fatal(srv.ListenAndServe())
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/bzelaznicki/bzCommerce/internal/pdf"
+	"github.com/google/uuid"
+)
+
+const (
+	docMargin     = 50.0
+	docLineHeight = 14.0
+	docFontSize   = 10.0
+)
+
+type orderDocumentData struct {
+	StoreName       string
+	Currency        string
+	Order           database.GetOrderWithUserByIdRow
+	Items           []database.GetOrderItemsByOrderIdWithVariantsRow
+	TaxLines        []TaxLine
+	Discounts       []DiscountLine
+	ShippingCountry string
+	BillingCountry  string
+}
+
+func (cfg *apiConfig) loadOrderDocumentData(ctx context.Context, orderID uuid.UUID) (orderDocumentData, error) {
+	order, err := cfg.db.GetOrderWithUserById(ctx, orderID)
+	if err != nil {
+		return orderDocumentData{}, err
+	}
+
+	items, err := cfg.db.GetOrderItemsByOrderIdWithVariants(ctx, orderID)
+	if err != nil {
+		return orderDocumentData{}, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	taxLines, err := getOrderTaxLines(ctx, cfg.db, orderID)
+	if err != nil {
+		return orderDocumentData{}, err
+	}
+
+	discounts, err := getOrderDiscountLines(ctx, cfg.db, orderID)
+	if err != nil {
+		return orderDocumentData{}, err
+	}
+
+	data := orderDocumentData{
+		StoreName: cfg.storeName,
+		Currency:  cfg.orderCurrency(order.Currency),
+		Order:     order,
+		Items:     items,
+		TaxLines:  taxLines,
+		Discounts: discounts,
+	}
+
+	// Missing countries only leave the country line blank.
+	if country, err := cfg.db.GetCountryById(ctx, order.ShippingCountryID); err == nil {
+		data.ShippingCountry = country.Name
+	}
+	if country, err := cfg.db.GetCountryById(ctx, order.BillingCountryID); err == nil {
+		data.BillingCountry = country.Name
+	}
+
+	return data, nil
+}
+
+// docWriter tracks the vertical position on the current page and starts a new
+// page when the next row would not fit.
+type docWriter struct {
+	doc   *pdf.Document
+	y     float64
+	title string
+}
+
+func newDocWriter(title string) *docWriter {
+	w := &docWriter{doc: pdf.New(), title: title}
+	w.newPage()
+	return w
+}
+
+func (w *docWriter) newPage() {
+	w.doc.AddPage()
+	w.y = docMargin
+}
+
+func (w *docWriter) ensure(height float64) {
+	if w.y+height > pdf.PageHeight-docMargin {
+		w.newPage()
+		w.doc.Text(docMargin, w.y, docFontSize, true, w.title+" (continued)")
+		w.y += docLineHeight * 2
+	}
+}
+
+func (w *docWriter) header(data orderDocumentData) {
+	w.doc.Text(docMargin, w.y+8, 18, true, data.StoreName)
+	w.doc.TextRight(pdf.PageWidth-docMargin, w.y+8, 18, true, w.title)
+	w.y += docLineHeight * 2.5
+
+	w.doc.TextRight(pdf.PageWidth-docMargin, w.y, docFontSize, false, "Order "+data.Order.OrderNumber)
+	w.y += docLineHeight
+	w.doc.TextRight(pdf.PageWidth-docMargin, w.y, docFontSize, false, "Date "+data.Order.CreatedAt.Format("2006-01-02"))
+	w.y += docLineHeight * 2
+}
+
+// addresses prints up to two address blocks side by side.
+func (w *docWriter) addresses(blocks ...[]string) {
+	height := 0
+	for _, block := range blocks {
+		height = max(height, len(block))
+	}
+	w.ensure(float64(height) * docLineHeight)
+
+	colWidth := (pdf.PageWidth - docMargin*2) / 2
+	for i, block := range blocks {
+		x := docMargin + float64(i)*colWidth
+		for j, line := range block {
+			w.doc.Text(x, w.y+float64(j)*docLineHeight, docFontSize, j == 0, pdf.Truncate(line, colWidth-10, docFontSize, j == 0))
+		}
+	}
+	w.y += float64(height)*docLineHeight + docLineHeight
+}
+
+type docColumn struct {
+	title string
+	width float64
+	right bool
+}
+
+func (w *docWriter) row(columns []docColumn, values []string, bold bool) {
+	w.ensure(docLineHeight)
+	x := docMargin
+	for i, col := range columns {
+		value := pdf.Truncate(values[i], col.width-6, docFontSize, bold)
+		if col.right {
+			w.doc.TextRight(x+col.width, w.y, docFontSize, bold, value)
+		} else {
+			w.doc.Text(x, w.y, docFontSize, bold, value)
+		}
+		x += col.width
+	}
+	w.y += docLineHeight
+}
+
+func (w *docWriter) rule() {
+	w.doc.Line(docMargin, w.y-docLineHeight+4, pdf.PageWidth-docMargin, w.y-docLineHeight+4, 0.5)
+}
+
+func (w *docWriter) totalLine(label, value string, bold bool) {
+	w.ensure(docLineHeight)
+	w.doc.TextRight(pdf.PageWidth-docMargin-90, w.y, docFontSize, bold, label)
+	w.doc.TextRight(pdf.PageWidth-docMargin, w.y, docFontSize, bold, value)
+	w.y += docLineHeight
+}
+
+func addressBlock(title, name, address, postalCode, city, country string) []string {
+	block := []string{title, name, address, postalCode + " " + city}
+	if country != "" {
+		block = append(block, country)
+	}
+	return block
+}
+
+func itemDescription(item database.GetOrderItemsByOrderIdWithVariantsRow) string {
+	if item.VariantName.Valid && item.VariantName.String != "" {
+		return item.ProductName + " - " + item.VariantName.String
+	}
+	return item.ProductName
+}
+
+func renderInvoice(data orderDocumentData) []byte {
+	w := newDocWriter("Invoice")
+	w.header(data)
+
+	order := data.Order
+	billTo := addressBlock("Bill to", order.BillingName, order.BillingAddress, order.BillingPostalCode, order.BillingCity, data.BillingCountry)
+	if order.VatID.Valid {
+		billTo = append(billTo, "VAT ID: "+order.VatID.String)
+	}
+	w.addresses(
+		billTo,
+		addressBlock("Ship to", order.ShippingName, order.ShippingAddress, order.ShippingPostalCode, order.ShippingCity, data.ShippingCountry),
+	)
+
+	columns := []docColumn{
+		{title: "Item", width: 215},
+		{title: "SKU", width: 90},
+		{title: "Qty", width: 40, right: true},
+		{title: "Unit price", width: 75, right: true},
+		{title: "Total", width: 75, right: true},
+	}
+	titles := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.title
+	}
+
+	w.row(columns, titles, true)
+	w.rule()
+
+	var subtotal money.Amount
+	for _, item := range data.Items {
+		lineTotal := item.PricePerItem.Mul(int64(item.Quantity))
+		subtotal += lineTotal
+		w.row(columns, []string{
+			itemDescription(item),
+			item.Sku,
+			fmt.Sprintf("%d", item.Quantity),
+			item.PricePerItem.String(),
+			lineTotal.String(),
+		}, false)
+	}
+
+	w.y += docLineHeight / 2
+	w.rule()
+	w.y += docLineHeight / 2
+
+	shippingLabel := "Shipping"
+	if order.ShippingMethodName.Valid {
+		shippingLabel = "Shipping (" + order.ShippingMethodName.String + ")"
+	}
+
+	w.totalLine("Subtotal", subtotal.String(), false)
+	w.totalLine(shippingLabel, order.ShippingPrice.String(), false)
+	for _, line := range data.Discounts {
+		w.totalLine(line.Name, (-line.Amount).String(), false)
+	}
+	// Orders placed before discounts were itemised only have the total.
+	if len(data.Discounts) == 0 && !order.DiscountTotal.IsZero() {
+		discountLabel := "Discount"
+		if order.CouponCode.Valid {
+			discountLabel = "Discount (" + order.CouponCode.String + ")"
+		}
+		w.totalLine(discountLabel, (-order.DiscountTotal).String(), false)
+	}
+	for _, line := range data.TaxLines {
+		label := fmt.Sprintf("%s %s%%", line.Name, line.Rate)
+		if line.IsShipping {
+			label += " on shipping"
+		}
+		if order.PricesIncludeTax && !order.ReverseCharge {
+			label = "Incl. " + label
+		}
+		w.totalLine(label, line.TaxAmount.String(), false)
+	}
+	// Under reverse charge the VAT in tax-inclusive prices is not charged, so
+	// the total falls short of the listed prices by that amount.
+	if waived := subtotal + order.ShippingPrice - order.DiscountTotal - order.TotalPrice; order.ReverseCharge && order.PricesIncludeTax && !waived.IsZero() {
+		w.totalLine("VAT not charged", (-waived).String(), false)
+	}
+	w.totalLine("Total", order.TotalPrice.String()+" "+data.Currency, true)
+
+	if order.ReverseCharge {
+		w.y += docLineHeight
+		w.ensure(docLineHeight)
+		w.doc.Text(docMargin, w.y, docFontSize, true, pdf.Truncate("Reverse charge: VAT to be accounted for by the recipient, Art. 196 VAT Directive.", pdf.PageWidth-docMargin*2, docFontSize, true))
+		w.y += docLineHeight
+	}
+
+	w.y += docLineHeight
+	if order.PaymentMethodName.Valid {
+		w.ensure(docLineHeight)
+		w.doc.Text(docMargin, w.y, docFontSize, false, "Payment method: "+order.PaymentMethodName.String)
+		w.y += docLineHeight
+	}
+	w.ensure(docLineHeight)
+	w.doc.Text(docMargin, w.y, docFontSize, false, "Payment status: "+string(order.PaymentStatus))
+
+	return w.doc.Bytes()
+}
+
+func renderPackingSlip(data orderDocumentData) []byte {
+	w := newDocWriter("Packing slip")
+	w.header(data)
+
+	order := data.Order
+	shipTo := addressBlock("Ship to", order.ShippingName, order.ShippingAddress, order.ShippingPostalCode, order.ShippingCity, data.ShippingCountry)
+	if order.ShippingPhone != "" {
+		shipTo = append(shipTo, order.ShippingPhone)
+	}
+	shippingBlock := []string{"Shipping method"}
+	if order.ShippingMethodName.Valid {
+		shippingBlock = append(shippingBlock, order.ShippingMethodName.String)
+	}
+	w.addresses(shipTo, shippingBlock)
+
+	if order.CustomerNote.Valid && order.CustomerNote.String != "" {
+		w.ensure(docLineHeight * 3)
+		w.doc.Text(docMargin, w.y, docFontSize, true, "Delivery instructions")
+		w.y += docLineHeight
+		w.doc.Text(docMargin, w.y, docFontSize, false, pdf.Truncate(order.CustomerNote.String, pdf.PageWidth-docMargin*2, docFontSize, false))
+		w.y += docLineHeight * 2
+	}
+
+	columns := []docColumn{
+		{title: "SKU", width: 110},
+		{title: "Item", width: 335},
+		{title: "Qty", width: 50, right: true},
+	}
+
+	w.row(columns, []string{"SKU", "Item", "Qty"}, true)
+	w.rule()
+
+	var units int32
+	for _, item := range data.Items {
+		units += item.Quantity
+		w.row(columns, []string{item.Sku, itemDescription(item), fmt.Sprintf("%d", item.Quantity)}, false)
+	}
+
+	w.y += docLineHeight / 2
+	w.rule()
+	w.y += docLineHeight / 2
+	w.totalLine("Units", fmt.Sprintf("%d", units), true)
+
+	return w.doc.Bytes()
+}
+
+func respondWithPDF(w http.ResponseWriter, filename string, body []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+type OrderAddressInput struct {
+	Name       string    `json:"name"`
+	Address    string    `json:"address"`
+	City       string    `json:"city"`
+	PostalCode string    `json:"postal_code"`
+	CountryID  uuid.UUID `json:"country_id"`
+	Phone      string    `json:"phone"`
+}
+
+type OrderEditLine struct {
+	VariantID uuid.UUID `json:"variant_id"`
+	Quantity  int32     `json:"quantity"`
+}
+
+// OrderEditRequest describes an admin edit. Omitted sections are left unchanged;
+// each line sets the final quantity for its variant, and a quantity of 0 removes it.
+type OrderEditRequest struct {
+	ShippingAddress  *OrderAddressInput `json:"shipping_address"`
+	BillingAddress   *OrderAddressInput `json:"billing_address"`
+	ShippingMethodID *uuid.UUID         `json:"shipping_method_id"`
+	Items            []OrderEditLine    `json:"items"`
+}
+
+type fieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type lineChange struct {
+	VariantID uuid.UUID `json:"variant_id"`
+	Sku       string    `json:"sku,omitempty"`
+	From      int32     `json:"from"`
+	To        int32     `json:"to"`
+}
+
+func isEditableOrderStatus(status database.OrderStatus) bool {
+	switch status {
+	case database.OrderStatusPending, database.OrderStatusPaid, database.OrderStatusProcessing:
+		return true
+	default:
+		return false
+	}
+}
+
+func normalizeAddressInput(in *OrderAddressInput, requirePhone bool) error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Address = strings.TrimSpace(in.Address)
+	in.City = strings.TrimSpace(in.City)
+	in.PostalCode = strings.TrimSpace(in.PostalCode)
+	in.Phone = strings.TrimSpace(in.Phone)
+
+	if in.Name == "" || in.Address == "" || in.City == "" || in.PostalCode == "" || in.CountryID == uuid.Nil {
+		return validationError("address is missing required fields")
+	}
+	if requirePhone && in.Phone == "" {
+		return validationError("shipping phone is required")
+	}
+	return nil
+}
+
+func diffField(changes map[string]fieldChange, name string, from, to any) {
+	if from != to {
+		changes[name] = fieldChange{From: from, To: to}
+	}
+}
+
+// editOrder applies an admin edit to a locked order. Stock follows every quantity
+// change, totals and tax are recomputed from the stored lines, and the
+// before/after values are recorded on the order timeline.
+func (cfg *apiConfig) editOrder(ctx context.Context, qtx *database.Queries, order database.Order, req OrderEditRequest, actor orderActor) (database.Order, error) {
+	if !isEditableOrderStatus(order.Status) {
+		return order, validationErrorf("orders with status %s cannot be edited", order.Status)
+	}
+
+	shippingCountryID := order.ShippingCountryID
+	billingCountryID := order.BillingCountryID
+	reverseCharge := order.ReverseCharge
+	order, err := editOrderAddresses(ctx, qtx, order, req, actor)
+	if err != nil {
+		return order, err
+	}
+
+	if order.BillingCountryID != billingCountryID {
+		order, err = cfg.recheckReverseCharge(ctx, qtx, order)
+		if err != nil {
+			return order, err
+		}
+	}
+
+	// Tax is charged at the shipping country's rates, so moving the order to
+	// another country retotals it even when the lines stay the same. So does a
+	// billing change that starts or ends a reverse charge.
+	if len(req.Items) == 0 && req.ShippingMethodID == nil && order.ShippingCountryID == shippingCountryID && order.ReverseCharge == reverseCharge {
+		return order, nil
+	}
+
+	return cfg.editOrderLines(ctx, qtx, order, req, actor)
+}
+
+func editOrderAddresses(ctx context.Context, qtx *database.Queries, order database.Order, req OrderEditRequest, actor orderActor) (database.Order, error) {
+	if req.ShippingAddress == nil && req.BillingAddress == nil {
+		return order, nil
+	}
+
+	params := database.UpdateOrderAddressesParams{
+		ShippingName:       order.ShippingName,
+		ShippingAddress:    order.ShippingAddress,
+		ShippingCity:       order.ShippingCity,
+		ShippingPostalCode: order.ShippingPostalCode,
+		ShippingCountryID:  order.ShippingCountryID,
+		ShippingPhone:      order.ShippingPhone,
+		BillingName:        order.BillingName,
+		BillingAddress:     order.BillingAddress,
+		BillingCity:        order.BillingCity,
+		BillingPostalCode:  order.BillingPostalCode,
+		BillingCountryID:   order.BillingCountryID,
+		ID:                 order.ID,
+	}
+
+	shippingChanges := map[string]fieldChange{}
+	billingChanges := map[string]fieldChange{}
+
+	if in := req.ShippingAddress; in != nil {
+		if err := normalizeAddressInput(in, true); err != nil {
+			return order, err
+		}
+		if err := checkActiveCountry(ctx, qtx, in.CountryID); err != nil {
+			return order, err
+		}
+		params.ShippingName = in.Name
+		params.ShippingAddress = in.Address
+		params.ShippingCity = in.City
+		params.ShippingPostalCode = in.PostalCode
+		params.ShippingCountryID = in.CountryID
+		params.ShippingPhone = in.Phone
+
+		diffField(shippingChanges, "name", order.ShippingName, in.Name)
+		diffField(shippingChanges, "address", order.ShippingAddress, in.Address)
+		diffField(shippingChanges, "city", order.ShippingCity, in.City)
+		diffField(shippingChanges, "postal_code", order.ShippingPostalCode, in.PostalCode)
+		diffField(shippingChanges, "country_id", order.ShippingCountryID, in.CountryID)
+		diffField(shippingChanges, "phone", order.ShippingPhone, in.Phone)
+	}
+
+	if in := req.BillingAddress; in != nil {
+		if err := normalizeAddressInput(in, false); err != nil {
+			return order, err
+		}
+		if err := checkActiveCountry(ctx, qtx, in.CountryID); err != nil {
+			return order, err
+		}
+		params.BillingName = in.Name
+		params.BillingAddress = in.Address
+		params.BillingCity = in.City
+		params.BillingPostalCode = in.PostalCode
+		params.BillingCountryID = in.CountryID
+
+		diffField(billingChanges, "name", order.BillingName, in.Name)
+		diffField(billingChanges, "address", order.BillingAddress, in.Address)
+		diffField(billingChanges, "city", order.BillingCity, in.City)
+		diffField(billingChanges, "postal_code", order.BillingPostalCode, in.PostalCode)
+		diffField(billingChanges, "country_id", order.BillingCountryID, in.CountryID)
+	}
+
+	if len(shippingChanges) == 0 && len(billingChanges) == 0 {
+		return order, nil
+	}
+
+	updated, err := qtx.UpdateOrderAddresses(ctx, params)
+	if err != nil {
+		return order, fmt.Errorf("failed to update addresses: %w", err)
+	}
+
+	payload := map[string]any{}
+	if len(shippingChanges) > 0 {
+		payload["shipping"] = shippingChanges
+	}
+	if len(billingChanges) > 0 {
+		payload["billing"] = billingChanges
+	}
+
+	if err := recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeAddressUpdated, actor, payload); err != nil {
+		return order, err
+	}
+
+	return updated, nil
+}
+
+func checkActiveCountry(ctx context.Context, qtx *database.Queries, countryID uuid.UUID) error {
+	country, err := qtx.GetCountryById(ctx, countryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return validationError("invalid country")
+		}
+		return fmt.Errorf("failed to load country: %w", err)
+	}
+	if !country.IsActive {
+		return validationError("invalid country")
+	}
+	return nil
+}
+
+// editOrderLines applies line and shipping changes to a locked order and retotals
+// it. An order whose payment has been captured or authorized keeps its total,
+// since the captured or authorized amount would no longer match it; it has to be
+// refunded or cancelled instead.
+func (cfg *apiConfig) editOrderLines(ctx context.Context, qtx *database.Queries, order database.Order, req OrderEditRequest, actor orderActor) (database.Order, error) {
+	held, err := paymentHeld(ctx, qtx, order)
+	if err != nil {
+		return order, err
+	}
+	if held {
+		return order, errOrderPaymentHeld
+	}
+
+	if len(req.Items) > 0 || req.ShippingMethodID != nil {
+		shipped, err := qtx.GetShippedQuantitiesByOrderId(ctx, order.ID)
+		if err != nil {
+			return order, fmt.Errorf("failed to load shipped quantities: %w", err)
+		}
+		refunded, err := qtx.GetRefundedQuantitiesByOrderId(ctx, order.ID)
+		if err != nil {
+			return order, fmt.Errorf("failed to load refunded quantities: %w", err)
+		}
+		if len(shipped) > 0 || len(refunded) > 0 {
+			return order, validationError("lines and shipping cannot be changed once the order has shipments or refunds")
+		}
+	}
+
+	existing, err := qtx.GetOrderItemsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load order items: %w", err)
+	}
+	itemsByVariant := make(map[uuid.UUID]database.OrdersVariant, len(existing))
+	for _, item := range existing {
+		itemsByVariant[item.ProductVariantID] = item
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.Items))
+	changes := []lineChange{}
+
+	for _, line := range req.Items {
+		if line.Quantity < 0 {
+			return order, validationError("quantity cannot be negative")
+		}
+		if seen[line.VariantID] {
+			return order, validationErrorf("variant %s is listed more than once", line.VariantID)
+		}
+		seen[line.VariantID] = true
+
+		item, ok := itemsByVariant[line.VariantID]
+		if !ok {
+			if line.Quantity == 0 {
+				continue
+			}
+			change, err := addOrderLine(ctx, qtx, order, line)
+			if err != nil {
+				return order, err
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		if line.Quantity == item.Quantity {
+			continue
+		}
+
+		if err := adjustStock(ctx, qtx, line.VariantID, line.Quantity-item.Quantity); err != nil {
+			return order, err
+		}
+
+		if line.Quantity == 0 {
+			err = qtx.DeleteOrderItem(ctx, database.DeleteOrderItemParams{
+				OrderID:          order.ID,
+				ProductVariantID: line.VariantID,
+			})
+		} else {
+			_, err = qtx.UpdateOrderItemQuantity(ctx, database.UpdateOrderItemQuantityParams{
+				Quantity:         line.Quantity,
+				OrderID:          order.ID,
+				ProductVariantID: line.VariantID,
+			})
+		}
+		if err != nil {
+			return order, fmt.Errorf("failed to update line %s: %w", line.VariantID, err)
+		}
+
+		changes = append(changes, lineChange{VariantID: line.VariantID, From: item.Quantity, To: line.Quantity})
+	}
+
+	items, err := qtx.GetOrderItemsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load order items: %w", err)
+	}
+	if len(items) == 0 {
+		return order, validationError("an order must keep at least one line")
+	}
+
+	shippingOptionID := order.ShippingOptionID
+	shippingPrice := order.ShippingPrice
+	if req.ShippingMethodID != nil && *req.ShippingMethodID != order.ShippingOptionID {
+		option, err := qtx.SelectShippingOptionById(ctx, *req.ShippingMethodID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return order, validationError("invalid shipping method")
+			}
+			return order, fmt.Errorf("failed to load shipping method: %w", err)
+		}
+		if !option.IsActive {
+			return order, validationError("invalid shipping method")
+		}
+		shippingOptionID = option.ID
+		shippingPrice = option.Price.Convert(order.ExchangeRate)
+	}
+
+	lines, err := loadOrderLines(ctx, qtx, order.ID)
+	if err != nil {
+		return order, err
+	}
+
+	discount, err := cfg.orderDiscount(ctx, qtx, order, lines, shippingPrice)
+	if err != nil {
+		return order, err
+	}
+
+	tax, err := cfg.quoteOrderTax(ctx, qtx, order, order.ShippingCountryID, lines, shippingPrice, discount)
+	if err != nil {
+		return order, err
+	}
+
+	var subtotal money.Amount
+	for _, item := range items {
+		subtotal += item.TotalPrice
+	}
+	totalPrice := subtotal + shippingPrice - discount.total() + tax.added()
+
+	if err := saveOrderTaxLines(ctx, qtx, order.ID, tax); err != nil {
+		return order, err
+	}
+	if err := saveOrderDiscountLines(ctx, qtx, order.ID, discount); err != nil {
+		return order, err
+	}
+
+	if len(changes) == 0 && shippingOptionID == order.ShippingOptionID && totalPrice == order.TotalPrice && tax.Total == order.TaxTotal && discount.total() == order.DiscountTotal {
+		return order, nil
+	}
+
+	updated, err := qtx.UpdateOrderShippingAndTotal(ctx, database.UpdateOrderShippingAndTotalParams{
+		ShippingOptionID: shippingOptionID,
+		ShippingPrice:    shippingPrice,
+		TotalPrice:       totalPrice,
+		TaxTotal:         tax.Total,
+		DiscountTotal:    discount.total(),
+		ID:               order.ID,
+	})
+	if err != nil {
+		return order, fmt.Errorf("failed to update order totals: %w", err)
+	}
+
+	payload := map[string]any{
+		"total_price": fieldChange{From: order.TotalPrice, To: totalPrice},
+	}
+	if tax.Total != order.TaxTotal {
+		payload["tax_total"] = fieldChange{From: order.TaxTotal, To: tax.Total}
+	}
+	if discount.total() != order.DiscountTotal {
+		payload["discount_total"] = fieldChange{From: order.DiscountTotal, To: discount.total()}
+	}
+	if len(changes) > 0 {
+		payload["items"] = changes
+	}
+	if shippingOptionID != order.ShippingOptionID {
+		payload["shipping_option_id"] = fieldChange{From: order.ShippingOptionID, To: shippingOptionID}
+		payload["shipping_price"] = fieldChange{From: order.ShippingPrice, To: shippingPrice}
+	}
+
+	if err := recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeOrderEdited, actor, payload); err != nil {
+		return order, err
+	}
+
+	return updated, nil
+}
+
+// addOrderLine adds a new variant to the order at its current price, converted at
+// the rate the order was placed at.
+func addOrderLine(ctx context.Context, qtx *database.Queries, order database.Order, line OrderEditLine) (lineChange, error) {
+	variant, err := qtx.GetVariantByID(ctx, line.VariantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return lineChange{}, validationErrorf("variant %s not found", line.VariantID)
+		}
+		return lineChange{}, fmt.Errorf("failed to load variant: %w", err)
+	}
+
+	if err := adjustStock(ctx, qtx, variant.ID, line.Quantity); err != nil {
+		return lineChange{}, err
+	}
+
+	price := variant.Price.Convert(order.ExchangeRate)
+	_, err = qtx.AddOrderItem(ctx, database.AddOrderItemParams{
+		OrderID:          order.ID,
+		ProductVariantID: variant.ID,
+		Quantity:         line.Quantity,
+		PricePerItem:     price,
+		TotalPrice:       price.Mul(int64(line.Quantity)),
+	})
+	if err != nil {
+		return lineChange{}, fmt.Errorf("failed to add line %s: %w", variant.ID, err)
+	}
+
+	return lineChange{VariantID: variant.ID, Sku: variant.Sku, From: 0, To: line.Quantity}, nil
+}
+
+// adjustStock reserves stock for a positive delta and returns it for a negative one.
+func adjustStock(ctx context.Context, qtx *database.Queries, variantID uuid.UUID, delta int32) error {
+	if delta > 0 {
+		_, err := qtx.DecreaseVariantStock(ctx, database.DecreaseVariantStockParams{
+			Quantity:  delta,
+			VariantID: variantID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return validationErrorf("insufficient stock for variant %s", variantID)
+			}
+			return fmt.Errorf("failed to reserve stock for variant %s: %w", variantID, err)
+		}
+		return nil
+	}
+
+	if delta < 0 {
+		err := qtx.IncreaseVariantStock(ctx, database.IncreaseVariantStockParams{
+			Quantity:  -delta,
+			VariantID: variantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restock variant %s: %w", variantID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+// orderActor identifies who caused an order event.
+type orderActor struct {
+	Type database.OrderEventActor
+	ID   uuid.UUID
+}
+
+var systemActor = orderActor{Type: database.OrderEventActorSystem}
+
+func userActor(id uuid.UUID) orderActor {
+	return orderActor{Type: database.OrderEventActorUser, ID: id}
+}
+
+func adminActor(id uuid.UUID) orderActor {
+	return orderActor{Type: database.OrderEventActorAdmin, ID: id}
+}
+
+func (a orderActor) nullID() uuid.NullUUID {
+	return uuid.NullUUID{UUID: a.ID, Valid: a.ID != uuid.Nil}
+}
+
+type OrderEventResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	EventType  string          `json:"event_type"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    *uuid.UUID      `json:"actor_id"`
+	ActorEmail string          `json:"actor_email"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// recordOrderEvent appends an event to the order's timeline. payload is marshalled
+// to JSON; pass nil for events that carry no extra data.
+func recordOrderEvent(ctx context.Context, qtx *database.Queries, orderID uuid.UUID, eventType database.OrderEventType, actor orderActor, payload any) error {
+	data := json.RawMessage("{}")
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+		}
+		data = encoded
+	}
+
+	_, err := qtx.CreateOrderEvent(ctx, database.CreateOrderEventParams{
+		OrderID:   orderID,
+		EventType: eventType,
+		ActorType: actor.Type,
+		ActorID:   actor.nullID(),
+		Payload:   data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) getOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]OrderEventResponse, error) {
+	events, err := cfg.db.GetOrderEvents(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]OrderEventResponse, 0, len(events))
+	for _, event := range events {
+		var actorID *uuid.UUID
+		if event.ActorID.Valid {
+			actorID = &event.ActorID.UUID
+		}
+		resp = append(resp, OrderEventResponse{
+			ID:         event.ID,
+			EventType:  string(event.EventType),
+			ActorType:  string(event.ActorType),
+			ActorID:    actorID,
+			ActorEmail: event.ActorEmail.String,
+			Payload:    event.Payload,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
+	"github.com/google/uuid"
+)
+
+var errInvalidStatusTransition = errors.New("invalid status transition")
+
+var errOrderNotExpirable = errors.New("order can no longer expire")
+
+var errOrderPaymentHeld = errors.New("order payment has been captured or authorized")
+
+// validationError is returned from order operations when the request itself is
+// invalid; its message is safe to show to the client.
+type validationError string
+
+func (e validationError) Error() string {
+	return string(e)
+}
+
+func validationErrorf(format string, args ...any) error {
+	return validationError(fmt.Sprintf(format, args...))
+}
+
+// orderStatusTransitions are the moves an admin may make. An order only becomes
+// refunded through syncPaymentStatus, once its ledger shows the money returned.
+var orderStatusTransitions = map[database.OrderStatus][]database.OrderStatus{
+	database.OrderStatusPending:    {database.OrderStatusPaid, database.OrderStatusCancelled},
+	database.OrderStatusPaid:       {database.OrderStatusProcessing, database.OrderStatusCancelled},
+	database.OrderStatusProcessing: {database.OrderStatusShipped, database.OrderStatusCancelled},
+	database.OrderStatusShipped:    {},
+	database.OrderStatusCancelled:  {},
+	database.OrderStatusRefunded:   {},
+}
+
+// refundableOrderStatuses are the statuses syncPaymentStatus moves to refunded
+// when every capture has been refunded.
+var refundableOrderStatuses = []database.OrderStatus{
+	database.OrderStatusPaid,
+	database.OrderStatusProcessing,
+	database.OrderStatusShipped,
+}
+
+var paymentStatusTransitions = map[database.PaymentStatus][]database.PaymentStatus{
+	database.PaymentStatusPending:           {database.PaymentStatusPaid, database.PaymentStatusFailed},
+	database.PaymentStatusFailed:            {database.PaymentStatusPending, database.PaymentStatusPaid},
+	database.PaymentStatusPaid:              {database.PaymentStatusPartiallyRefunded, database.PaymentStatusRefunded},
+	database.PaymentStatusPartiallyRefunded: {database.PaymentStatusRefunded},
+	database.PaymentStatusRefunded:          {},
+}
+
+func isValidOrderStatus(status database.OrderStatus) bool {
+	_, ok := orderStatusTransitions[status]
+	return ok
+}
+
+func isValidPaymentStatus(status database.PaymentStatus) bool {
+	_, ok := paymentStatusTransitions[status]
+	return ok
+}
+
+func canTransitionOrderStatus(from, to database.OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func isRefundableOrderStatus(status database.OrderStatus) bool {
+	for _, refundable := range refundableOrderStatuses {
+		if refundable == status {
+			return true
+		}
+	}
+	return false
+}
+
+func canTransitionPaymentStatus(from, to database.PaymentStatus) bool {
+	for _, allowed := range paymentStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionOrderStatus moves a locked order to a new status and records the change
+// on its timeline.
+// It must be called with a transaction-bound Queries after GetOrderByIdForUpdate.
+func transitionOrderStatus(ctx context.Context, qtx *database.Queries, order database.Order, to database.OrderStatus, actor orderActor) (database.Order, error) {
+	if !canTransitionOrderStatus(order.Status, to) {
+		return order, fmt.Errorf("%w: %s -> %s", errInvalidStatusTransition, order.Status, to)
+	}
+
+	return setOrderStatus(ctx, qtx, order, to, actor)
+}
+
+// setOrderStatus writes a locked order's status and records the change on its
+// timeline, leaving the caller to decide whether the move is allowed.
+func setOrderStatus(ctx context.Context, qtx *database.Queries, order database.Order, to database.OrderStatus, actor orderActor) (database.Order, error) {
+	updated, err := qtx.UpdateOrderStatus(ctx, database.UpdateOrderStatusParams{
+		Status: to,
+		ID:     order.ID,
+	})
+	if err != nil {
+		return order, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeStatusChanged, actor, map[string]any{
+		"from": order.Status,
+		"to":   to,
+	})
+	if err != nil {
+		return order, err
+	}
+
+	return updated, nil
+}
+
+// transitionPaymentStatus is the payment_status counterpart of transitionOrderStatus.
+// Payment status is derived from the ledger, so callers outside syncPaymentStatus
+// should record a payment transaction instead.
+func transitionPaymentStatus(ctx context.Context, qtx *database.Queries, order database.Order, to database.PaymentStatus, actor orderActor) (database.Order, error) {
+	if !canTransitionPaymentStatus(order.PaymentStatus, to) {
+		return order, fmt.Errorf("%w: %s -> %s", errInvalidStatusTransition, order.PaymentStatus, to)
+	}
+
+	updated, err := qtx.UpdateOrderPaymentStatus(ctx, database.UpdateOrderPaymentStatusParams{
+		PaymentStatus: to,
+		ID:            order.ID,
+	})
+	if err != nil {
+		return order, fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypePaymentStatusChanged, actor, map[string]any{
+		"from": order.PaymentStatus,
+		"to":   to,
+	})
+	if err != nil {
+		return order, err
+	}
+
+	return updated, nil
+}
+
+// updateOrderInTx locks the order row and runs fn inside a single transaction.
+// Errors returned by fn roll the transaction back and are passed through unchanged.
+func (cfg *apiConfig) updateOrderInTx(ctx context.Context, orderID uuid.UUID, fn func(qtx *database.Queries, order database.Order) (database.Order, error)) (database.Order, error) {
+	tx, err := cfg.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Order{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.db.WithTx(tx)
+
+	order, err := qtx.GetOrderByIdForUpdate(ctx, orderID)
+	if err != nil {
+		return database.Order{}, err
+	}
+
+	updated, err := fn(qtx, order)
+	if err != nil {
+		return order, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return order, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
+// cancelOrder cancels a locked order, returns every line's quantity to stock and
+// gives back the use of its coupon. Units already restocked by a refund or a
+// received return are not restocked again. An open authorization is queued for
+// a void and captured money for a refund, to be sent by runPaymentOperations
+// once the caller has committed. Cancelling an order that is already cancelled
+// is a no-op.
+func (cfg *apiConfig) cancelOrder(ctx context.Context, qtx *database.Queries, order database.Order, actor orderActor) (database.Order, error) {
+	if order.Status == database.OrderStatusCancelled {
+		return order, nil
+	}
+
+	if !canTransitionOrderStatus(order.Status, database.OrderStatusCancelled) {
+		return order, fmt.Errorf("%w: %s -> %s", errInvalidStatusTransition, order.Status, database.OrderStatusCancelled)
+	}
+
+	order, err := cfg.releaseOrderPayment(ctx, qtx, order, actor)
+	if err != nil {
+		return order, err
+	}
+
+	items, err := qtx.GetOrderItemsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	restockedRows, err := qtx.GetRestockedQuantitiesByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load restocked quantities: %w", err)
+	}
+	restocked := make(map[uuid.UUID]int32, len(restockedRows))
+	for _, row := range restockedRows {
+		restocked[row.ProductVariantID] = row.Quantity
+	}
+
+	for _, item := range items {
+		quantity := item.Quantity - restocked[item.ProductVariantID]
+		if quantity <= 0 {
+			continue
+		}
+
+		err := qtx.IncreaseVariantStock(ctx, database.IncreaseVariantStockParams{
+			Quantity:  quantity,
+			VariantID: item.ProductVariantID,
+		})
+		if err != nil {
+			return order, fmt.Errorf("failed to restock variant %s: %w", item.ProductVariantID, err)
+		}
+	}
+
+	if err := qtx.ReleaseCouponRedemption(ctx, order.ID); err != nil {
+		return order, fmt.Errorf("failed to release coupon: %w", err)
+	}
+
+	return transitionOrderStatus(ctx, qtx, order, database.OrderStatusCancelled, actor)
+}
+
+// releaseOrderPayment queues a void of a locked order's open or in-flight
+// authorization and a full refund of whatever captured money has not been
+// refunded yet. The refund leaves stock alone, as cancelling restocks the order
+// itself.
+func (cfg *apiConfig) releaseOrderPayment(ctx context.Context, qtx *database.Queries, order database.Order, actor orderActor) (database.Order, error) {
+	txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+
+	auth, ok := openAuthorization(txns)
+	if !ok {
+		auth, ok = inFlightAuthorization(txns)
+	}
+	if ok {
+		_, err := queuePaymentOperation(ctx, qtx, order, database.CreatePaymentTransactionParams{
+			Provider:          auth.Provider,
+			Type:              database.PaymentTransactionTypeVoid,
+			Amount:            auth.Amount,
+			Currency:          auth.Currency,
+			ProviderReference: auth.ProviderReference,
+			IdempotencyKey:    sql.NullString{String: "void-" + auth.ID.String(), Valid: true},
+		}, actor)
+		if err != nil {
+			return order, err
+		}
+	}
+
+	captured, refunded := ledgerTotals(txns)
+	if captured-refunded-pendingRefunds(txns) <= 0 {
+		return order, nil
+	}
+
+	order, _, err = cfg.createRefund(ctx, qtx, order, RefundRequest{
+		Full:   true,
+		Reason: "Order cancelled",
+	}, actor)
+	return order, err
+}
+
+// expiringPayment is a payment still in flight for an order about to expire,
+// voided with its provider before the order is cancelled.
+type expiringPayment struct {
+	Authorization database.GetPaymentTransactionsByOrderIdRow
+	Result        payments.Result
+}
+
+// orderExpirable reports whether an order is still unpaid and was placed before
+// the cutoff. Orders with an open authorization or a partial capture are left
+// for an admin.
+func orderExpirable(order database.Order, txns []database.GetPaymentTransactionsByOrderIdRow, cutoff time.Time) bool {
+	unpaid := order.PaymentStatus == database.PaymentStatusPending || order.PaymentStatus == database.PaymentStatusFailed
+	if order.Status != database.OrderStatusPending || !unpaid || !order.CreatedAt.Before(cutoff) {
+		return false
+	}
+	if _, ok := openAuthorization(txns); ok {
+		return false
+	}
+	// A partial capture leaves the order pending, but money has been taken.
+	_, captured := lastCapture(txns)
+	return !captured
+}
+
+// voidExpiringPayment voids the payment still in flight for an order that is due
+// to expire, so it cannot complete once the order is cancelled. It runs outside
+// any transaction; the void is sent under a key derived from the authorization,
+// so voiding again after a failed cancellation is harmless. It returns nil when
+// there is nothing to void.
+func (cfg *apiConfig) voidExpiringPayment(ctx context.Context, orderID uuid.UUID, cutoff time.Time) (*expiringPayment, error) {
+	order, err := cfg.db.GetOrderById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := cfg.db.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+	if !orderExpirable(order, txns, cutoff) {
+		return nil, errOrderNotExpirable
+	}
+
+	auth, ok := inFlightAuthorization(txns)
+	if !ok {
+		return nil, nil
+	}
+
+	provider, err := cfg.orderPaymentProvider(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := provider.Void(ctx, auth.ProviderReference.String, "void-"+auth.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: void failed: %v", errPaymentProvider, err)
+	}
+	return &expiringPayment{Authorization: auth, Result: result}, nil
+}
+
+// expireOrder cancels a locked order that is still unpaid and was placed before the
+// cutoff, recording the expiry on its timeline. A payment still in flight must
+// have been voided by voidExpiringPayment first; the void is recorded on the
+// ledger. If the payment has moved on since, the order is left for the next run.
+func (cfg *apiConfig) expireOrder(ctx context.Context, qtx *database.Queries, order database.Order, cutoff time.Time, voided *expiringPayment) (database.Order, error) {
+	txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+	if !orderExpirable(order, txns, cutoff) {
+		return order, errOrderNotExpirable
+	}
+	auth, inFlight := inFlightAuthorization(txns)
+	if inFlight && (voided == nil || voided.Authorization.ID != auth.ID) {
+		return order, errOrderNotExpirable
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeExpired, systemActor, map[string]any{
+		"timeout_minutes": int(cfg.unpaidOrderTimeout.Minutes()),
+	})
+	if err != nil {
+		return order, err
+	}
+
+	if inFlight {
+		order, err = recordPaymentTransaction(ctx, qtx, order, database.CreatePaymentTransactionParams{
+			Provider:          auth.Provider,
+			Type:              database.PaymentTransactionTypeVoid,
+			Status:            database.PaymentTransactionStatusSucceeded,
+			Amount:            auth.Amount,
+			Currency:          auth.Currency,
+			ProviderReference: auth.ProviderReference,
+			RawResponse:       voided.Result.Raw,
+		}, systemActor)
+		if err != nil {
+			return order, err
+		}
+	}
+
+	return cfg.cancelOrder(ctx, qtx, order, systemActor)
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
+)
+
+// errPaymentProvider wraps failures reported by a payment provider, as opposed to
+// errors in the shop itself.
+var errPaymentProvider = errors.New("payment provider error")
+
+type CheckoutPayment struct {
+	Provider   string              `json:"provider"`
+	Reference  string              `json:"reference,omitempty"`
+	Status     string              `json:"status"`
+	NextAction payments.NextAction `json:"next_action"`
+	Error      string              `json:"error,omitempty"`
+}
+
+func (cfg *apiConfig) paymentProvider(option database.PaymentOption) (payments.Provider, error) {
+	return cfg.payments.New(option.Provider, option.ProviderConfig)
+}
+
+// orderPaymentProvider builds the provider an order was paid with, using its payment
+// option's config while the option still points at that provider. Orders placed
+// before providers existed are treated as manual payments.
+func (cfg *apiConfig) orderPaymentProvider(ctx context.Context, order database.Order) (payments.Provider, error) {
+	key := payments.ManualKey
+	if order.PaymentProvider.Valid {
+		key = order.PaymentProvider.String
+	}
+
+	option, err := cfg.db.GetPaymentOptionById(ctx, order.PaymentOptionID)
+	if err == nil && option.Provider == key {
+		return cfg.paymentProvider(option)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load payment option: %w", err)
+	}
+
+	return cfg.payments.New(key, nil)
+}
+
+// ledgerEntryFor maps a provider status to the kind of ledger entry it records.
+func ledgerEntryFor(status payments.Status) (database.PaymentTransactionType, database.PaymentTransactionStatus) {
+	switch status {
+	case payments.StatusAuthorized:
+		return database.PaymentTransactionTypeAuthorization, database.PaymentTransactionStatusSucceeded
+	case payments.StatusCaptured:
+		return database.PaymentTransactionTypeCapture, database.PaymentTransactionStatusSucceeded
+	case payments.StatusVoided:
+		return database.PaymentTransactionTypeVoid, database.PaymentTransactionStatusSucceeded
+	case payments.StatusRefunded:
+		return database.PaymentTransactionTypeRefund, database.PaymentTransactionStatusSucceeded
+	case payments.StatusFailed:
+		return database.PaymentTransactionTypeAuthorization, database.PaymentTransactionStatusFailed
+	default:
+		return database.PaymentTransactionTypeAuthorization, database.PaymentTransactionStatusPending
+	}
+}
+
+// derivePaymentStatus computes an order's payment status from its ledger, which
+// must be in chronological order. An order is only paid once the captures cover
+// its total; a partial capture leaves it pending.
+func derivePaymentStatus(txns []database.GetPaymentTransactionsByOrderIdRow, total money.Amount) database.PaymentStatus {
+	captured, refunded := ledgerTotals(txns)
+	lastAttempt := database.PaymentTransactionStatusPending
+	for _, txn := range txns {
+		switch txn.Type {
+		case database.PaymentTransactionTypeAuthorization, database.PaymentTransactionTypeCapture:
+			lastAttempt = txn.Status
+		}
+	}
+
+	switch {
+	case captured > 0 && refunded >= captured:
+		return database.PaymentStatusRefunded
+	case captured > 0 && refunded > 0:
+		return database.PaymentStatusPartiallyRefunded
+	case captured > 0 && captured >= total:
+		return database.PaymentStatusPaid
+	case lastAttempt == database.PaymentTransactionStatusFailed:
+		return database.PaymentStatusFailed
+	default:
+		return database.PaymentStatusPending
+	}
+}
+
+// ledgerTotals adds up the succeeded captures and refunds on the ledger.
+func ledgerTotals(txns []database.GetPaymentTransactionsByOrderIdRow) (captured, refunded money.Amount) {
+	for _, txn := range txns {
+		if txn.Status != database.PaymentTransactionStatusSucceeded {
+			continue
+		}
+		switch txn.Type {
+		case database.PaymentTransactionTypeCapture:
+			captured += txn.Amount
+		case database.PaymentTransactionTypeRefund:
+			refunded += txn.Amount
+		}
+	}
+	return captured, refunded
+}
+
+// pendingRefunds adds up the refunds queued on the ledger that the provider has
+// not confirmed yet.
+func pendingRefunds(txns []database.GetPaymentTransactionsByOrderIdRow) money.Amount {
+	var pending money.Amount
+	for _, txn := range txns {
+		if txn.Type == database.PaymentTransactionTypeRefund && txn.Status == database.PaymentTransactionStatusPending {
+			pending += txn.Amount
+		}
+	}
+	return pending
+}
+
+// pendingOperation returns the oldest queued void, or queued refund of the given
+// amount, that a provider event may be reporting back.
+func pendingOperation(txns []database.GetPaymentTransactionsByOrderIdRow, txnType database.PaymentTransactionType, amount money.Amount) (database.GetPaymentTransactionsByOrderIdRow, bool) {
+	for _, txn := range txns {
+		if txn.Type != txnType || txn.Status != database.PaymentTransactionStatusPending {
+			continue
+		}
+		if txnType == database.PaymentTransactionTypeRefund && txn.Amount != amount {
+			continue
+		}
+		return txn, true
+	}
+	return database.GetPaymentTransactionsByOrderIdRow{}, false
+}
+
+// hasLedgerEntry reports whether the ledger already holds a successful entry of
+// the given type under the provider reference.
+func hasLedgerEntry(txns []database.GetPaymentTransactionsByOrderIdRow, txnType database.PaymentTransactionType, reference sql.NullString) bool {
+	if !reference.Valid {
+		return false
+	}
+	for _, txn := range txns {
+		if txn.Type == txnType && txn.Status == database.PaymentTransactionStatusSucceeded && txn.ProviderReference == reference {
+			return true
+		}
+	}
+	return false
+}
+
+// refundReference is the reference a refund is recorded under: the provider's own
+// ID for the refund where it has one, otherwise the payment's.
+func refundReference(result payments.Result, payment sql.NullString) sql.NullString {
+	if result.TransactionReference != "" {
+		return sql.NullString{String: result.TransactionReference, Valid: true}
+	}
+	return payment
+}
+
+// lastCapture returns the most recent successful capture in the ledger.
+func lastCapture(txns []database.GetPaymentTransactionsByOrderIdRow) (database.GetPaymentTransactionsByOrderIdRow, bool) {
+	for i := len(txns) - 1; i >= 0; i-- {
+		if txns[i].Type == database.PaymentTransactionTypeCapture && txns[i].Status == database.PaymentTransactionStatusSucceeded {
+			return txns[i], true
+		}
+	}
+	return database.GetPaymentTransactionsByOrderIdRow{}, false
+}
+
+// openAuthorization returns the latest successful authorization that has not been
+// captured or voided since.
+func openAuthorization(txns []database.GetPaymentTransactionsByOrderIdRow) (database.GetPaymentTransactionsByOrderIdRow, bool) {
+	for i := len(txns) - 1; i >= 0; i-- {
+		txn := txns[i]
+		if txn.Status != database.PaymentTransactionStatusSucceeded {
+			continue
+		}
+		switch txn.Type {
+		case database.PaymentTransactionTypeAuthorization:
+			return txn, true
+		case database.PaymentTransactionTypeCapture, database.PaymentTransactionTypeVoid:
+			return database.GetPaymentTransactionsByOrderIdRow{}, false
+		}
+	}
+	return database.GetPaymentTransactionsByOrderIdRow{}, false
+}
+
+// inFlightAuthorization returns the authorization a provider is still working
+// on, such as a card payment waiting for 3-D Secure, when it is the latest entry
+// on the ledger.
+func inFlightAuthorization(txns []database.GetPaymentTransactionsByOrderIdRow) (database.GetPaymentTransactionsByOrderIdRow, bool) {
+	if len(txns) == 0 {
+		return database.GetPaymentTransactionsByOrderIdRow{}, false
+	}
+	last := txns[len(txns)-1]
+	if last.Type != database.PaymentTransactionTypeAuthorization || last.Status != database.PaymentTransactionStatusPending || !last.ProviderReference.Valid {
+		return database.GetPaymentTransactionsByOrderIdRow{}, false
+	}
+	return last, true
+}
+
+// paymentHeld reports whether a locked order has money captured or authorized
+// that the shop would have to give back before the order can be dropped.
+func paymentHeld(ctx context.Context, qtx *database.Queries, order database.Order) (bool, error) {
+	switch order.PaymentStatus {
+	case database.PaymentStatusPaid, database.PaymentStatusPartiallyRefunded:
+		return true, nil
+	}
+
+	txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+	_, ok := openAuthorization(txns)
+	return ok, nil
+}
+
+// recordPaymentTransaction appends a payment action to a locked order's ledger and
+// brings the order's payment status in line with it.
+func recordPaymentTransaction(ctx context.Context, qtx *database.Queries, order database.Order, params database.CreatePaymentTransactionParams, actor orderActor) (database.Order, error) {
+	params.OrderID = order.ID
+	params.CreatedBy = actor.nullID()
+	if len(params.RawResponse) == 0 {
+		params.RawResponse = json.RawMessage("{}")
+	}
+
+	if _, err := qtx.CreatePaymentTransaction(ctx, params); err != nil {
+		return order, fmt.Errorf("failed to record payment transaction: %w", err)
+	}
+
+	return syncPaymentStatus(ctx, qtx, order, actor)
+}
+
+// syncPaymentStatus sets a locked order's payment status to the one derived from
+// its ledger. A newly paid order that is still pending becomes paid, and a fully
+// refunded order becomes refunded where its status allows it.
+func syncPaymentStatus(ctx context.Context, qtx *database.Queries, order database.Order, actor orderActor) (database.Order, error) {
+	txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+
+	status := derivePaymentStatus(txns, order.TotalPrice)
+	if status != order.PaymentStatus {
+		order, err = transitionPaymentStatus(ctx, qtx, order, status, actor)
+		if err != nil {
+			return order, err
+		}
+	}
+
+	switch {
+	case status == database.PaymentStatusPaid && order.Status == database.OrderStatusPending:
+		return transitionOrderStatus(ctx, qtx, order, database.OrderStatusPaid, actor)
+	case status == database.PaymentStatusRefunded && isRefundableOrderStatus(order.Status):
+		return setOrderStatus(ctx, qtx, order, database.OrderStatusRefunded, actor)
+	}
+
+	return order, nil
+}
+
+// startPayment asks the order's provider to authorize the payment, stores the
+// provider reference and records the attempt on the payment ledger. It runs after
+// the order is committed, so a provider failure leaves the order pending rather
+// than losing it.
+func (cfg *apiConfig) startPayment(ctx context.Context, order database.Order, provider payments.Provider, returnURL string) (database.Order, CheckoutPayment) {
+	payment := CheckoutPayment{
+		Provider:   provider.Key(),
+		Status:     string(payments.StatusPending),
+		NextAction: payments.NextAction{Type: payments.ActionNone},
+	}
+
+	result, authErr := provider.Authorize(ctx, payments.Order{
+		ID:            order.ID,
+		Number:        order.OrderNumber,
+		Amount:        order.TotalPrice,
+		Currency:      cfg.orderExchangeRate(order).Currency,
+		CustomerEmail: order.CustomerEmail,
+		ReturnURL:     returnURL,
+	})
+	switch {
+	case errors.Is(authErr, payments.ErrDeclined):
+		payment.Error = "Payment was declined"
+	case authErr != nil:
+		log.Printf("Payment authorization error for order %s: %v", order.ID, authErr)
+		payment.Error = "Payment could not be started"
+		result = payments.Result{Status: payments.StatusFailed}
+	}
+
+	payment.Reference = result.Reference
+	payment.Status = string(result.Status)
+	payment.NextAction = result.NextAction
+	if payment.NextAction.Type == "" {
+		payment.NextAction.Type = payments.ActionNone
+	}
+
+	amount := result.Amount
+	if amount == 0 {
+		amount = order.TotalPrice
+	}
+	txnType, txnStatus := ledgerEntryFor(result.Status)
+	errorMessage := ""
+	if authErr != nil {
+		errorMessage = authErr.Error()
+	}
+
+	updated, err := cfg.updateOrderInTx(ctx, order.ID, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		order, err := qtx.UpdateOrderPaymentReference(ctx, database.UpdateOrderPaymentReferenceParams{
+			PaymentProvider:  sql.NullString{String: provider.Key(), Valid: true},
+			PaymentReference: sql.NullString{String: result.Reference, Valid: result.Reference != ""},
+			ID:               order.ID,
+		})
+		if err != nil {
+			return order, err
+		}
+
+		return recordPaymentTransaction(ctx, qtx, order, database.CreatePaymentTransactionParams{
+			Provider:          provider.Key(),
+			Type:              txnType,
+			Status:            txnStatus,
+			Amount:            amount,
+			Currency:          cfg.orderExchangeRate(order).Currency,
+			ProviderReference: sql.NullString{String: result.Reference, Valid: result.Reference != ""},
+			RawResponse:       result.Raw,
+			ErrorMessage:      sql.NullString{String: errorMessage, Valid: errorMessage != ""},
+		}, systemActor)
+	})
+	if err != nil {
+		log.Printf("Failed to record payment for order %s: %v", order.ID, err)
+		return order, payment
+	}
+
+	return updated, payment
+}
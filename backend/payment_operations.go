@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/payments"
+	"github.com/google/uuid"
+)
+
+// paymentOperationRetryDelay is how long a queued void or refund may stay pending
+// before the worker sends it again.
+const paymentOperationRetryDelay = 5 * time.Minute
+
+var errPaymentPending = errors.New("payment operation is still pending")
+
+// queuePaymentOperation puts a void or refund on a locked order's ledger as
+// pending. It is sent to the provider by runPaymentOperations once the caller's
+// transaction has committed, so the provider is never asked to move money for
+// work that was rolled back. The idempotency key is sent with every attempt, so
+// an operation resent after a crash or timeout is carried out once.
+func queuePaymentOperation(ctx context.Context, qtx *database.Queries, order database.Order, params database.CreatePaymentTransactionParams, actor orderActor) (database.PaymentTransaction, error) {
+	params.OrderID = order.ID
+	params.Status = database.PaymentTransactionStatusPending
+	params.RawResponse = json.RawMessage("{}")
+	params.CreatedBy = actor.nullID()
+
+	txn, err := qtx.CreatePaymentTransaction(ctx, params)
+	if err != nil {
+		return txn, fmt.Errorf("failed to queue %s: %w", params.Type, err)
+	}
+	return txn, nil
+}
+
+// runPaymentOperations sends an order's queued voids and refunds to its provider.
+// It returns the order as it stands after the last one settled, and the first
+// error, which wraps errPaymentPending when an operation has to be retried.
+func (cfg *apiConfig) runPaymentOperations(ctx context.Context, orderID uuid.UUID) (database.Order, error) {
+	txns, err := cfg.db.GetPendingPaymentOperationsByOrderId(ctx, orderID)
+	if err != nil {
+		return database.Order{}, fmt.Errorf("failed to load pending payment operations: %w", err)
+	}
+
+	order, err := cfg.db.GetOrderById(ctx, orderID)
+	if err != nil {
+		return order, err
+	}
+
+	var firstErr error
+	for _, txn := range txns {
+		updated, err := cfg.runPaymentOperation(ctx, txn)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		order = updated
+	}
+	return order, firstErr
+}
+
+// runPaymentOperation sends one queued void or refund and settles it on the
+// ledger. A provider error that may not be final leaves it pending for the
+// worker to resend under the same idempotency key.
+func (cfg *apiConfig) runPaymentOperation(ctx context.Context, txn database.PaymentTransaction) (database.Order, error) {
+	order, err := cfg.db.GetOrderById(ctx, txn.OrderID)
+	if err != nil {
+		return order, err
+	}
+
+	provider, err := cfg.orderPaymentProvider(ctx, order)
+	if err != nil {
+		return order, fmt.Errorf("%w: %v", errPaymentPending, err)
+	}
+
+	var result payments.Result
+	switch txn.Type {
+	case database.PaymentTransactionTypeVoid:
+		result, err = provider.Void(ctx, txn.ProviderReference.String, txn.IdempotencyKey.String)
+	case database.PaymentTransactionTypeRefund:
+		result, err = provider.Refund(ctx, txn.ProviderReference.String, txn.Amount, txn.IdempotencyKey.String)
+	default:
+		return order, fmt.Errorf("cannot send a queued %s", txn.Type)
+	}
+	if err != nil && !payments.IsFinal(err) {
+		return order, fmt.Errorf("%w: %s: %v", errPaymentPending, txn.Type, err)
+	}
+
+	params := database.SettlePaymentTransactionParams{
+		ID:          txn.ID,
+		Status:      database.PaymentTransactionStatusSucceeded,
+		RawResponse: result.Raw,
+	}
+	if result.TransactionReference != "" {
+		params.ProviderReference = sql.NullString{String: result.TransactionReference, Valid: true}
+	}
+	if err != nil {
+		params.Status = database.PaymentTransactionStatusFailed
+		params.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+	}
+
+	updated, settleErr := cfg.updateOrderInTx(ctx, order.ID, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+		return settlePaymentOperation(ctx, qtx, order, params)
+	})
+	if settleErr != nil {
+		return order, settleErr
+	}
+	if err != nil {
+		return updated, fmt.Errorf("%w: %s failed: %v", errPaymentProvider, txn.Type, err)
+	}
+	return updated, nil
+}
+
+// settlePaymentOperation records the outcome of a queued void or refund on a
+// locked order. An operation that has already settled, for instance because the
+// provider's webhook arrived first, is left alone.
+func settlePaymentOperation(ctx context.Context, qtx *database.Queries, order database.Order, params database.SettlePaymentTransactionParams) (database.Order, error) {
+	if len(params.RawResponse) == 0 {
+		params.RawResponse = json.RawMessage("{}")
+	}
+
+	txn, err := qtx.SettlePaymentTransaction(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return order, nil
+		}
+		return order, fmt.Errorf("failed to settle payment transaction: %w", err)
+	}
+
+	if txn.Type == database.PaymentTransactionTypeRefund {
+		if err := finishRefund(ctx, qtx, order, txn); err != nil {
+			return order, err
+		}
+	}
+
+	return syncPaymentStatus(ctx, qtx, order, systemActor)
+}
+
+// finishRefund restocks a refund's lines once its payment has gone through. A
+// failed refund no longer counts as restocking anything, so its lines are only
+// put back if the order was cancelled meanwhile, as cancelling skipped them.
+func finishRefund(ctx context.Context, qtx *database.Queries, order database.Order, txn database.PaymentTransaction) error {
+	refund, err := qtx.GetRefundByPaymentTransactionId(ctx, uuid.NullUUID{UUID: txn.ID, Valid: true})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to load refund: %w", err)
+	}
+
+	if !refund.Restock {
+		return nil
+	}
+	if txn.Status != database.PaymentTransactionStatusSucceeded && order.Status != database.OrderStatusCancelled {
+		return nil
+	}
+
+	variants, err := qtx.GetRefundVariantsByOrderId(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load refund lines: %w", err)
+	}
+	for _, v := range variants {
+		if v.RefundID != refund.ID {
+			continue
+		}
+		err := qtx.IncreaseVariantStock(ctx, database.IncreaseVariantStockParams{
+			Quantity:  v.Quantity,
+			VariantID: v.ProductVariantID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restock variant %s: %w", v.ProductVariantID, err)
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"time"
 
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -17,13 +18,13 @@ type Product struct {
 }
 
 type Variant struct {
-	ID            uuid.UUID `json:"id"`
-	Name          string    `json:"name"`
-	ProductID     uuid.UUID `json:"productId"`
-	Price         float64   `json:"price"`
-	StockQuantity int32     `json:"stockQuantity"`
-	ImageUrl      string    `json:"imageUrl"`
-	VariantName   string    `json:"variantName"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uuid.UUID    `json:"id"`
+	Name          string       `json:"name"`
+	ProductID     uuid.UUID    `json:"productId"`
+	Price         money.Amount `json:"price"`
+	StockQuantity int32        `json:"stockQuantity"`
+	ImageUrl      string       `json:"imageUrl"`
+	VariantName   string       `json:"variantName"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
 }
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+// promotionTargets is a set of products and categories. An empty set covers
+// every line.
+type promotionTargets struct {
+	products   map[uuid.UUID]bool
+	categories map[uuid.UUID]bool
+}
+
+func (t promotionTargets) isEmpty() bool {
+	return len(t.products) == 0 && len(t.categories) == 0
+}
+
+func (t promotionTargets) covers(line pricedLine) bool {
+	return t.isEmpty() || t.products[line.ProductID] || t.categories[line.CategoryID]
+}
+
+// promotionRules is a promotion with what it targets. A buy_x_get_y promotion
+// without get targets rewards items from its buy targets.
+type promotionRules struct {
+	promotion database.Promotion
+	buy       promotionTargets
+	get       promotionTargets
+	bundle    map[uuid.UUID]int32
+}
+
+func loadPromotionRules(ctx context.Context, q *database.Queries, promotion database.Promotion) (promotionRules, error) {
+	products, err := q.GetPromotionProducts(ctx, promotion.ID)
+	if err != nil {
+		return promotionRules{}, fmt.Errorf("failed to load promotion products: %w", err)
+	}
+	categories, err := q.GetPromotionCategories(ctx, promotion.ID)
+	if err != nil {
+		return promotionRules{}, fmt.Errorf("failed to load promotion categories: %w", err)
+	}
+
+	rules := promotionRules{
+		promotion: promotion,
+		buy:       promotionTargets{products: map[uuid.UUID]bool{}, categories: map[uuid.UUID]bool{}},
+		get:       promotionTargets{products: map[uuid.UUID]bool{}, categories: map[uuid.UUID]bool{}},
+		bundle:    map[uuid.UUID]int32{},
+	}
+	for _, p := range products {
+		if p.Role == database.PromotionTargetRoleGet {
+			rules.get.products[p.ProductID] = true
+			continue
+		}
+		rules.buy.products[p.ProductID] = true
+		rules.bundle[p.ProductID] = p.Quantity
+	}
+	for _, c := range categories {
+		if c.Role == database.PromotionTargetRoleGet {
+			rules.get.categories[c.CategoryID] = true
+			continue
+		}
+		rules.buy.categories[c.CategoryID] = true
+	}
+	return rules, nil
+}
+
+// promotionCart tracks what is left of each line while promotions apply, so a
+// unit counts towards one deal only and no line is discounted below zero.
+type promotionCart struct {
+	lines     []pricedLine
+	remaining []money.Amount
+	used      []int32
+}
+
+type promotionUnit struct {
+	line  int
+	price money.Amount
+}
+
+func newPromotionCart(lines []pricedLine) *promotionCart {
+	c := &promotionCart{
+		lines:     lines,
+		remaining: make([]money.Amount, len(lines)),
+		used:      make([]int32, len(lines)),
+	}
+	for i, line := range lines {
+		c.remaining[i] = line.Amount
+	}
+	return c
+}
+
+// units lists the units of covered lines not yet taken by a deal, most
+// expensive first.
+func (c *promotionCart) units(covers func(pricedLine) bool) []promotionUnit {
+	var units []promotionUnit
+	for i, line := range c.lines {
+		if !covers(line) {
+			continue
+		}
+		for n := c.used[i]; n < line.Quantity; n++ {
+			units = append(units, promotionUnit{line: i, price: line.UnitPrice})
+		}
+	}
+	sort.SliceStable(units, func(a, b int) bool {
+		return units[a].price > units[b].price
+	})
+	return units
+}
+
+func (c *promotionCart) take(units []promotionUnit) {
+	for _, u := range units {
+		c.used[u.line]++
+	}
+}
+
+// value is what is left of the covered lines.
+func (c *promotionCart) value(covers func(pricedLine) bool) money.Amount {
+	var total money.Amount
+	for i, line := range c.lines {
+		if covers(line) {
+			total += c.remaining[i]
+		}
+	}
+	return total
+}
+
+// evaluate applies one promotion and returns each line's discount and how
+// many times the deal applied. Amounts in the store currency are converted at
+// rate.
+func (c *promotionCart) evaluate(rules promotionRules, rate exchangeRate) ([]money.Amount, int) {
+	p := rules.promotion
+	shares := make([]money.Amount, len(c.lines))
+
+	if c.value(rules.buy.covers) < rate.fromBase(p.MinSubtotal) {
+		return shares, 0
+	}
+
+	times := 0
+	switch p.Type {
+	case database.PromotionTypeBuyXGetY:
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return shares, 0
+		}
+
+		if rules.get.isEmpty() {
+			// Every group of buy+get units has its cheapest get units
+			// discounted.
+			units := c.units(rules.buy.covers)
+			size := int(p.BuyQuantity + p.GetQuantity)
+			for ; (times+1)*size <= len(units); times++ {
+				group := units[times*size : (times+1)*size]
+				c.take(group)
+				for _, u := range group[p.BuyQuantity:] {
+					shares[u.line] += u.price.Percent(p.PercentOff)
+				}
+			}
+			break
+		}
+
+		// Buying from one set rewards the cheapest units of the other.
+		saved := slices.Clone(c.used)
+		buyUnits := c.units(rules.buy.covers)
+		groups := len(buyUnits) / int(p.BuyQuantity)
+		c.take(buyUnits[:groups*int(p.BuyQuantity)])
+
+		getUnits := c.units(rules.get.covers)
+		slices.Reverse(getUnits)
+		rewarded := min(groups*int(p.GetQuantity), len(getUnits))
+		if rewarded == 0 {
+			c.used = saved
+			return shares, 0
+		}
+		times = (rewarded + int(p.GetQuantity) - 1) / int(p.GetQuantity)
+		c.used = saved
+		c.take(buyUnits[:times*int(p.BuyQuantity)])
+		c.take(getUnits[:rewarded])
+		for _, u := range getUnits[:rewarded] {
+			shares[u.line] += u.price.Percent(p.PercentOff)
+		}
+
+	case database.PromotionTypeSpendThreshold:
+		var weights []money.Amount
+		for i, line := range c.lines {
+			if rules.buy.covers(line) {
+				weights = append(weights, c.remaining[i])
+			} else {
+				weights = append(weights, 0)
+			}
+		}
+		eligible := c.value(rules.buy.covers)
+		if !eligible.IsPositive() {
+			return shares, 0
+		}
+
+		amount := eligible.Percent(p.PercentOff)
+		if p.PercentOff.IsZero() {
+			amount = money.Min(rate.fromBase(p.AmountOff), eligible)
+		}
+		shares = spreadDiscount(amount, weights)
+		times = 1
+
+	case database.PromotionTypeBundlePrice:
+		if len(rules.bundle) == 0 {
+			return shares, 0
+		}
+
+		ids := make([]uuid.UUID, 0, len(rules.bundle))
+		for id := range rules.bundle {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(a, b int) bool { return ids[a].String() < ids[b].String() })
+
+		byProduct := map[uuid.UUID][]promotionUnit{}
+		for _, u := range c.units(func(line pricedLine) bool { return rules.bundle[line.ProductID] > 0 }) {
+			id := c.lines[u.line].ProductID
+			byProduct[id] = append(byProduct[id], u)
+		}
+
+		price := rate.fromBase(p.BundlePrice)
+		for {
+			var bundle []promotionUnit
+			for _, id := range ids {
+				need := int(rules.bundle[id])
+				start := times * need
+				if start+need > len(byProduct[id]) {
+					bundle = nil
+					break
+				}
+				bundle = append(bundle, byProduct[id][start:start+need]...)
+			}
+			if bundle == nil {
+				break
+			}
+
+			var value money.Amount
+			weights := make([]money.Amount, len(bundle))
+			for i, u := range bundle {
+				value += u.price
+				weights[i] = u.price
+			}
+			if value <= price {
+				break
+			}
+
+			c.take(bundle)
+			for i, share := range spreadDiscount(value-price, weights) {
+				shares[bundle[i].line] += share
+			}
+			times++
+		}
+	}
+
+	// Earlier promotions may have left less of a line than this one takes.
+	for i := range shares {
+		shares[i] = money.Min(shares[i], c.remaining[i])
+		c.remaining[i] -= shares[i]
+	}
+	return shares, times
+}
+
+// explainPromotion describes the rule that applied, in the cart's currency.
+func explainPromotion(p database.Promotion, rate exchangeRate, times int) string {
+	var text string
+	switch p.Type {
+	case database.PromotionTypeBuyXGetY:
+		if p.PercentOff == money.HundredPercent {
+			text = fmt.Sprintf("Buy %d, get %d free", p.BuyQuantity, p.GetQuantity)
+		} else {
+			text = fmt.Sprintf("Buy %d, get %d at %s%% off", p.BuyQuantity, p.GetQuantity, p.PercentOff)
+		}
+	case database.PromotionTypeSpendThreshold:
+		if p.PercentOff.IsZero() {
+			text = fmt.Sprintf("%s %s off", rate.fromBase(p.AmountOff), rate.Currency)
+		} else {
+			text = fmt.Sprintf("%s%% off", p.PercentOff)
+		}
+	case database.PromotionTypeBundlePrice:
+		text = fmt.Sprintf("Bundle for %s %s", rate.fromBase(p.BundlePrice), rate.Currency)
+	}
+
+	if !p.MinSubtotal.IsZero() {
+		text += fmt.Sprintf(" when you spend %s %s", rate.fromBase(p.MinSubtotal), rate.Currency)
+	}
+	if times > 1 {
+		text += fmt.Sprintf(" (applied %d times)", times)
+	}
+	return text
+}
+
+// applyPromotions runs the promotions over the lines in order. Each works on
+// what the ones before it left, and only those that took something off are
+// listed.
+func applyPromotions(rules []promotionRules, lines []pricedLine, rate exchangeRate) discountQuote {
+	discount := discountQuote{Items: map[uuid.UUID]money.Amount{}}
+	cart := newPromotionCart(lines)
+
+	for _, r := range rules {
+		shares, times := cart.evaluate(r, rate)
+
+		var amount money.Amount
+		for i, share := range shares {
+			discount.Items[lines[i].VariantID] += share
+			amount += share
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+
+		id := r.promotion.ID
+		discount.Goods += amount
+		discount.Lines = append(discount.Lines, DiscountLine{
+			PromotionID: &id,
+			Name:        r.promotion.Name,
+			Explanation: explainPromotion(r.promotion, rate, times),
+			Amount:      amount,
+		})
+	}
+
+	return discount
+}
+
+// cartPromotions applies the promotions running now to a cart's lines.
+func (cfg *apiConfig) cartPromotions(ctx context.Context, q *database.Queries, lines []pricedLine, rate exchangeRate) (discountQuote, error) {
+	if len(lines) == 0 {
+		return discountQuote{}, nil
+	}
+
+	promotions, err := q.GetActivePromotions(ctx, time.Now().UTC())
+	if err != nil {
+		return discountQuote{}, fmt.Errorf("failed to load promotions: %w", err)
+	}
+
+	rules := make([]promotionRules, 0, len(promotions))
+	for _, promotion := range promotions {
+		r, err := loadPromotionRules(ctx, q, promotion)
+		if err != nil {
+			return discountQuote{}, err
+		}
+		rules = append(rules, r)
+	}
+
+	return applyPromotions(rules, lines, rate), nil
+}
+
+// orderPromotions reapplies the promotions an order received to its current
+// lines, in the order they first applied. As with coupons, dates no longer
+// matter; promotions deleted since are dropped.
+func (cfg *apiConfig) orderPromotions(ctx context.Context, q *database.Queries, order database.Order, lines []pricedLine) (discountQuote, error) {
+	stored, err := q.GetOrderDiscountLines(ctx, order.ID)
+	if err != nil {
+		return discountQuote{}, fmt.Errorf("failed to load discount lines: %w", err)
+	}
+
+	var rules []promotionRules
+	for _, line := range stored {
+		if !line.PromotionID.Valid {
+			continue
+		}
+
+		promotion, err := q.GetPromotionById(ctx, line.PromotionID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return discountQuote{}, fmt.Errorf("failed to load promotion: %w", err)
+		}
+
+		r, err := loadPromotionRules(ctx, q, promotion)
+		if err != nil {
+			return discountQuote{}, err
+		}
+		rules = append(rules, r)
+	}
+
+	return applyPromotions(rules, lines, cfg.orderExchangeRate(order)), nil
+}
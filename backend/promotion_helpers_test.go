@@ -0,0 +1,215 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+var (
+	testProductA = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	testProductB = uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	testProductC = uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+)
+
+func testLine(product uuid.UUID, quantity int32, unitPrice money.Amount) pricedLine {
+	return pricedLine{
+		VariantID: uuid.New(),
+		ProductID: product,
+		Quantity:  quantity,
+		UnitPrice: unitPrice,
+		Amount:    unitPrice.Mul(int64(quantity)),
+	}
+}
+
+func testTargets(products ...uuid.UUID) promotionTargets {
+	t := promotionTargets{products: map[uuid.UUID]bool{}, categories: map[uuid.UUID]bool{}}
+	for _, id := range products {
+		t.products[id] = true
+	}
+	return t
+}
+
+func testPromotion(p database.Promotion, buy, get promotionTargets, bundle map[uuid.UUID]int32) promotionRules {
+	p.ID = uuid.New()
+	p.Name = string(p.Type)
+	return promotionRules{promotion: p, buy: buy, get: get, bundle: bundle}
+}
+
+func threeForTwo() promotionRules {
+	return testPromotion(database.Promotion{
+		Type:        database.PromotionTypeBuyXGetY,
+		BuyQuantity: 2,
+		GetQuantity: 1,
+		PercentOff:  money.HundredPercent,
+	}, testTargets(), testTargets(), nil)
+}
+
+func spendThreshold(minSubtotal money.Amount, percentOff money.Percent) promotionRules {
+	return testPromotion(database.Promotion{
+		Type:        database.PromotionTypeSpendThreshold,
+		MinSubtotal: minSubtotal,
+		PercentOff:  percentOff,
+	}, testTargets(), testTargets(), nil)
+}
+
+func bundleOfAB(price money.Amount) promotionRules {
+	return testPromotion(database.Promotion{
+		Type:        database.PromotionTypeBundlePrice,
+		BundlePrice: price,
+	}, testTargets(testProductA, testProductB), testTargets(), map[uuid.UUID]int32{testProductA: 1, testProductB: 1})
+}
+
+func TestApplyPromotions(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []promotionRules
+		lines     []pricedLine
+		wantItems []money.Amount
+		wantLines int
+	}{
+		{
+			name:  "3-for-2 gives the cheapest unit free",
+			rules: []promotionRules{threeForTwo()},
+			lines: []pricedLine{
+				testLine(testProductA, 1, 3000),
+				testLine(testProductB, 1, 2000),
+				testLine(testProductC, 1, 1000),
+			},
+			wantItems: []money.Amount{0, 0, 1000},
+			wantLines: 1,
+		},
+		{
+			name:  "3-for-2 ignores an incomplete group",
+			rules: []promotionRules{threeForTwo()},
+			lines: []pricedLine{
+				testLine(testProductA, 2, 3000),
+				testLine(testProductB, 2, 1000),
+			},
+			wantItems: []money.Amount{0, 1000},
+			wantLines: 1,
+		},
+		{
+			name:  "3-for-2 with too few units",
+			rules: []promotionRules{threeForTwo()},
+			lines: []pricedLine{
+				testLine(testProductA, 2, 3000),
+			},
+			wantItems: []money.Amount{0},
+			wantLines: 0,
+		},
+		{
+			name: "disjoint buy and get sets reward the get set",
+			rules: []promotionRules{testPromotion(database.Promotion{
+				Type:        database.PromotionTypeBuyXGetY,
+				BuyQuantity: 1,
+				GetQuantity: 1,
+				PercentOff:  money.HundredPercent / 2,
+			}, testTargets(testProductA), testTargets(testProductB), nil)},
+			lines: []pricedLine{
+				testLine(testProductA, 2, 1000),
+				testLine(testProductB, 3, 800),
+			},
+			wantItems: []money.Amount{0, 800},
+			wantLines: 1,
+		},
+		{
+			name: "disjoint sets without a get item",
+			rules: []promotionRules{testPromotion(database.Promotion{
+				Type:        database.PromotionTypeBuyXGetY,
+				BuyQuantity: 1,
+				GetQuantity: 1,
+				PercentOff:  money.HundredPercent,
+			}, testTargets(testProductA), testTargets(testProductB), nil)},
+			lines: []pricedLine{
+				testLine(testProductA, 2, 1000),
+				testLine(testProductC, 1, 500),
+			},
+			wantItems: []money.Amount{0, 0},
+			wantLines: 0,
+		},
+		{
+			name:  "bundle applies once per complete set",
+			rules: []promotionRules{bundleOfAB(1500)},
+			lines: []pricedLine{
+				testLine(testProductA, 2, 1000),
+				testLine(testProductB, 3, 800),
+			},
+			wantItems: []money.Amount{334, 266},
+			wantLines: 1,
+		},
+		{
+			name:  "bundle dearer than its items",
+			rules: []promotionRules{bundleOfAB(2000)},
+			lines: []pricedLine{
+				testLine(testProductA, 1, 1000),
+				testLine(testProductB, 1, 800),
+			},
+			wantItems: []money.Amount{0, 0},
+			wantLines: 0,
+		},
+		{
+			name:  "spend threshold counts what the earlier promotion left",
+			rules: []promotionRules{threeForTwo(), spendThreshold(5000, money.HundredPercent/10)},
+			lines: []pricedLine{
+				testLine(testProductA, 1, 3000),
+				testLine(testProductB, 1, 2000),
+				testLine(testProductC, 1, 1000),
+			},
+			wantItems: []money.Amount{300, 200, 1000},
+			wantLines: 2,
+		},
+		{
+			name:  "spend threshold missed after the earlier promotion",
+			rules: []promotionRules{threeForTwo(), spendThreshold(5500, money.HundredPercent/10)},
+			lines: []pricedLine{
+				testLine(testProductA, 1, 3000),
+				testLine(testProductB, 1, 2000),
+				testLine(testProductC, 1, 1000),
+			},
+			wantItems: []money.Amount{0, 0, 1000},
+			wantLines: 1,
+		},
+		{
+			name:  "no line is discounted below zero",
+			rules: []promotionRules{spendThreshold(0, money.HundredPercent/2), bundleOfAB(200)},
+			lines: []pricedLine{
+				testLine(testProductA, 1, 1000),
+				testLine(testProductB, 1, 1000),
+			},
+			wantItems: []money.Amount{1000, 1000},
+			wantLines: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := exchangeRate{Currency: "USD", Rate: money.IdentityRate}
+			got := applyPromotions(tt.rules, tt.lines, rate)
+
+			var wantGoods money.Amount
+			for i, line := range tt.lines {
+				if item := got.Items[line.VariantID]; item != tt.wantItems[i] {
+					t.Errorf("line %d discount = %d, want %d", i, item, tt.wantItems[i])
+				}
+				wantGoods += tt.wantItems[i]
+			}
+			if got.Goods != wantGoods {
+				t.Errorf("Goods = %d, want %d", got.Goods, wantGoods)
+			}
+			if len(got.Lines) != tt.wantLines {
+				t.Errorf("len(Lines) = %d, want %d", len(got.Lines), tt.wantLines)
+			}
+
+			var listed money.Amount
+			for _, line := range got.Lines {
+				listed += line.Amount
+			}
+			if listed != got.Goods {
+				t.Errorf("discount lines add up to %d, want %d", listed, got.Goods)
+			}
+		})
+	}
+}
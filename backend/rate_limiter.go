@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter allows each key a number of requests per fixed window. It lives in
+// memory, so every instance of the server counts on its own.
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	hits      map[string]rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   map[string]rateWindow{},
+	}
+}
+
+// allow counts a request for key and reports whether it is within the limit.
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Windows that have ended are dropped once per window so the map does not
+	// keep every key it has seen.
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, w := range l.hits {
+			if now.Sub(w.start) >= l.window {
+				delete(l.hits, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.hits[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = rateWindow{start: now}
+	}
+	w.count++
+	l.hits[key] = w
+	return w.count <= l.limit
+}
+
+// clientIP is the address the request came from, without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
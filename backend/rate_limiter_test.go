@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Minute)
+
+	tests := []struct {
+		name string
+		key  string
+		at   time.Duration
+		want bool
+	}{
+		{name: "first", key: "a", at: 0, want: true},
+		{name: "second", key: "a", at: time.Second, want: true},
+		{name: "over the limit", key: "a", at: 2 * time.Second, want: false},
+		{name: "other key", key: "b", at: 3 * time.Second, want: true},
+		{name: "still over the limit", key: "a", at: 59 * time.Second, want: false},
+		{name: "next window", key: "a", at: time.Minute, want: true},
+		{name: "window after that", key: "a", at: 2 * time.Minute, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := l.allow(tt.key, start.Add(tt.at)); got != tt.want {
+			t.Errorf("%s: allow(%q) = %v, want %v", tt.name, tt.key, got, tt.want)
+		}
+	}
+
+	if _, ok := l.hits["b"]; ok {
+		t.Errorf("ended window for %q was not dropped", "b")
+	}
+}
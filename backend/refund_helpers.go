@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+type RefundLine struct {
+	VariantID uuid.UUID `json:"variant_id"`
+	Quantity  int32     `json:"quantity"`
+}
+
+type RefundRequest struct {
+	Full            bool         `json:"full"`
+	Items           []RefundLine `json:"items"`
+	IncludeShipping bool         `json:"include_shipping"`
+	Restock         bool         `json:"restock"`
+	Reason          string       `json:"reason"`
+}
+
+// createRefund records a refund against a locked order and queues it on the
+// payment ledger against the last capture. The provider is only called by
+// runPaymentOperations once the transaction has committed, and the refunded
+// lines are restocked when it confirms. Lines and shipping are refunded at what
+// the customer paid for them, see refundableAmounts.
+func (cfg *apiConfig) createRefund(ctx context.Context, qtx *database.Queries, order database.Order, req RefundRequest, actor orderActor) (database.Order, database.Refund, error) {
+	if order.PaymentStatus != database.PaymentStatusPaid && order.PaymentStatus != database.PaymentStatusPartiallyRefunded {
+		return order, database.Refund{}, validationError("order has no captured payment to refund")
+	}
+
+	txns, err := qtx.GetPaymentTransactionsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, database.Refund{}, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+	capture, ok := lastCapture(txns)
+	if !ok {
+		return order, database.Refund{}, validationError("order has no captured payment to refund")
+	}
+
+	orderItems, err := qtx.GetOrderItemsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, database.Refund{}, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	refundedRows, err := qtx.GetRefundedQuantitiesByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, database.Refund{}, fmt.Errorf("failed to load refunded quantities: %w", err)
+	}
+	refunded := make(map[uuid.UUID]int32, len(refundedRows))
+	for _, row := range refundedRows {
+		refunded[row.ProductVariantID] = row.Quantity
+	}
+
+	totals, err := qtx.GetOrderRefundTotals(ctx, order.ID)
+	if err != nil {
+		return order, database.Refund{}, fmt.Errorf("failed to load refund totals: %w", err)
+	}
+
+	lines := req.Items
+	includeShipping := req.IncludeShipping
+	paidLines, paidShipping, err := cfg.refundableAmounts(ctx, qtx, order)
+	if err != nil {
+		return order, database.Refund{}, err
+	}
+	if req.Full {
+		lines = nil
+		for _, item := range orderItems {
+			if remaining := item.Quantity - refunded[item.ProductVariantID]; remaining > 0 {
+				lines = append(lines, RefundLine{VariantID: item.ProductVariantID, Quantity: remaining})
+			}
+		}
+		includeShipping = totals.RefundedShipping < paidShipping
+	}
+
+	itemsByVariant := make(map[uuid.UUID]database.OrdersVariant, len(orderItems))
+	for _, item := range orderItems {
+		itemsByVariant[item.ProductVariantID] = item
+	}
+
+	requested := make(map[uuid.UUID]int32, len(lines))
+	for _, line := range lines {
+		item, ok := itemsByVariant[line.VariantID]
+		if !ok {
+			return order, database.Refund{}, validationErrorf("variant %s is not part of this order", line.VariantID)
+		}
+		if line.Quantity <= 0 {
+			return order, database.Refund{}, validationError("quantity must be positive")
+		}
+		requested[line.VariantID] += line.Quantity
+		if refunded[line.VariantID]+requested[line.VariantID] > item.Quantity {
+			return order, database.Refund{}, validationErrorf("quantity for variant %s exceeds the refundable quantity", line.VariantID)
+		}
+	}
+
+	var shippingAmount money.Amount
+	if includeShipping {
+		shippingAmount = paidShipping - totals.RefundedShipping
+		if shippingAmount <= 0 {
+			return order, database.Refund{}, validationError("shipping has already been refunded")
+		}
+	}
+
+	// Each unit's amount is worked out from the running refunded quantity, so the
+	// partial refunds of a line add up to exactly what was paid for it.
+	lineAmounts := make(map[uuid.UUID]money.Amount, len(requested))
+	amount := shippingAmount
+	for variantID, quantity := range requested {
+		ordered := int64(itemsByVariant[variantID].Quantity)
+		before := int64(refunded[variantID])
+		paid := paidLines[variantID]
+		lineAmounts[variantID] = paid.MulRat(before+int64(quantity), ordered) - paid.MulRat(before, ordered)
+		amount += lineAmounts[variantID]
+	}
+
+	captured, refundedTotal := ledgerTotals(txns)
+	refundable := captured - refundedTotal - pendingRefunds(txns)
+	if req.Full {
+		amount = refundable
+	}
+
+	if amount <= 0 {
+		return order, database.Refund{}, validationError("nothing to refund")
+	}
+
+	if amount > refundable {
+		return order, database.Refund{}, validationError("refund exceeds the captured amount")
+	}
+
+	// Cancelled orders have already returned their stock.
+	restock := req.Restock && order.Status != database.OrderStatusCancelled
+
+	refund, err := qtx.CreateRefund(ctx, database.CreateRefundParams{
+		OrderID:        order.ID,
+		Amount:         amount,
+		ShippingAmount: shippingAmount,
+		Reason:         sql.NullString{String: req.Reason, Valid: req.Reason != ""},
+		Restock:        restock,
+		CreatedBy:      actor.nullID(),
+	})
+	if err != nil {
+		return order, database.Refund{}, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	eventItems := make([]RefundLine, 0, len(requested))
+	for variantID, quantity := range requested {
+		_, err := qtx.AddRefundVariant(ctx, database.AddRefundVariantParams{
+			RefundID:         refund.ID,
+			OrderID:          order.ID,
+			ProductVariantID: variantID,
+			Quantity:         quantity,
+			Amount:           lineAmounts[variantID],
+		})
+		if err != nil {
+			return order, refund, fmt.Errorf("failed to add refund line: %w", err)
+		}
+
+		eventItems = append(eventItems, RefundLine{VariantID: variantID, Quantity: quantity})
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeRefundCreated, actor, map[string]any{
+		"refund_id":       refund.ID,
+		"amount":          refund.Amount,
+		"shipping_amount": refund.ShippingAmount,
+		"restock":         restock,
+		"reason":          req.Reason,
+		"items":           eventItems,
+	})
+	if err != nil {
+		return order, refund, err
+	}
+
+	txn, err := queuePaymentOperation(ctx, qtx, order, database.CreatePaymentTransactionParams{
+		Provider:          capture.Provider,
+		Type:              database.PaymentTransactionTypeRefund,
+		Amount:            amount,
+		Currency:          capture.Currency,
+		ProviderReference: capture.ProviderReference,
+		IdempotencyKey:    sql.NullString{String: "refund-" + refund.ID.String(), Valid: true},
+	}, actor)
+	if err != nil {
+		return order, refund, err
+	}
+
+	err = qtx.SetRefundPaymentTransaction(ctx, database.SetRefundPaymentTransactionParams{
+		PaymentTransactionID: uuid.NullUUID{UUID: txn.ID, Valid: true},
+		ID:                   refund.ID,
+	})
+	if err != nil {
+		return order, refund, fmt.Errorf("failed to link refund payment: %w", err)
+	}
+	refund.PaymentTransactionID = uuid.NullUUID{UUID: txn.ID, Valid: true}
+
+	return order, refund, nil
+}
+
+// refundableAmounts splits the order total over its lines and shipping by what
+// the customer paid for each: the price less its share of the discount, plus
+// its share of the stored tax lines when prices exclude tax. The shares add up
+// to the order total, so refunding everything refunds what was charged.
+func (cfg *apiConfig) refundableAmounts(ctx context.Context, qtx *database.Queries, order database.Order) (map[uuid.UUID]money.Amount, money.Amount, error) {
+	lines, err := loadOrderLines(ctx, qtx, order.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	discount := discountQuote{}
+	if !order.DiscountTotal.IsZero() {
+		discount, err = cfg.orderDiscount(ctx, qtx, order, lines, order.ShippingPrice)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	net := discount.applyTo(lines)
+	shipping := order.ShippingPrice - discount.Shipping
+
+	if !order.PricesIncludeTax {
+		lineTax, shippingTax, err := spreadOrderTax(ctx, qtx, order, net)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range net {
+			net[i].Amount += lineTax[i]
+		}
+		shipping += shippingTax
+	}
+
+	weights := make([]money.Amount, 0, len(net)+1)
+	for _, line := range net {
+		weights = append(weights, line.Amount)
+	}
+	weights = append(weights, shipping)
+	shares := spreadDiscount(order.TotalPrice, weights)
+
+	paid := make(map[uuid.UUID]money.Amount, len(net))
+	for i, line := range net {
+		paid[line.VariantID] += shares[i]
+	}
+	return paid, shares[len(net)], nil
+}
+
+// spreadOrderTax divides an order's stored tax lines over its net lines. Goods
+// tax goes to the lines taxed at that line's rate in the shipping country, or to
+// every line when the rate has changed since the order was placed.
+func spreadOrderTax(ctx context.Context, qtx *database.Queries, order database.Order, lines []pricedLine) ([]money.Amount, money.Amount, error) {
+	taxLines, err := getOrderTaxLines(ctx, qtx, order.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	rates, err := loadCountryTaxRates(ctx, qtx, order.ShippingCountryID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lineTax := make([]money.Amount, len(lines))
+	var shippingTax money.Amount
+	for _, taxLine := range taxLines {
+		if taxLine.IsShipping {
+			shippingTax += taxLine.TaxAmount
+			continue
+		}
+
+		weights := make([]money.Amount, len(lines))
+		matched := false
+		for i, line := range lines {
+			if rate, ok := rates.lookup(line.TaxClassID); ok && rate.Name == taxLine.Name && rate.Rate == taxLine.Rate {
+				weights[i] = line.Amount
+				matched = matched || line.Amount.IsPositive()
+			}
+		}
+		if !matched {
+			for i, line := range lines {
+				weights[i] = line.Amount
+			}
+		}
+
+		for i, share := range spreadDiscount(taxLine.TaxAmount, weights) {
+			lineTax[i] += share
+		}
+	}
+	return lineTax, shippingTax, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+type ReturnLine struct {
+	VariantID uuid.UUID `json:"variant_id"`
+	Quantity  int32     `json:"quantity"`
+}
+
+type ReturnRequest struct {
+	Reason string       `json:"reason"`
+	Items  []ReturnLine `json:"items"`
+}
+
+// ReturnUpdateRequest moves a return to a new status. Restock applies when the
+// return is received; Refund on receipt continues straight to refunded.
+type ReturnUpdateRequest struct {
+	Status          database.ReturnStatus `json:"status"`
+	Note            string                `json:"note"`
+	Restock         bool                  `json:"restock"`
+	Refund          bool                  `json:"refund"`
+	IncludeShipping bool                  `json:"include_shipping"`
+}
+
+var returnStatusTransitions = map[database.ReturnStatus][]database.ReturnStatus{
+	database.ReturnStatusRequested: {database.ReturnStatusApproved, database.ReturnStatusRejected},
+	database.ReturnStatusApproved:  {database.ReturnStatusReceived, database.ReturnStatusRejected},
+	database.ReturnStatusReceived:  {database.ReturnStatusRefunded, database.ReturnStatusRejected},
+	database.ReturnStatusRefunded:  {},
+	database.ReturnStatusRejected:  {},
+}
+
+func isValidReturnStatus(status database.ReturnStatus) bool {
+	_, ok := returnStatusTransitions[status]
+	return ok
+}
+
+func canTransitionReturnStatus(from, to database.ReturnStatus) bool {
+	for _, allowed := range returnStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// createReturn opens a return for shipped lines of a locked order. Quantities are
+// limited to what has shipped and is not already part of another open return.
+func createReturn(ctx context.Context, qtx *database.Queries, order database.Order, req ReturnRequest, actor orderActor) (database.Return, error) {
+	if len(req.Items) == 0 {
+		return database.Return{}, validationError("at least one line is required")
+	}
+
+	shippedRows, err := qtx.GetShippedQuantitiesByOrderId(ctx, order.ID)
+	if err != nil {
+		return database.Return{}, fmt.Errorf("failed to load shipped quantities: %w", err)
+	}
+	shipped := make(map[uuid.UUID]int32, len(shippedRows))
+	for _, row := range shippedRows {
+		shipped[row.ProductVariantID] = row.Quantity
+	}
+
+	returnedRows, err := qtx.GetReturnedQuantitiesByOrderId(ctx, order.ID)
+	if err != nil {
+		return database.Return{}, fmt.Errorf("failed to load returned quantities: %w", err)
+	}
+	returned := make(map[uuid.UUID]int32, len(returnedRows))
+	for _, row := range returnedRows {
+		returned[row.ProductVariantID] = row.Quantity
+	}
+
+	requested := make(map[uuid.UUID]int32, len(req.Items))
+	for _, line := range req.Items {
+		if line.Quantity <= 0 {
+			return database.Return{}, validationError("quantity must be positive")
+		}
+		if shipped[line.VariantID] == 0 {
+			return database.Return{}, validationErrorf("variant %s has not shipped on this order", line.VariantID)
+		}
+		requested[line.VariantID] += line.Quantity
+		if returned[line.VariantID]+requested[line.VariantID] > shipped[line.VariantID] {
+			return database.Return{}, validationErrorf("quantity for variant %s exceeds the returnable quantity", line.VariantID)
+		}
+	}
+
+	ret, err := qtx.CreateReturn(ctx, database.CreateReturnParams{
+		OrderID:   order.ID,
+		Reason:    req.Reason,
+		CreatedBy: actor.nullID(),
+	})
+	if err != nil {
+		return database.Return{}, fmt.Errorf("failed to create return: %w", err)
+	}
+
+	eventItems := make([]ReturnLine, 0, len(requested))
+	for variantID, quantity := range requested {
+		_, err := qtx.AddReturnVariant(ctx, database.AddReturnVariantParams{
+			ReturnID:         ret.ID,
+			OrderID:          order.ID,
+			ProductVariantID: variantID,
+			Quantity:         quantity,
+		})
+		if err != nil {
+			return ret, fmt.Errorf("failed to add return line: %w", err)
+		}
+		eventItems = append(eventItems, ReturnLine{VariantID: variantID, Quantity: quantity})
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeReturnCreated, actor, map[string]any{
+		"return_id": ret.ID,
+		"reason":    ret.Reason,
+		"items":     eventItems,
+	})
+	if err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+// updateReturnStatus advances a return on a locked order. Receiving can restock the
+// returned lines; moving to refunded creates a refund for them.
+func (cfg *apiConfig) updateReturnStatus(ctx context.Context, qtx *database.Queries, order database.Order, returnID uuid.UUID, req ReturnUpdateRequest, actor orderActor) (database.Order, database.Return, error) {
+	ret, err := qtx.GetReturnByIdForUpdate(ctx, database.GetReturnByIdForUpdateParams{
+		ID:      returnID,
+		OrderID: order.ID,
+	})
+	if err != nil {
+		return order, database.Return{}, err
+	}
+
+	if !canTransitionReturnStatus(ret.Status, req.Status) {
+		return order, ret, fmt.Errorf("%w: %s -> %s", errInvalidStatusTransition, ret.Status, req.Status)
+	}
+
+	lines, err := qtx.GetReturnVariants(ctx, ret.ID)
+	if err != nil {
+		return order, ret, fmt.Errorf("failed to load return lines: %w", err)
+	}
+
+	params := database.UpdateReturnParams{
+		Status:         req.Status,
+		ResolutionNote: ret.ResolutionNote,
+		Restocked:      ret.Restocked,
+		RefundID:       ret.RefundID,
+		ID:             ret.ID,
+	}
+	if req.Note != "" {
+		params.ResolutionNote = sql.NullString{String: req.Note, Valid: true}
+	}
+
+	if req.Status == database.ReturnStatusReceived && req.Restock {
+		for _, line := range lines {
+			err := qtx.IncreaseVariantStock(ctx, database.IncreaseVariantStockParams{
+				Quantity:  line.Quantity,
+				VariantID: line.ProductVariantID,
+			})
+			if err != nil {
+				return order, ret, fmt.Errorf("failed to restock variant %s: %w", line.ProductVariantID, err)
+			}
+		}
+		params.Restocked = true
+	}
+
+	if req.Status == database.ReturnStatusReceived && req.Refund {
+		if err := recordReturnStatusChange(ctx, qtx, order.ID, ret, params, actor); err != nil {
+			return order, ret, err
+		}
+		ret.Status = database.ReturnStatusReceived
+		ret.Restocked = params.Restocked
+		params.Status = database.ReturnStatusRefunded
+	}
+
+	if params.Status == database.ReturnStatusRefunded {
+		refundLines := make([]RefundLine, 0, len(lines))
+		for _, line := range lines {
+			refundLines = append(refundLines, RefundLine{VariantID: line.ProductVariantID, Quantity: line.Quantity})
+		}
+
+		// Stock is handled by the return itself, never by its refund.
+		var refund database.Refund
+		order, refund, err = cfg.createRefund(ctx, qtx, order, RefundRequest{
+			Items:           refundLines,
+			IncludeShipping: req.IncludeShipping,
+			Reason:          "Return " + ret.ID.String(),
+		}, actor)
+		if err != nil {
+			return order, ret, err
+		}
+		params.RefundID = uuid.NullUUID{UUID: refund.ID, Valid: true}
+	}
+
+	if err := recordReturnStatusChange(ctx, qtx, order.ID, ret, params, actor); err != nil {
+		return order, ret, err
+	}
+
+	updated, err := qtx.UpdateReturn(ctx, params)
+	if err != nil {
+		return order, ret, fmt.Errorf("failed to update return: %w", err)
+	}
+
+	return order, updated, nil
+}
+
+func recordReturnStatusChange(ctx context.Context, qtx *database.Queries, orderID uuid.UUID, ret database.Return, params database.UpdateReturnParams, actor orderActor) error {
+	payload := map[string]any{
+		"return_id": ret.ID,
+		"from":      ret.Status,
+		"to":        params.Status,
+	}
+	if params.Restocked && !ret.Restocked {
+		payload["restocked"] = true
+	}
+	if params.RefundID.Valid && params.RefundID != ret.RefundID {
+		payload["refund_id"] = params.RefundID.UUID
+	}
+	return recordOrderEvent(ctx, qtx, orderID, database.OrderEventTypeReturnStatusChanged, actor, payload)
+}
@@ -12,6 +12,7 @@ func (cfg *apiConfig) registerRoutes(mux *http.ServeMux) {
 	cfg.registerAdminRoutes(mux)
 	cfg.registerApiAuthRoutes(mux)
 	cfg.registerApiAdminRoutes(mux)
+	cfg.registerApiWebhookRoutes(mux)
 	cfg.registerAuthRoutes(mux)
 	log.Printf("All routes registered")
 }
@@ -43,7 +43,48 @@ func (cfg *apiConfig) registerApiAdminRoutes(mux *http.ServeMux) {
 	mux.Handle("PUT /api/admin/countries/{countryId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateCountry))))
 	mux.Handle("PATCH /api/admin/countries/{countryId}/status", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminToggleCountryStatus))))
 	mux.Handle("DELETE /api/admin/countries/{countryId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeleteCountry))))
+	mux.Handle("GET /api/admin/tax-classes", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetTaxClasses))))
+	mux.Handle("POST /api/admin/tax-classes", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateTaxClass))))
+	mux.Handle("PUT /api/admin/tax-classes/{taxClassId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateTaxClass))))
+	mux.Handle("DELETE /api/admin/tax-classes/{taxClassId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeleteTaxClass))))
+	mux.Handle("GET /api/admin/tax-rates", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetTaxRates))))
+	mux.Handle("POST /api/admin/tax-rates", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateTaxRate))))
+	mux.Handle("PUT /api/admin/tax-rates/{taxRateId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateTaxRate))))
+	mux.Handle("DELETE /api/admin/tax-rates/{taxRateId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeleteTaxRate))))
+	mux.Handle("GET /api/admin/coupons", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetCoupons))))
+	mux.Handle("POST /api/admin/coupons", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateCoupon))))
+	mux.Handle("PUT /api/admin/coupons/{couponId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateCoupon))))
+	mux.Handle("DELETE /api/admin/coupons/{couponId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeleteCoupon))))
+	mux.Handle("GET /api/admin/promotions", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetPromotions))))
+	mux.Handle("POST /api/admin/promotions", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreatePromotion))))
+	mux.Handle("PUT /api/admin/promotions/{promotionId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdatePromotion))))
+	mux.Handle("DELETE /api/admin/promotions/{promotionId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeletePromotion))))
+	mux.Handle("GET /api/admin/exchange-rates", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetExchangeRates))))
+	mux.Handle("PUT /api/admin/exchange-rates/{currency}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpsertExchangeRate))))
+	mux.Handle("DELETE /api/admin/exchange-rates/{currency}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeleteExchangeRate))))
 	mux.Handle("GET /api/admin/orders", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminListOrders))))
+	mux.Handle("GET /api/admin/orders/export", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminExportOrders))))
 	mux.Handle("GET /api/admin/orders/{orderId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetSingleOrder))))
+	mux.Handle("PATCH /api/admin/orders/{orderId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateOrder))))
+	mux.Handle("PATCH /api/admin/orders/{orderId}/status", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateOrderStatus))))
+	mux.Handle("PATCH /api/admin/orders/{orderId}/payment-status", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateOrderPaymentStatus))))
+	mux.Handle("GET /api/admin/orders/{orderId}/payment-transactions", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetOrderPaymentTransactions))))
+	mux.Handle("POST /api/admin/orders/{orderId}/payment/capture", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCapturePayment))))
+	mux.Handle("POST /api/admin/orders/{orderId}/payment/void", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminVoidPayment))))
+	mux.Handle("POST /api/admin/orders/{orderId}/cancel", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCancelOrder))))
+	mux.Handle("GET /api/admin/orders/{orderId}/invoice.pdf", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminOrderInvoice))))
+	mux.Handle("GET /api/admin/orders/{orderId}/packing-slip.pdf", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminOrderPackingSlip))))
+	mux.Handle("GET /api/admin/orders/{orderId}/refunds", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetOrderRefunds))))
+	mux.Handle("POST /api/admin/orders/{orderId}/refunds", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateRefund))))
+	mux.Handle("GET /api/admin/orders/{orderId}/shipments", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetOrderShipments))))
+	mux.Handle("POST /api/admin/orders/{orderId}/shipments", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateShipment))))
+	mux.Handle("PUT /api/admin/orders/{orderId}/shipments/{shipmentId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateShipment))))
+	mux.Handle("GET /api/admin/orders/{orderId}/returns", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetOrderReturns))))
+	mux.Handle("POST /api/admin/orders/{orderId}/returns", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateReturn))))
+	mux.Handle("PATCH /api/admin/orders/{orderId}/returns/{returnId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateReturn))))
+	mux.Handle("GET /api/admin/orders/{orderId}/notes", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminGetOrderNotes))))
+	mux.Handle("POST /api/admin/orders/{orderId}/notes", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminCreateOrderNote))))
+	mux.Handle("PUT /api/admin/orders/{orderId}/notes/{noteId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminUpdateOrderNote))))
+	mux.Handle("DELETE /api/admin/orders/{orderId}/notes/{noteId}", cfg.checkAuth(cfg.checkAdmin(http.HandlerFunc(cfg.handleApiAdminDeleteOrderNote))))
 	log.Printf("Shop API routes registered")
 }
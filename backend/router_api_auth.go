@@ -10,6 +10,13 @@ func (cfg *apiConfig) registerApiAuthRoutes(mux *http.ServeMux) {
 	mux.Handle("POST /api/login", http.HandlerFunc(cfg.handleApiLogin))
 	mux.Handle("POST /api/refresh", http.HandlerFunc(cfg.handleApiRefreshToken))
 	mux.Handle("GET /api/account", cfg.checkAuth(http.HandlerFunc(cfg.handleApiGetAccount)))
+	mux.Handle("GET /api/account/orders", cfg.checkAuth(http.HandlerFunc(cfg.handleApiGetAccountOrders)))
+	mux.Handle("GET /api/account/orders/{id}", cfg.checkAuth(http.HandlerFunc(cfg.handleApiGetAccountOrder)))
+	mux.Handle("POST /api/account/orders/{id}/cancel", cfg.checkAuth(http.HandlerFunc(cfg.handleApiCancelAccountOrder)))
+	mux.Handle("POST /api/account/orders/{id}/reorder", cfg.checkAuth(http.HandlerFunc(cfg.handleApiReorderAccountOrder)))
+	mux.Handle("GET /api/account/orders/{id}/returns", cfg.checkAuth(http.HandlerFunc(cfg.handleApiGetAccountOrderReturns)))
+	mux.Handle("POST /api/account/orders/{id}/returns", cfg.checkAuth(http.HandlerFunc(cfg.handleApiCreateAccountOrderReturn)))
+	mux.Handle("GET /api/account/orders/{id}/invoice.pdf", cfg.checkAuth(http.HandlerFunc(cfg.handleApiAccountOrderInvoice)))
 	mux.Handle("POST /api/logout", http.HandlerFunc(cfg.handleApiLogout))
 	mux.Handle("POST /api/users", http.HandlerFunc(cfg.handlerApiRegister))
 	log.Printf("Auth API routes registered")
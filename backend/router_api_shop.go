@@ -15,9 +15,16 @@ func (cfg *apiConfig) registerApiShopRoutes(mux *http.ServeMux) {
 	mux.Handle("PUT /api/carts/variants", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiUpdateCartVariant)))
 	mux.Handle("GET /api/carts", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiGetCart)))
 	mux.Handle("DELETE /api/carts/variants/{id}", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiDeleteFromCart)))
+	mux.Handle("GET /api/carts/quote", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiGetCartQuote)))
+	mux.Handle("PUT /api/carts/currency", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiSetCartCurrency)))
+	mux.Handle("POST /api/carts/coupon", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiApplyCartCoupon)))
+	mux.Handle("DELETE /api/carts/coupon", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiRemoveCartCoupon)))
+	mux.Handle("GET /api/currencies", http.HandlerFunc(cfg.handleApiGetCurrencies))
 	mux.Handle("GET /api/countries", http.HandlerFunc(cfg.handleApiGetCountries))
 	mux.Handle("GET /api/shipping-methods", http.HandlerFunc(cfg.handleApiGetShippingMethods))
 	mux.Handle("GET /api/payment-methods", http.HandlerFunc(cfg.handleApiGetPaymentMethods))
 	mux.Handle("POST /api/orders", cfg.optionalAuth(http.HandlerFunc(cfg.handleApiCheckout)))
+	mux.Handle("POST /api/orders/lookup", http.HandlerFunc(cfg.handleApiLookupOrder))
+	mux.Handle("GET /api/orders/{id}", http.HandlerFunc(cfg.handleApiGetOrderWithToken))
 	log.Printf("Shop API routes registered")
 }
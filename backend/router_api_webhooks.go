@@ -0,0 +1,12 @@
+package main
+
+import (
+	"log"
+	"net/http"
+)
+
+func (cfg *apiConfig) registerApiWebhookRoutes(mux *http.ServeMux) {
+	log.Printf("Registering Webhook API routes...")
+	mux.Handle("POST /api/webhooks/payments/{provider}", http.HandlerFunc(cfg.handleApiPaymentWebhook))
+	log.Printf("Webhook API routes registered")
+}
@@ -4,7 +4,10 @@ set -e
 
 if [ -f .env ]; then
     echo "Loading .env..."
+    # Exported so migrations can read settings such as ORDER_NUMBER_FORMAT.
+    set -a
     source .env
+    set +a
 else
     echo "No .env file found."
     exit 1
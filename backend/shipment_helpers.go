@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/google/uuid"
+)
+
+// trackingNumberPlaceholder is replaced with the shipment's tracking number when
+// building a tracking URL, e.g. "https://carrier.example/track?n={tracking_number}".
+const trackingNumberPlaceholder = "{tracking_number}"
+
+type ShipmentLine struct {
+	VariantID uuid.UUID `json:"variant_id"`
+	Quantity  int32     `json:"quantity"`
+}
+
+type ShipmentRequest struct {
+	Carrier             string         `json:"carrier"`
+	TrackingNumber      string         `json:"tracking_number"`
+	TrackingURLTemplate string         `json:"tracking_url_template"`
+	ShippedAt           *time.Time     `json:"shipped_at"`
+	Items               []ShipmentLine `json:"items"`
+}
+
+func trackingURL(template, trackingNumber string) string {
+	if template == "" || trackingNumber == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, trackingNumberPlaceholder, trackingNumber)
+}
+
+// createShipment records a parcel for a locked order. Without items it ships every
+// line that has not shipped yet. Refunded units no longer need shipping. Once all
+// lines have shipped the order is moved to shipped, passing through processing
+// when it is still only paid.
+func createShipment(ctx context.Context, qtx *database.Queries, order database.Order, req ShipmentRequest, actor orderActor) (database.Order, database.Shipment, error) {
+	if order.Status != database.OrderStatusPaid && order.Status != database.OrderStatusProcessing {
+		return order, database.Shipment{}, validationErrorf("orders with status %s cannot be shipped", order.Status)
+	}
+
+	orderItems, err := qtx.GetOrderItemsByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, database.Shipment{}, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	shippedRows, err := qtx.GetShippedQuantitiesByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, database.Shipment{}, fmt.Errorf("failed to load shipped quantities: %w", err)
+	}
+	shipped := make(map[uuid.UUID]int32, len(shippedRows))
+	for _, row := range shippedRows {
+		shipped[row.ProductVariantID] = row.Quantity
+	}
+
+	refundedRows, err := qtx.GetRefundedQuantitiesByOrderId(ctx, order.ID)
+	if err != nil {
+		return order, database.Shipment{}, fmt.Errorf("failed to load refunded quantities: %w", err)
+	}
+	refunded := make(map[uuid.UUID]int32, len(refundedRows))
+	for _, row := range refundedRows {
+		refunded[row.ProductVariantID] = row.Quantity
+	}
+	toShip := func(item database.OrdersVariant) int32 {
+		return item.Quantity - refunded[item.ProductVariantID]
+	}
+
+	lines := req.Items
+	if len(lines) == 0 {
+		for _, item := range orderItems {
+			if remaining := toShip(item) - shipped[item.ProductVariantID]; remaining > 0 {
+				lines = append(lines, ShipmentLine{VariantID: item.ProductVariantID, Quantity: remaining})
+			}
+		}
+	}
+
+	itemsByVariant := make(map[uuid.UUID]database.OrdersVariant, len(orderItems))
+	for _, item := range orderItems {
+		itemsByVariant[item.ProductVariantID] = item
+	}
+
+	requested := make(map[uuid.UUID]int32, len(lines))
+	for _, line := range lines {
+		item, ok := itemsByVariant[line.VariantID]
+		if !ok {
+			return order, database.Shipment{}, validationErrorf("variant %s is not part of this order", line.VariantID)
+		}
+		if line.Quantity <= 0 {
+			return order, database.Shipment{}, validationError("quantity must be positive")
+		}
+		requested[line.VariantID] += line.Quantity
+		if shipped[line.VariantID]+requested[line.VariantID] > toShip(item) {
+			return order, database.Shipment{}, validationErrorf("quantity for variant %s exceeds the unshipped quantity", line.VariantID)
+		}
+	}
+
+	if len(requested) == 0 {
+		return order, database.Shipment{}, validationError("nothing left to ship")
+	}
+
+	shippedAt := time.Now().UTC()
+	if req.ShippedAt != nil {
+		shippedAt = req.ShippedAt.UTC()
+	}
+
+	shipment, err := qtx.CreateShipment(ctx, database.CreateShipmentParams{
+		OrderID:             order.ID,
+		Carrier:             req.Carrier,
+		TrackingNumber:      sql.NullString{String: req.TrackingNumber, Valid: req.TrackingNumber != ""},
+		TrackingUrlTemplate: sql.NullString{String: req.TrackingURLTemplate, Valid: req.TrackingURLTemplate != ""},
+		ShippedAt:           shippedAt,
+		CreatedBy:           actor.nullID(),
+	})
+	if err != nil {
+		return order, database.Shipment{}, fmt.Errorf("failed to create shipment: %w", err)
+	}
+
+	eventItems := make([]ShipmentLine, 0, len(requested))
+	for variantID, quantity := range requested {
+		_, err := qtx.AddShipmentVariant(ctx, database.AddShipmentVariantParams{
+			ShipmentID:       shipment.ID,
+			OrderID:          order.ID,
+			ProductVariantID: variantID,
+			Quantity:         quantity,
+		})
+		if err != nil {
+			return order, shipment, fmt.Errorf("failed to add shipment line: %w", err)
+		}
+		shipped[variantID] += quantity
+		eventItems = append(eventItems, ShipmentLine{VariantID: variantID, Quantity: quantity})
+	}
+
+	err = recordOrderEvent(ctx, qtx, order.ID, database.OrderEventTypeShipmentCreated, actor, map[string]any{
+		"shipment_id":     shipment.ID,
+		"carrier":         shipment.Carrier,
+		"tracking_number": shipment.TrackingNumber.String,
+		"items":           eventItems,
+	})
+	if err != nil {
+		return order, shipment, err
+	}
+
+	if order.Status == database.OrderStatusPaid {
+		order, err = transitionOrderStatus(ctx, qtx, order, database.OrderStatusProcessing, actor)
+		if err != nil {
+			return order, shipment, err
+		}
+	}
+
+	for _, item := range orderItems {
+		if shipped[item.ProductVariantID] < toShip(item) {
+			return order, shipment, nil
+		}
+	}
+
+	order, err = transitionOrderStatus(ctx, qtx, order, database.OrderStatusShipped, actor)
+	if err != nil {
+		return order, shipment, err
+	}
+
+	return order, shipment, nil
+}
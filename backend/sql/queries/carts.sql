@@ -1,6 +1,6 @@
 -- name: CreateCart :one
-INSERT INTO carts (user_id)
-VALUES (sqlc.arg(user_id))
+INSERT INTO carts (user_id, currency, exchange_rate)
+VALUES (sqlc.arg(user_id), sqlc.arg(currency), sqlc.arg(exchange_rate))
 RETURNING id, user_id, created_at, updated_at;
 
 -- name: GetCartById :one
@@ -43,6 +43,18 @@ UPDATE carts
 SET status = 'abandoned', updated_at = NOW()
 WHERE status = 'new' AND updated_at < sqlc.arg(threshold);
 
+-- name: UpdateCartCurrency :one
+UPDATE carts
+SET currency = sqlc.arg(currency), exchange_rate = sqlc.arg(exchange_rate), updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateCartCoupon :one
+UPDATE carts
+SET coupon_id = sqlc.arg(coupon_id), updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: UpdateCartStatus :one
 UPDATE carts
 SET status = sqlc.arg(status), updated_at = NOW()
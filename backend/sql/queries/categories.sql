@@ -32,6 +32,7 @@ SELECT
   c.description,
   c.parent_id,
   p.name AS parent_name,
+  c.tax_class_id,
   c.created_at,
   c.updated_at
 FROM categories c
@@ -39,12 +40,13 @@ LEFT JOIN categories p ON c.parent_id = p.id
 ORDER BY c.name;
 
 -- name: CreateCategory :one
-INSERT INTO categories (name, slug, description, parent_id)
+INSERT INTO categories (name, slug, description, parent_id, tax_class_id)
 VALUES (
     sqlc.arg(name),
     sqlc.arg(slug),
     sqlc.arg(description),
-    sqlc.arg(parent_id)
+    sqlc.arg(parent_id),
+    sqlc.arg(tax_class_id)
 )
 RETURNING *;
 
@@ -55,6 +57,7 @@ SET
     slug = sqlc.arg(slug),
     description = sqlc.arg(description),
     parent_id = sqlc.arg(parent_id),
+    tax_class_id = sqlc.arg(tax_class_id),
     updated_at = NOW()
     WHERE id = sqlc.arg(id)
     RETURNING *;
@@ -1,9 +1,10 @@
 -- name: CreateCountry :one
-INSERT INTO countries (name, iso_code, is_active)
+INSERT INTO countries (name, iso_code, is_active, currency)
 VALUES (
     sqlc.arg(name),
     sqlc.arg(iso_code),
-    sqlc.arg(is_active)
+    sqlc.arg(is_active),
+    NULLIF(sqlc.arg(currency)::text, '')
     )
     RETURNING *;
 
@@ -21,7 +22,8 @@ UPDATE countries SET
     name = sqlc.arg(name),
     iso_code = sqlc.arg(iso_code),
     is_active = sqlc.arg(is_active),
-    sort_order = sqlc.arg(sort_order)
+    sort_order = sqlc.arg(sort_order),
+    currency = NULLIF(sqlc.arg(currency)::text, '')
 
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -0,0 +1,115 @@
+-- name: ListCoupons :many
+SELECT * FROM coupons
+ORDER BY created_at DESC;
+
+-- name: GetCouponById :one
+SELECT * FROM coupons
+WHERE id = sqlc.arg(id);
+
+-- name: GetCouponByCode :one
+SELECT * FROM coupons
+WHERE UPPER(code) = UPPER(sqlc.arg(code));
+
+-- name: CreateCoupon :one
+INSERT INTO coupons (
+  code,
+  description,
+  type,
+  percent_off,
+  amount_off,
+  min_subtotal,
+  starts_at,
+  ends_at,
+  usage_limit,
+  usage_limit_per_customer,
+  is_active
+)
+VALUES (
+  sqlc.arg(code),
+  sqlc.arg(description),
+  sqlc.arg(type),
+  sqlc.arg(percent_off),
+  sqlc.arg(amount_off),
+  sqlc.arg(min_subtotal),
+  sqlc.arg(starts_at),
+  sqlc.arg(ends_at),
+  sqlc.arg(usage_limit),
+  sqlc.arg(usage_limit_per_customer),
+  sqlc.arg(is_active)
+)
+RETURNING *;
+
+-- name: UpdateCoupon :one
+UPDATE coupons
+SET
+  code = sqlc.arg(code),
+  description = sqlc.arg(description),
+  type = sqlc.arg(type),
+  percent_off = sqlc.arg(percent_off),
+  amount_off = sqlc.arg(amount_off),
+  min_subtotal = sqlc.arg(min_subtotal),
+  starts_at = sqlc.arg(starts_at),
+  ends_at = sqlc.arg(ends_at),
+  usage_limit = sqlc.arg(usage_limit),
+  usage_limit_per_customer = sqlc.arg(usage_limit_per_customer),
+  is_active = sqlc.arg(is_active)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteCoupon :execrows
+DELETE FROM coupons
+WHERE id = sqlc.arg(id);
+
+-- name: GetCouponProductIds :many
+SELECT product_id FROM coupon_products
+WHERE coupon_id = sqlc.arg(coupon_id);
+
+-- name: GetCouponCategoryIds :many
+SELECT category_id FROM coupon_categories
+WHERE coupon_id = sqlc.arg(coupon_id);
+
+-- name: AddCouponProduct :exec
+INSERT INTO coupon_products (coupon_id, product_id)
+VALUES (sqlc.arg(coupon_id), sqlc.arg(product_id))
+ON CONFLICT DO NOTHING;
+
+-- name: AddCouponCategory :exec
+INSERT INTO coupon_categories (coupon_id, category_id)
+VALUES (sqlc.arg(coupon_id), sqlc.arg(category_id))
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteCouponProducts :exec
+DELETE FROM coupon_products
+WHERE coupon_id = sqlc.arg(coupon_id);
+
+-- name: DeleteCouponCategories :exec
+DELETE FROM coupon_categories
+WHERE coupon_id = sqlc.arg(coupon_id);
+
+-- name: IncrementCouponUsage :one
+UPDATE coupons
+SET times_used = times_used + 1
+WHERE id = sqlc.arg(id)
+  AND (usage_limit IS NULL OR times_used < usage_limit)
+RETURNING *;
+
+-- name: CountCouponRedemptionsByCustomer :one
+SELECT COUNT(*)
+FROM coupon_redemptions
+WHERE coupon_id = sqlc.arg(coupon_id)
+  AND (user_id = sqlc.narg(user_id) OR LOWER(customer_email) = LOWER(sqlc.arg(customer_email)));
+
+-- name: CreateCouponRedemption :one
+INSERT INTO coupon_redemptions (coupon_id, order_id, user_id, customer_email)
+VALUES (sqlc.arg(coupon_id), sqlc.arg(order_id), sqlc.arg(user_id), sqlc.arg(customer_email))
+RETURNING *;
+
+-- name: ReleaseCouponRedemption :exec
+WITH released AS (
+  DELETE FROM coupon_redemptions
+  WHERE order_id = sqlc.arg(order_id)
+  RETURNING coupon_id
+)
+UPDATE coupons
+SET times_used = GREATEST(times_used - 1, 0)
+WHERE id IN (SELECT coupon_id FROM released);
@@ -0,0 +1,18 @@
+-- name: GetExchangeRates :many
+SELECT * FROM exchange_rates
+ORDER BY currency ASC;
+
+-- name: GetExchangeRate :one
+SELECT * FROM exchange_rates
+WHERE currency = sqlc.arg(currency);
+
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (currency, rate)
+VALUES (sqlc.arg(currency), sqlc.arg(rate))
+ON CONFLICT (currency) DO UPDATE
+SET rate = EXCLUDED.rate
+RETURNING *;
+
+-- name: DeleteExchangeRate :execrows
+DELETE FROM exchange_rates
+WHERE currency = sqlc.arg(currency);
@@ -0,0 +1,25 @@
+-- name: CreateOrderEvent :one
+INSERT INTO order_events (order_id, event_type, actor_type, actor_id, payload)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(event_type),
+    sqlc.arg(actor_type),
+    sqlc.arg(actor_id),
+    sqlc.arg(payload)
+)
+RETURNING *;
+
+-- name: GetOrderEvents :many
+SELECT
+  e.id,
+  e.order_id,
+  e.event_type,
+  e.actor_type,
+  e.actor_id,
+  e.payload,
+  e.created_at,
+  u.email AS actor_email
+FROM order_events e
+LEFT JOIN users u ON u.id = e.actor_id
+WHERE e.order_id = sqlc.arg(order_id)
+ORDER BY e.created_at ASC, e.id ASC;
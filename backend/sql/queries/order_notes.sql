@@ -0,0 +1,43 @@
+-- name: CreateOrderNote :one
+INSERT INTO order_notes (order_id, body, is_customer_visible, created_by)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(body),
+    sqlc.arg(is_customer_visible),
+    sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: GetOrderNotes :many
+SELECT
+  n.id,
+  n.order_id,
+  n.body,
+  n.is_customer_visible,
+  n.created_by,
+  n.created_at,
+  n.updated_at,
+  u.email AS created_by_email
+FROM order_notes n
+LEFT JOIN users u ON u.id = n.created_by
+WHERE n.order_id = sqlc.arg(order_id)
+ORDER BY n.created_at ASC;
+
+-- name: GetCustomerVisibleOrderNotes :many
+SELECT * FROM order_notes
+WHERE order_id = sqlc.arg(order_id)
+  AND is_customer_visible = TRUE
+ORDER BY created_at ASC;
+
+-- name: UpdateOrderNote :one
+UPDATE order_notes
+SET
+    body = sqlc.arg(body),
+    is_customer_visible = sqlc.arg(is_customer_visible),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND order_id = sqlc.arg(order_id)
+RETURNING *;
+
+-- name: DeleteOrderNote :execrows
+DELETE FROM order_notes
+WHERE id = sqlc.arg(id) AND order_id = sqlc.arg(order_id);
@@ -1,4 +1,13 @@
 -- name: CreateOrder :one
+WITH next_number AS (
+    INSERT INTO order_number_counters (period, last_value)
+    VALUES (
+        CASE WHEN sqlc.arg(order_number_format)::text LIKE '%{year}%' THEN EXTRACT(YEAR FROM CURRENT_DATE)::int ELSE 0 END,
+        1
+    )
+    ON CONFLICT (period) DO UPDATE SET last_value = order_number_counters.last_value + 1
+    RETURNING period, last_value
+)
 INSERT INTO orders (
     user_id, 
     total_price, 
@@ -16,9 +25,20 @@ INSERT INTO orders (
     billing_country_id,
     shipping_option_id,
     shipping_price,
-    payment_option_id
+    payment_option_id,
+    customer_note,
+    currency,
+    exchange_rate,
+    tax_total,
+    prices_include_tax,
+    vat_id,
+    reverse_charge,
+    coupon_id,
+    coupon_code,
+    discount_total,
+    order_number
 )
-VALUES (
+SELECT
     sqlc.arg(user_id), 
     sqlc.arg(total_price), 
     sqlc.arg(customer_email), 
@@ -35,8 +55,23 @@ VALUES (
     sqlc.arg(billing_country_id),
     sqlc.arg(shipping_option_id),
     sqlc.arg(shipping_price),
-    sqlc.arg(payment_option_id)
-)
+    sqlc.arg(payment_option_id),
+    sqlc.arg(customer_note),
+    sqlc.arg(currency),
+    sqlc.arg(exchange_rate),
+    sqlc.arg(tax_total),
+    sqlc.arg(prices_include_tax),
+    sqlc.arg(vat_id),
+    sqlc.arg(reverse_charge),
+    sqlc.arg(coupon_id),
+    sqlc.arg(coupon_code),
+    sqlc.arg(discount_total),
+    replace(
+        replace(sqlc.arg(order_number_format)::text, '{year}', next_number.period::text),
+        '{seq}',
+        lpad(next_number.last_value::text, GREATEST(sqlc.arg(order_number_digits)::int, length(next_number.last_value::text)), '0')
+    )
+FROM next_number
 RETURNING *;
 
 -- name: GetOrderById :one
@@ -46,10 +81,13 @@ WHERE id = sqlc.arg(id);
 -- name: GetOrderWithUserById :one
 SELECT 
   o.id,
+  o.order_number,
   o.user_id,
   o.status,
   o.payment_status,
   o.total_price,
+  o.currency,
+  o.exchange_rate,
   o.created_at,
   o.updated_at,
   o.customer_email,
@@ -64,9 +102,16 @@ SELECT
   o.billing_postal_code,
   o.shipping_option_id,
   o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
   o.payment_option_id,
   o.shipping_country_id,
   o.billing_country_id,
+  o.customer_note,
   s.name AS shipping_method_name,
   p.name AS payment_method_name,
   u.email AS user_email,
@@ -110,6 +155,30 @@ RETURNING *;
 SELECT * FROM orders_variants
 WHERE order_id = sqlc.arg(order_id);
 
+-- name: AddOrderItem :one
+INSERT INTO orders_variants (order_id, product_variant_id, quantity, price_per_item, total_price)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(product_variant_id),
+    sqlc.arg(quantity),
+    sqlc.arg(price_per_item),
+    sqlc.arg(total_price)
+)
+RETURNING *;
+
+-- name: UpdateOrderItemQuantity :one
+UPDATE orders_variants
+SET
+    quantity = sqlc.arg(quantity),
+    total_price = sqlc.arg(quantity) * price_per_item,
+    updated_at = NOW()
+WHERE order_id = sqlc.arg(order_id) AND product_variant_id = sqlc.arg(product_variant_id)
+RETURNING *;
+
+-- name: DeleteOrderItem :exec
+DELETE FROM orders_variants
+WHERE order_id = sqlc.arg(order_id) AND product_variant_id = sqlc.arg(product_variant_id);
+
 -- name: GetOrderItemsByOrderIdWithVariants :many
 SELECT
   ov.order_id,
@@ -134,10 +203,13 @@ RETURNING *;
 -- name: ListOrders :many
 SELECT
   o.id,
+  o.order_number,
   o.user_id,
   o.status,
   o.payment_status,
   o.total_price,
+  o.currency,
+  o.exchange_rate,
   o.created_at,
   o.updated_at,
   o.customer_email,
@@ -167,6 +239,7 @@ LEFT JOIN users u ON u.id = o.user_id
 WHERE
   (
     sqlc.narg('search')::text IS NULL
+    OR o.order_number ILIKE '%' || sqlc.narg('search') || '%'
     OR o.customer_email ILIKE '%' || sqlc.narg('search') || '%'
     OR o.shipping_name ILIKE '%' || sqlc.narg('search') || '%'
     OR o.billing_name ILIKE '%' || sqlc.narg('search') || '%'
@@ -194,6 +267,140 @@ OFFSET $2;
 
 
 
+-- name: ExportOrders :many
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.created_at,
+  o.customer_email,
+  u.email AS user_email,
+  o.shipping_name,
+  o.shipping_address,
+  o.shipping_city,
+  o.shipping_postal_code,
+  sc.iso_code AS shipping_country_code,
+  o.shipping_phone,
+  o.billing_name,
+  o.billing_address,
+  o.billing_city,
+  o.billing_postal_code,
+  bc.iso_code AS billing_country_code,
+  o.vat_id,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name,
+  (
+    SELECT COALESCE(SUM(ov.quantity), 0)
+    FROM orders_variants ov
+    WHERE ov.order_id = o.id
+  )::int AS item_count,
+  o.shipping_price,
+  o.tax_total,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.total_price,
+  o.currency,
+  o.exchange_rate,
+  o.customer_note
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+LEFT JOIN users u ON u.id = o.user_id
+LEFT JOIN countries sc ON sc.id = o.shipping_country_id
+LEFT JOIN countries bc ON bc.id = o.billing_country_id
+WHERE
+  (
+    sqlc.narg('search')::text IS NULL
+    OR o.order_number ILIKE '%' || sqlc.narg('search') || '%'
+    OR o.customer_email ILIKE '%' || sqlc.narg('search') || '%'
+    OR o.shipping_name ILIKE '%' || sqlc.narg('search') || '%'
+    OR o.billing_name ILIKE '%' || sqlc.narg('search') || '%'
+    OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+  )
+  AND (
+    sqlc.narg('status')::order_status IS NULL
+    OR o.status = sqlc.narg('status')
+  )
+  AND (
+    sqlc.narg('payment_status')::payment_status IS NULL
+    OR o.payment_status = sqlc.narg('payment_status')
+  )
+  AND (
+    sqlc.narg('date_from')::timestamp IS NULL
+    OR o.created_at >= sqlc.narg('date_from')
+  )
+  AND (
+    sqlc.narg('date_to')::timestamp IS NULL
+    OR o.created_at <= sqlc.narg('date_to')
+  )
+  AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (o.created_at, o.id) < (sqlc.narg('after_created_at'), sqlc.narg('after_id')::uuid)
+  )
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ExportOrderItems :many
+SELECT
+  o.id AS order_id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.created_at,
+  o.customer_email,
+  ov.product_variant_id,
+  v.sku,
+  pr.name AS product_name,
+  v.variant_name,
+  ov.quantity,
+  ov.price_per_item,
+  o.currency,
+  o.exchange_rate
+FROM
+  orders_variants ov
+JOIN orders o ON o.id = ov.order_id
+JOIN product_variants v ON v.id = ov.product_variant_id
+JOIN products pr ON pr.id = v.product_id
+LEFT JOIN users u ON u.id = o.user_id
+WHERE
+  (
+    sqlc.narg('search')::text IS NULL
+    OR o.order_number ILIKE '%' || sqlc.narg('search') || '%'
+    OR o.customer_email ILIKE '%' || sqlc.narg('search') || '%'
+    OR o.shipping_name ILIKE '%' || sqlc.narg('search') || '%'
+    OR o.billing_name ILIKE '%' || sqlc.narg('search') || '%'
+    OR u.email ILIKE '%' || sqlc.narg('search') || '%'
+  )
+  AND (
+    sqlc.narg('status')::order_status IS NULL
+    OR o.status = sqlc.narg('status')
+  )
+  AND (
+    sqlc.narg('payment_status')::payment_status IS NULL
+    OR o.payment_status = sqlc.narg('payment_status')
+  )
+  AND (
+    sqlc.narg('date_from')::timestamp IS NULL
+    OR o.created_at >= sqlc.narg('date_from')
+  )
+  AND (
+    sqlc.narg('date_to')::timestamp IS NULL
+    OR o.created_at <= sqlc.narg('date_to')
+  )
+  AND (
+    sqlc.narg('after_created_at')::timestamp IS NULL
+    OR (o.created_at, o.id) < (sqlc.narg('after_created_at'), sqlc.narg('after_order_id')::uuid)
+    OR (
+      (o.created_at, o.id) = (sqlc.narg('after_created_at'), sqlc.narg('after_order_id')::uuid)
+      AND v.sku > sqlc.narg('after_sku')::text
+    )
+  )
+ORDER BY o.created_at DESC, o.id DESC, v.sku
+LIMIT sqlc.arg('page_size');
+
 -- name: CountOrders :one
 SELECT COUNT(*)
 FROM orders o
@@ -201,6 +408,7 @@ LEFT JOIN users u ON u.id = o.user_id
 WHERE
   (
     sqlc.narg('search')::text IS NULL
+    OR o.order_number ILIKE '%' || sqlc.narg('search') || '%'
     OR o.customer_email ILIKE '%' || sqlc.narg('search') || '%'
     OR o.shipping_name ILIKE '%' || sqlc.narg('search') || '%'
     OR o.billing_name ILIKE '%' || sqlc.narg('search') || '%'
@@ -223,3 +431,216 @@ WHERE
     OR o.created_at <= sqlc.narg('date_to')
   );
 
+-- name: GetOrderByIdForUpdate :one
+SELECT * FROM orders
+WHERE id = sqlc.arg(id)
+FOR UPDATE;
+
+-- name: UpdateOrderPaymentStatus :one
+UPDATE orders
+SET payment_status = sqlc.arg(payment_status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListOrdersByUserId :many
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.shipping_price,
+  o.created_at,
+  o.updated_at,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name,
+  (
+    SELECT COALESCE(SUM(ov.quantity), 0)
+    FROM orders_variants ov
+    WHERE ov.order_id = o.id
+  )::int AS item_count
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.user_id = $1
+ORDER BY o.created_at DESC, o.id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetOrderItemsForUserOrders :many
+SELECT
+  ov.order_id,
+  ov.product_variant_id,
+  ov.quantity,
+  ov.price_per_item,
+  pv.sku,
+  pv.variant_name AS variant_name,
+  pv.price,
+  pv.image_url,
+  p.name AS product_name
+  FROM orders_variants ov
+  JOIN product_variants pv ON pv.id = ov.product_variant_id
+  JOIN products p ON p.id = pv.product_id
+  WHERE ov.order_id IN (
+    SELECT o.id
+    FROM orders o
+    WHERE o.user_id = $1
+    ORDER BY o.created_at DESC, o.id DESC
+    LIMIT $2
+    OFFSET $3
+  );
+
+-- name: CountOrdersByUserId :one
+SELECT COUNT(*)
+FROM orders
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: GetUserOrderById :one
+SELECT
+  o.id,
+  o.order_number,
+  o.user_id,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.created_at,
+  o.updated_at,
+  o.customer_email,
+  o.shipping_name,
+  o.shipping_address,
+  o.shipping_city,
+  o.shipping_postal_code,
+  o.shipping_phone,
+  o.billing_name,
+  o.billing_address,
+  o.billing_city,
+  o.billing_postal_code,
+  o.shipping_option_id,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.payment_option_id,
+  o.shipping_country_id,
+  o.billing_country_id,
+  o.customer_note,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.id = sqlc.arg(id)
+  AND o.user_id = sqlc.arg(user_id);
+
+-- name: GetOrderStatusByNumberAndEmail :one
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.created_at,
+  o.updated_at,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.order_number = sqlc.arg(order_number)
+  AND LOWER(o.customer_email) = LOWER(sqlc.arg(customer_email));
+
+-- name: GetOrderStatusById :one
+SELECT
+  o.id,
+  o.order_number,
+  o.status,
+  o.payment_status,
+  o.total_price,
+  o.currency,
+  o.shipping_price,
+  o.tax_total,
+  o.prices_include_tax,
+  o.vat_id,
+  o.reverse_charge,
+  o.coupon_code,
+  o.discount_total,
+  o.created_at,
+  o.updated_at,
+  s.name AS shipping_method_name,
+  p.name AS payment_method_name
+FROM
+  orders o
+LEFT JOIN shipping_options s ON s.id = o.shipping_option_id
+LEFT JOIN payment_options p ON p.id = o.payment_option_id
+WHERE o.id = sqlc.arg(id);
+
+-- name: UpdateOrderAddresses :one
+UPDATE orders
+SET
+    shipping_name = sqlc.arg(shipping_name),
+    shipping_address = sqlc.arg(shipping_address),
+    shipping_city = sqlc.arg(shipping_city),
+    shipping_postal_code = sqlc.arg(shipping_postal_code),
+    shipping_country_id = sqlc.arg(shipping_country_id),
+    shipping_phone = sqlc.arg(shipping_phone),
+    billing_name = sqlc.arg(billing_name),
+    billing_address = sqlc.arg(billing_address),
+    billing_city = sqlc.arg(billing_city),
+    billing_postal_code = sqlc.arg(billing_postal_code),
+    billing_country_id = sqlc.arg(billing_country_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateOrderShippingAndTotal :one
+UPDATE orders
+SET
+    shipping_option_id = sqlc.arg(shipping_option_id),
+    shipping_price = sqlc.arg(shipping_price),
+    total_price = sqlc.arg(total_price),
+    tax_total = sqlc.arg(tax_total),
+    discount_total = sqlc.arg(discount_total)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateOrderReverseCharge :one
+UPDATE orders
+SET reverse_charge = sqlc.arg(reverse_charge)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateOrderPaymentReference :one
+UPDATE orders
+SET
+    payment_provider = sqlc.arg(payment_provider),
+    payment_reference = sqlc.arg(payment_reference)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetOrderByPaymentReferenceForUpdate :one
+SELECT * FROM orders
+WHERE payment_provider = sqlc.arg(payment_provider)
+  AND payment_reference = sqlc.arg(payment_reference)
+FOR UPDATE;
+
+-- name: GetUnpaidOrderIdsCreatedBefore :many
+SELECT id FROM orders
+WHERE status = 'pending'
+  AND payment_status IN ('pending', 'failed')
+  AND created_at < sqlc.arg(created_before)
+ORDER BY created_at ASC;
@@ -3,17 +3,25 @@ SELECT * FROM payment_options
 ORDER BY sort_order ASC;
 
 -- name: GetActivePaymentOptions :many
-SELECT * FROM payment_options
+SELECT id, name, description, is_active, sort_order, provider, created_at, updated_at
+FROM payment_options
 WHERE is_active = true
 ORDER BY sort_order ASC;
 
+-- name: GetPaymentOptionsByProvider :many
+SELECT * FROM payment_options
+WHERE provider = sqlc.arg(provider)
+ORDER BY sort_order ASC;
+
 -- name: CreatePaymentOption :one
-INSERT INTO payment_options (name, description, is_active, sort_order)
+INSERT INTO payment_options (name, description, is_active, sort_order, provider, provider_config)
 VALUES(
     sqlc.arg(name),
     sqlc.arg(description),
     sqlc.arg(is_active),
-    sqlc.arg(sort_order)
+    sqlc.arg(sort_order),
+    sqlc.arg(provider),
+    sqlc.arg(provider_config)
 )
 RETURNING *;
 
@@ -25,7 +33,9 @@ UPDATE payment_options
 SET name = sqlc.arg(name),
 description = sqlc.arg(description),
 sort_order = sqlc.arg(sort_order),
-is_active = sqlc.arg(is_active)
+is_active = sqlc.arg(is_active),
+provider = sqlc.arg(provider),
+provider_config = sqlc.arg(provider_config)
 where id = sqlc.arg(id);
 
 -- name: DeletePaymentOption :exec
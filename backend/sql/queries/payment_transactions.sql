@@ -0,0 +1,65 @@
+-- name: CreatePaymentTransaction :one
+INSERT INTO payment_transactions (order_id, provider, type, status, amount, currency, provider_reference, raw_response, error_message, created_by, idempotency_key)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(provider),
+    sqlc.arg(type),
+    sqlc.arg(status),
+    sqlc.arg(amount),
+    sqlc.arg(currency),
+    sqlc.arg(provider_reference),
+    sqlc.arg(raw_response),
+    sqlc.arg(error_message),
+    sqlc.arg(created_by),
+    sqlc.narg(idempotency_key)
+)
+RETURNING *;
+
+-- name: GetPaymentTransactionsByOrderId :many
+SELECT
+  pt.id,
+  pt.order_id,
+  pt.provider,
+  pt.type,
+  pt.status,
+  pt.amount,
+  pt.currency,
+  pt.provider_reference,
+  pt.raw_response,
+  pt.error_message,
+  pt.created_by,
+  pt.created_at,
+  pt.updated_at,
+  u.email AS created_by_email
+FROM payment_transactions pt
+LEFT JOIN users u ON u.id = pt.created_by
+WHERE pt.order_id = sqlc.arg(order_id)
+ORDER BY pt.created_at ASC;
+
+-- name: GetPaymentTransactionById :one
+SELECT * FROM payment_transactions
+WHERE id = sqlc.arg(id);
+
+-- name: SettlePaymentTransaction :one
+UPDATE payment_transactions
+SET status = sqlc.arg(status),
+    provider_reference = COALESCE(sqlc.narg(provider_reference), provider_reference),
+    raw_response = sqlc.arg(raw_response),
+    error_message = sqlc.narg(error_message)
+WHERE id = sqlc.arg(id)
+  AND status = 'pending'
+RETURNING *;
+
+-- name: GetPendingPaymentOperations :many
+SELECT * FROM payment_transactions
+WHERE status = 'pending'
+  AND idempotency_key IS NOT NULL
+  AND created_at < sqlc.arg(created_before)
+ORDER BY created_at ASC;
+
+-- name: GetPendingPaymentOperationsByOrderId :many
+SELECT * FROM payment_transactions
+WHERE order_id = sqlc.arg(order_id)
+  AND status = 'pending'
+  AND idempotency_key IS NOT NULL
+ORDER BY created_at ASC;
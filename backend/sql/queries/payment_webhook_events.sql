@@ -0,0 +1,19 @@
+-- name: CreatePaymentWebhookEvent :one
+INSERT INTO payment_webhook_events (provider, event_id, event_type, reference, payload)
+VALUES (
+    sqlc.arg(provider),
+    sqlc.arg(event_id),
+    sqlc.arg(event_type),
+    sqlc.arg(reference),
+    sqlc.arg(payload)
+)
+ON CONFLICT (provider, event_id) DO NOTHING
+RETURNING *;
+
+-- name: UpdatePaymentWebhookEventOutcome :exec
+UPDATE payment_webhook_events
+SET
+    order_id = sqlc.arg(order_id),
+    outcome = sqlc.arg(outcome),
+    processed_at = NOW()
+WHERE id = sqlc.arg(id);
@@ -67,13 +67,14 @@ SELECT * FROM product_variants WHERE product_id = sqlc.arg(product_id);
 DELETE FROM products WHERE id = $1;
 
 -- name: CreateProduct :one
-INSERT INTO products (name, slug, description, image_url, category_id)
+INSERT INTO products (name, slug, description, image_url, category_id, tax_class_id)
 VALUES (
   sqlc.arg(name),
   sqlc.arg(slug),
   sqlc.arg(description),
   sqlc.arg(image_url),
-  sqlc.arg(category_id)
+  sqlc.arg(category_id),
+  sqlc.arg(tax_class_id)
 )
 RETURNING *;
 
@@ -85,6 +86,7 @@ SET
   description = sqlc.arg(description),
   category_id = sqlc.arg(category_id),
     image_url = sqlc.arg(image_url),
+    tax_class_id = sqlc.arg(tax_class_id),
     updated_at = NOW()
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -175,4 +177,9 @@ UPDATE product_variants
 SET stock_quantity = stock_quantity - sqlc.arg(quantity)
 WHERE id = sqlc.arg(variant_id)
 AND stock_quantity >= sqlc.arg(quantity)
-RETURNING stock_quantity;
\ No newline at end of file
+RETURNING stock_quantity;
+
+-- name: IncreaseVariantStock :exec
+UPDATE product_variants
+SET stock_quantity = stock_quantity + sqlc.arg(quantity)
+WHERE id = sqlc.arg(variant_id);
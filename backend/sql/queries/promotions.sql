@@ -0,0 +1,120 @@
+-- name: ListPromotions :many
+SELECT * FROM promotions
+ORDER BY priority DESC, created_at ASC;
+
+-- name: GetActivePromotions :many
+SELECT * FROM promotions
+WHERE is_active = TRUE
+  AND (starts_at IS NULL OR starts_at <= sqlc.arg(now)::timestamp)
+  AND (ends_at IS NULL OR ends_at > sqlc.arg(now)::timestamp)
+ORDER BY priority DESC, created_at ASC;
+
+-- name: GetPromotionById :one
+SELECT * FROM promotions
+WHERE id = sqlc.arg(id);
+
+-- name: CreatePromotion :one
+INSERT INTO promotions (
+  name,
+  description,
+  type,
+  priority,
+  percent_off,
+  amount_off,
+  min_subtotal,
+  buy_quantity,
+  get_quantity,
+  bundle_price,
+  starts_at,
+  ends_at,
+  is_active
+)
+VALUES (
+  sqlc.arg(name),
+  sqlc.arg(description),
+  sqlc.arg(type),
+  sqlc.arg(priority),
+  sqlc.arg(percent_off),
+  sqlc.arg(amount_off),
+  sqlc.arg(min_subtotal),
+  sqlc.arg(buy_quantity),
+  sqlc.arg(get_quantity),
+  sqlc.arg(bundle_price),
+  sqlc.arg(starts_at),
+  sqlc.arg(ends_at),
+  sqlc.arg(is_active)
+)
+RETURNING *;
+
+-- name: UpdatePromotion :one
+UPDATE promotions
+SET
+  name = sqlc.arg(name),
+  description = sqlc.arg(description),
+  type = sqlc.arg(type),
+  priority = sqlc.arg(priority),
+  percent_off = sqlc.arg(percent_off),
+  amount_off = sqlc.arg(amount_off),
+  min_subtotal = sqlc.arg(min_subtotal),
+  buy_quantity = sqlc.arg(buy_quantity),
+  get_quantity = sqlc.arg(get_quantity),
+  bundle_price = sqlc.arg(bundle_price),
+  starts_at = sqlc.arg(starts_at),
+  ends_at = sqlc.arg(ends_at),
+  is_active = sqlc.arg(is_active)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeletePromotion :execrows
+DELETE FROM promotions
+WHERE id = sqlc.arg(id);
+
+-- name: GetPromotionProducts :many
+SELECT * FROM promotion_products
+WHERE promotion_id = sqlc.arg(promotion_id)
+ORDER BY role ASC;
+
+-- name: GetPromotionCategories :many
+SELECT * FROM promotion_categories
+WHERE promotion_id = sqlc.arg(promotion_id)
+ORDER BY role ASC;
+
+-- name: AddPromotionProduct :exec
+INSERT INTO promotion_products (promotion_id, product_id, role, quantity)
+VALUES (sqlc.arg(promotion_id), sqlc.arg(product_id), sqlc.arg(role), sqlc.arg(quantity))
+ON CONFLICT (promotion_id, product_id, role) DO UPDATE
+SET quantity = EXCLUDED.quantity;
+
+-- name: AddPromotionCategory :exec
+INSERT INTO promotion_categories (promotion_id, category_id, role)
+VALUES (sqlc.arg(promotion_id), sqlc.arg(category_id), sqlc.arg(role))
+ON CONFLICT DO NOTHING;
+
+-- name: DeletePromotionProducts :exec
+DELETE FROM promotion_products
+WHERE promotion_id = sqlc.arg(promotion_id);
+
+-- name: DeletePromotionCategories :exec
+DELETE FROM promotion_categories
+WHERE promotion_id = sqlc.arg(promotion_id);
+
+-- name: CreateOrderDiscountLine :one
+INSERT INTO order_discount_lines (order_id, promotion_id, name, explanation, amount, position)
+VALUES (
+  sqlc.arg(order_id),
+  sqlc.arg(promotion_id),
+  sqlc.arg(name),
+  sqlc.arg(explanation),
+  sqlc.arg(amount),
+  sqlc.arg(position)
+)
+RETURNING *;
+
+-- name: GetOrderDiscountLines :many
+SELECT * FROM order_discount_lines
+WHERE order_id = sqlc.arg(order_id)
+ORDER BY position ASC;
+
+-- name: DeleteOrderDiscountLines :exec
+DELETE FROM order_discount_lines
+WHERE order_id = sqlc.arg(order_id);
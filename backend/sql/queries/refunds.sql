@@ -0,0 +1,104 @@
+-- name: CreateRefund :one
+INSERT INTO refunds (order_id, amount, shipping_amount, reason, restock, created_by)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(amount),
+    sqlc.arg(shipping_amount),
+    sqlc.arg(reason),
+    sqlc.arg(restock),
+    sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: AddRefundVariant :one
+INSERT INTO refunds_variants (refund_id, order_id, product_variant_id, quantity, amount)
+VALUES (
+    sqlc.arg(refund_id),
+    sqlc.arg(order_id),
+    sqlc.arg(product_variant_id),
+    sqlc.arg(quantity),
+    sqlc.arg(amount)
+)
+RETURNING *;
+
+-- name: GetRefundsByOrderId :many
+SELECT
+  r.id,
+  r.order_id,
+  r.amount,
+  r.shipping_amount,
+  r.reason,
+  r.restock,
+  r.created_by,
+  r.created_at,
+  u.email AS created_by_email,
+  pt.status AS payment_status
+FROM refunds r
+LEFT JOIN users u ON u.id = r.created_by
+LEFT JOIN payment_transactions pt ON pt.id = r.payment_transaction_id
+WHERE r.order_id = sqlc.arg(order_id)
+ORDER BY r.created_at ASC;
+
+-- name: GetRefundVariantsByOrderId :many
+SELECT
+  rv.refund_id,
+  rv.product_variant_id,
+  rv.quantity,
+  rv.amount,
+  pv.sku,
+  pv.variant_name,
+  p.name AS product_name
+FROM refunds_variants rv
+JOIN product_variants pv ON pv.id = rv.product_variant_id
+JOIN products p ON p.id = pv.product_id
+WHERE rv.order_id = sqlc.arg(order_id);
+
+-- name: GetRefundedQuantitiesByOrderId :many
+SELECT
+  rv.product_variant_id,
+  SUM(rv.quantity)::int AS quantity
+FROM refunds_variants rv
+JOIN refunds r ON r.id = rv.refund_id
+LEFT JOIN payment_transactions pt ON pt.id = r.payment_transaction_id
+WHERE rv.order_id = sqlc.arg(order_id)
+  AND pt.status IS DISTINCT FROM 'failed'
+GROUP BY rv.product_variant_id;
+
+-- name: GetOrderRefundTotals :one
+SELECT
+  COALESCE(SUM(r.amount), 0)::numeric AS refunded_amount,
+  COALESCE(SUM(r.shipping_amount), 0)::numeric AS refunded_shipping
+FROM refunds r
+LEFT JOIN payment_transactions pt ON pt.id = r.payment_transaction_id
+WHERE r.order_id = sqlc.arg(order_id)
+  AND pt.status IS DISTINCT FROM 'failed';
+
+-- name: GetRestockedQuantitiesByOrderId :many
+SELECT
+  restocked.product_variant_id,
+  SUM(restocked.quantity)::int AS quantity
+FROM (
+  SELECT rv.product_variant_id, rv.quantity
+  FROM refunds_variants rv
+  JOIN refunds r ON r.id = rv.refund_id
+  LEFT JOIN payment_transactions pt ON pt.id = r.payment_transaction_id
+  WHERE rv.order_id = sqlc.arg(order_id)
+    AND r.restock
+    AND pt.status IS DISTINCT FROM 'failed'
+  UNION ALL
+  SELECT rtv.product_variant_id, rtv.quantity
+  FROM returns_variants rtv
+  JOIN returns rt ON rt.id = rtv.return_id
+  WHERE rtv.order_id = sqlc.arg(order_id)
+    AND rt.restocked
+) restocked
+GROUP BY restocked.product_variant_id;
+
+-- name: SetRefundPaymentTransaction :exec
+UPDATE refunds
+SET payment_transaction_id = sqlc.arg(payment_transaction_id)
+WHERE id = sqlc.arg(id);
+
+-- name: GetRefundByPaymentTransactionId :one
+SELECT * FROM refunds
+WHERE payment_transaction_id = sqlc.arg(payment_transaction_id);
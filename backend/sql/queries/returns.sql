@@ -0,0 +1,79 @@
+-- name: CreateReturn :one
+INSERT INTO returns (order_id, reason, created_by)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(reason),
+    sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: AddReturnVariant :one
+INSERT INTO returns_variants (return_id, order_id, product_variant_id, quantity)
+VALUES (
+    sqlc.arg(return_id),
+    sqlc.arg(order_id),
+    sqlc.arg(product_variant_id),
+    sqlc.arg(quantity)
+)
+RETURNING *;
+
+-- name: GetReturnByIdForUpdate :one
+SELECT * FROM returns
+WHERE id = sqlc.arg(id) AND order_id = sqlc.arg(order_id)
+FOR UPDATE;
+
+-- name: GetReturnVariants :many
+SELECT * FROM returns_variants
+WHERE return_id = sqlc.arg(return_id);
+
+-- name: UpdateReturn :one
+UPDATE returns
+SET
+    status = sqlc.arg(status),
+    resolution_note = sqlc.arg(resolution_note),
+    restocked = sqlc.arg(restocked),
+    refund_id = sqlc.arg(refund_id),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetReturnsByOrderId :many
+SELECT
+  r.id,
+  r.order_id,
+  r.status,
+  r.reason,
+  r.resolution_note,
+  r.restocked,
+  r.refund_id,
+  r.created_by,
+  r.created_at,
+  r.updated_at,
+  u.email AS created_by_email
+FROM returns r
+LEFT JOIN users u ON u.id = r.created_by
+WHERE r.order_id = sqlc.arg(order_id)
+ORDER BY r.created_at ASC;
+
+-- name: GetReturnVariantsByOrderId :many
+SELECT
+  rv.return_id,
+  rv.product_variant_id,
+  rv.quantity,
+  pv.sku,
+  pv.variant_name,
+  p.name AS product_name
+FROM returns_variants rv
+JOIN product_variants pv ON pv.id = rv.product_variant_id
+JOIN products p ON p.id = pv.product_id
+WHERE rv.order_id = sqlc.arg(order_id);
+
+-- name: GetReturnedQuantitiesByOrderId :many
+SELECT
+  rv.product_variant_id,
+  SUM(rv.quantity)::int AS quantity
+FROM returns_variants rv
+JOIN returns r ON r.id = rv.return_id
+WHERE rv.order_id = sqlc.arg(order_id)
+  AND r.status <> 'rejected'
+GROUP BY rv.product_variant_id;
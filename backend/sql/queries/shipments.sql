@@ -0,0 +1,58 @@
+-- name: CreateShipment :one
+INSERT INTO shipments (order_id, carrier, tracking_number, tracking_url_template, shipped_at, created_by)
+VALUES (
+    sqlc.arg(order_id),
+    sqlc.arg(carrier),
+    sqlc.arg(tracking_number),
+    sqlc.arg(tracking_url_template),
+    sqlc.arg(shipped_at),
+    sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: UpdateShipment :one
+UPDATE shipments
+SET
+    carrier = sqlc.arg(carrier),
+    tracking_number = sqlc.arg(tracking_number),
+    tracking_url_template = sqlc.arg(tracking_url_template),
+    shipped_at = COALESCE(sqlc.narg(shipped_at), shipped_at),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND order_id = sqlc.arg(order_id)
+RETURNING *;
+
+-- name: AddShipmentVariant :one
+INSERT INTO shipments_variants (shipment_id, order_id, product_variant_id, quantity)
+VALUES (
+    sqlc.arg(shipment_id),
+    sqlc.arg(order_id),
+    sqlc.arg(product_variant_id),
+    sqlc.arg(quantity)
+)
+RETURNING *;
+
+-- name: GetShipmentsByOrderId :many
+SELECT * FROM shipments
+WHERE order_id = sqlc.arg(order_id)
+ORDER BY shipped_at ASC, created_at ASC;
+
+-- name: GetShipmentVariantsByOrderId :many
+SELECT
+  sv.shipment_id,
+  sv.product_variant_id,
+  sv.quantity,
+  pv.sku,
+  pv.variant_name,
+  p.name AS product_name
+FROM shipments_variants sv
+JOIN product_variants pv ON pv.id = sv.product_variant_id
+JOIN products p ON p.id = pv.product_id
+WHERE sv.order_id = sqlc.arg(order_id);
+
+-- name: GetShippedQuantitiesByOrderId :many
+SELECT
+  product_variant_id,
+  SUM(quantity)::int AS quantity
+FROM shipments_variants
+WHERE order_id = sqlc.arg(order_id)
+GROUP BY product_variant_id;
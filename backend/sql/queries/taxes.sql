@@ -0,0 +1,113 @@
+-- name: GetTaxClasses :many
+SELECT * FROM tax_classes
+ORDER BY name ASC;
+
+-- name: GetTaxClassById :one
+SELECT * FROM tax_classes
+WHERE id = sqlc.arg(id);
+
+-- name: CreateTaxClass :one
+INSERT INTO tax_classes (name)
+VALUES (sqlc.arg(name))
+RETURNING *;
+
+-- name: UpdateTaxClass :one
+UPDATE tax_classes
+SET name = sqlc.arg(name)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteTaxClass :execrows
+DELETE FROM tax_classes
+WHERE id = sqlc.arg(id);
+
+-- name: ListTaxRates :many
+SELECT
+  tr.id,
+  tr.country_id,
+  c.name AS country_name,
+  c.iso_code AS country_iso_code,
+  tr.tax_class_id,
+  tc.name AS tax_class_name,
+  tr.name,
+  tr.rate,
+  tr.created_at,
+  tr.updated_at
+FROM tax_rates tr
+JOIN countries c ON c.id = tr.country_id
+LEFT JOIN tax_classes tc ON tc.id = tr.tax_class_id
+WHERE sqlc.narg(country_id)::uuid IS NULL OR tr.country_id = sqlc.narg(country_id)
+ORDER BY c.name ASC, tc.name ASC NULLS FIRST;
+
+-- name: GetTaxRatesByCountryId :many
+SELECT * FROM tax_rates
+WHERE country_id = sqlc.arg(country_id);
+
+-- name: CreateTaxRate :one
+INSERT INTO tax_rates (country_id, tax_class_id, name, rate)
+VALUES (sqlc.arg(country_id), sqlc.arg(tax_class_id), sqlc.arg(name), sqlc.arg(rate))
+RETURNING *;
+
+-- name: UpdateTaxRate :one
+UPDATE tax_rates
+SET
+  tax_class_id = sqlc.arg(tax_class_id),
+  name = sqlc.arg(name),
+  rate = sqlc.arg(rate)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteTaxRate :execrows
+DELETE FROM tax_rates
+WHERE id = sqlc.arg(id);
+
+-- name: GetCartTaxItems :many
+SELECT
+  cv.product_variant_id,
+  p.id AS product_id,
+  p.category_id,
+  cv.quantity,
+  cv.price_per_item,
+  (cv.quantity * cv.price_per_item) AS total_price,
+  COALESCE(p.tax_class_id, c.tax_class_id) AS tax_class_id
+FROM carts_variants cv
+JOIN product_variants v ON v.id = cv.product_variant_id
+JOIN products p ON p.id = v.product_id
+JOIN categories c ON c.id = p.category_id
+WHERE cv.cart_id = sqlc.arg(cart_id);
+
+-- name: GetOrderTaxItems :many
+SELECT
+  ov.product_variant_id,
+  p.id AS product_id,
+  p.category_id,
+  ov.quantity,
+  ov.price_per_item,
+  ov.total_price,
+  COALESCE(p.tax_class_id, c.tax_class_id) AS tax_class_id
+FROM orders_variants ov
+JOIN product_variants v ON v.id = ov.product_variant_id
+JOIN products p ON p.id = v.product_id
+JOIN categories c ON c.id = p.category_id
+WHERE ov.order_id = sqlc.arg(order_id);
+
+-- name: CreateOrderTaxLine :one
+INSERT INTO order_tax_lines (order_id, name, rate, is_shipping, taxable_amount, tax_amount)
+VALUES (
+  sqlc.arg(order_id),
+  sqlc.arg(name),
+  sqlc.arg(rate),
+  sqlc.arg(is_shipping),
+  sqlc.arg(taxable_amount),
+  sqlc.arg(tax_amount)
+)
+RETURNING *;
+
+-- name: GetOrderTaxLines :many
+SELECT * FROM order_tax_lines
+WHERE order_id = sqlc.arg(order_id)
+ORDER BY is_shipping ASC, rate DESC, name ASC;
+
+-- name: DeleteOrderTaxLines :exec
+DELETE FROM order_tax_lines
+WHERE order_id = sqlc.arg(order_id);
@@ -21,7 +21,8 @@ INSERT INTO users_addresses (
     is_shipping, 
     is_shipping_default,
     is_billing,
-    is_billing_default
+    is_billing_default,
+    vat_id
 )
 VALUES (
     sqlc.arg(user_id), 
@@ -33,7 +34,8 @@ VALUES (
     sqlc.arg(is_shipping), 
     sqlc.arg(is_shipping_default),
     sqlc.arg(is_billing),
-    sqlc.arg(is_billing_default)
+    sqlc.arg(is_billing_default),
+    sqlc.arg(vat_id)
 )
 RETURNING *;
 
@@ -47,7 +49,8 @@ SET address = sqlc.arg(address),
     is_shipping = sqlc.arg(is_shipping),
     is_shipping_default = sqlc.arg(is_shipping_default),
     is_billing = sqlc.arg(is_billing),
-    is_billing_default = sqlc.arg(is_billing_default)
+    is_billing_default = sqlc.arg(is_billing_default),
+    vat_id = sqlc.arg(vat_id)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 -- name: DeleteUserAddress :exec
@@ -0,0 +1,23 @@
+-- +goose Up
+
+CREATE TYPE order_status_field AS ENUM ('status', 'payment_status');
+
+CREATE TABLE order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    field order_status_field NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_changed_by FOREIGN KEY (changed_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history (order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_order_status_history_order_id;
+DROP TABLE IF EXISTS order_status_history;
+DROP TYPE IF EXISTS order_status_field;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'partially_refunded' AFTER 'paid';
+
+-- +goose Down
+
+UPDATE orders SET payment_status = 'paid' WHERE payment_status = 'partially_refunded';
+
+ALTER TYPE payment_status RENAME TO payment_status_old;
+
+CREATE TYPE payment_status AS ENUM (
+  'pending',
+  'paid',
+  'failed',
+  'refunded'
+);
+
+ALTER TABLE orders
+ALTER COLUMN payment_status DROP DEFAULT,
+ALTER COLUMN payment_status TYPE payment_status USING payment_status::text::payment_status,
+ALTER COLUMN payment_status SET DEFAULT 'pending';
+
+DROP TYPE payment_status_old;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount > 0),
+    shipping_amount NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (shipping_amount >= 0),
+    reason TEXT,
+    restock BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE refunds_variants (
+    refund_id UUID NOT NULL,
+    order_id UUID NOT NULL,
+    product_variant_id UUID NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    amount NUMERIC(10, 2) NOT NULL,
+    PRIMARY KEY (refund_id, product_variant_id),
+    CONSTRAINT fk_refund FOREIGN KEY (refund_id) REFERENCES refunds (id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_variant FOREIGN KEY (order_id, product_variant_id) REFERENCES orders_variants (order_id, product_variant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds (order_id);
+CREATE INDEX idx_refunds_variants_order_id ON refunds_variants (order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_refunds_variants_order_id;
+DROP INDEX IF EXISTS idx_refunds_order_id;
+DROP TABLE IF EXISTS refunds_variants;
+DROP TABLE IF EXISTS refunds;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TABLE shipments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    carrier TEXT NOT NULL,
+    tracking_number TEXT,
+    tracking_url_template TEXT,
+    shipped_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE shipments_variants (
+    shipment_id UUID NOT NULL,
+    order_id UUID NOT NULL,
+    product_variant_id UUID NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (shipment_id, product_variant_id),
+    CONSTRAINT fk_shipment FOREIGN KEY (shipment_id) REFERENCES shipments (id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_variant FOREIGN KEY (order_id, product_variant_id) REFERENCES orders_variants (order_id, product_variant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_shipments_order_id ON shipments (order_id);
+CREATE INDEX idx_shipments_variants_order_id ON shipments_variants (order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_shipments_variants_order_id;
+DROP INDEX IF EXISTS idx_shipments_order_id;
+DROP TABLE IF EXISTS shipments_variants;
+DROP TABLE IF EXISTS shipments;
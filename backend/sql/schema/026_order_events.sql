@@ -0,0 +1,106 @@
+-- +goose Up
+
+CREATE TYPE order_event_type AS ENUM (
+    'created',
+    'status_changed',
+    'payment_status_changed',
+    'shipment_created',
+    'shipment_updated',
+    'refund_created',
+    'address_updated',
+    'note_added'
+);
+
+CREATE TYPE order_event_actor AS ENUM ('user', 'admin', 'system');
+
+CREATE TABLE order_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    event_type order_event_type NOT NULL,
+    actor_type order_event_actor NOT NULL,
+    actor_id UUID,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_events_order_id ON order_events (order_id, created_at);
+
+-- Events are append-only; only the actor reference may be cleared when a user is deleted.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION prevent_order_event_update()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.id IS DISTINCT FROM OLD.id
+    OR NEW.order_id IS DISTINCT FROM OLD.order_id
+    OR NEW.event_type IS DISTINCT FROM OLD.event_type
+    OR NEW.actor_type IS DISTINCT FROM OLD.actor_type
+    OR NEW.payload IS DISTINCT FROM OLD.payload
+    OR NEW.created_at IS DISTINCT FROM OLD.created_at
+    OR NEW.actor_id IS NOT NULL THEN
+    RAISE EXCEPTION 'order_events is append-only';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER trg_prevent_order_event_update
+BEFORE UPDATE ON order_events
+FOR EACH ROW EXECUTE FUNCTION prevent_order_event_update();
+-- +goose StatementEnd
+
+INSERT INTO order_events (order_id, event_type, actor_type, payload, created_at)
+SELECT id, 'created', 'system', '{}'::jsonb, created_at
+FROM orders;
+
+INSERT INTO order_events (order_id, event_type, actor_type, actor_id, payload, created_at)
+SELECT
+    order_id,
+    CASE field WHEN 'status' THEN 'status_changed'::order_event_type ELSE 'payment_status_changed'::order_event_type END,
+    CASE WHEN changed_by IS NULL THEN 'system'::order_event_actor ELSE 'admin'::order_event_actor END,
+    changed_by,
+    jsonb_build_object('from', from_status, 'to', to_status),
+    created_at
+FROM order_status_history;
+
+DROP TABLE order_status_history;
+DROP TYPE order_status_field;
+
+-- +goose Down
+
+CREATE TYPE order_status_field AS ENUM ('status', 'payment_status');
+
+CREATE TABLE order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    field order_status_field NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_changed_by FOREIGN KEY (changed_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history (order_id);
+
+INSERT INTO order_status_history (order_id, field, from_status, to_status, changed_by, created_at)
+SELECT
+    order_id,
+    CASE event_type WHEN 'status_changed' THEN 'status'::order_status_field ELSE 'payment_status'::order_status_field END,
+    payload->>'from',
+    payload->>'to',
+    actor_id,
+    created_at
+FROM order_events
+WHERE event_type IN ('status_changed', 'payment_status_changed');
+
+DROP TRIGGER IF EXISTS trg_prevent_order_event_update ON order_events;
+DROP FUNCTION IF EXISTS prevent_order_event_update;
+DROP INDEX IF EXISTS idx_order_events_order_id;
+DROP TABLE IF EXISTS order_events;
+DROP TYPE IF EXISTS order_event_actor;
+DROP TYPE IF EXISTS order_event_type;
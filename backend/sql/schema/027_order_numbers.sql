@@ -0,0 +1,66 @@
+-- +goose Up
+
+CREATE TABLE order_number_counters (
+    period INTEGER PRIMARY KEY,
+    last_value BIGINT NOT NULL
+);
+
+ALTER TABLE orders ADD COLUMN order_number TEXT;
+
+-- Existing orders are numbered the way CreateOrder numbers new ones. Goose fills
+-- in ORDER_NUMBER_FORMAT and ORDER_NUMBER_DIGITS from the environment
+-- (scripts/migrateup.sh exports them from .env); unset, the defaults apply.
+-- +goose ENVSUB ON
+CREATE TEMPORARY TABLE order_number_backfill AS
+WITH settings AS (
+    SELECT
+        COALESCE(NULLIF('${ORDER_NUMBER_FORMAT}', ''), 'BZ-{year}-{seq}') AS format,
+        CASE
+            WHEN '${ORDER_NUMBER_DIGITS}' ~ '^[0-9]+$' AND '${ORDER_NUMBER_DIGITS}'::numeric BETWEEN 1 AND 20
+            THEN '${ORDER_NUMBER_DIGITS}'::int
+            ELSE 6
+        END AS digits
+),
+periods AS (
+    SELECT
+        o.id,
+        o.created_at,
+        s.format,
+        s.digits,
+        CASE WHEN s.format LIKE '%{year}%' THEN EXTRACT(YEAR FROM o.created_at)::int ELSE 0 END AS period
+    FROM orders o
+    CROSS JOIN settings s
+)
+SELECT
+    id,
+    format,
+    digits,
+    period,
+    ROW_NUMBER() OVER (PARTITION BY period ORDER BY created_at, id) AS seq
+FROM periods;
+-- +goose ENVSUB OFF
+
+UPDATE orders o
+SET order_number = replace(
+    replace(b.format, '{year}', b.period::text),
+    '{seq}',
+    lpad(b.seq::text, GREATEST(b.digits, length(b.seq::text)), '0')
+)
+FROM order_number_backfill b
+WHERE b.id = o.id;
+
+INSERT INTO order_number_counters (period, last_value)
+SELECT period, COUNT(*)
+FROM order_number_backfill
+GROUP BY period;
+
+DROP TABLE order_number_backfill;
+
+ALTER TABLE orders ALTER COLUMN order_number SET NOT NULL;
+ALTER TABLE orders ADD CONSTRAINT orders_order_number_key UNIQUE (order_number);
+
+-- +goose Down
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_order_number_key;
+ALTER TABLE orders DROP COLUMN IF EXISTS order_number;
+DROP TABLE IF EXISTS order_number_counters;
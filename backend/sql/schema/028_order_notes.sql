@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE orders ADD COLUMN customer_note TEXT;
+
+CREATE TABLE order_notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    is_customer_visible BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_notes_order_id ON order_notes (order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_order_notes_order_id;
+DROP TABLE IF EXISTS order_notes;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_note;
@@ -0,0 +1,25 @@
+-- +goose Up
+
+ALTER TYPE order_event_type ADD VALUE IF NOT EXISTS 'order_edited' AFTER 'address_updated';
+
+-- +goose Down
+
+DELETE FROM order_events WHERE event_type = 'order_edited';
+
+ALTER TYPE order_event_type RENAME TO order_event_type_old;
+
+CREATE TYPE order_event_type AS ENUM (
+    'created',
+    'status_changed',
+    'payment_status_changed',
+    'shipment_created',
+    'shipment_updated',
+    'refund_created',
+    'address_updated',
+    'note_added'
+);
+
+ALTER TABLE order_events
+ALTER COLUMN event_type TYPE order_event_type USING event_type::text::order_event_type;
+
+DROP TYPE order_event_type_old;
@@ -0,0 +1,64 @@
+-- +goose Up
+
+CREATE TYPE return_status AS ENUM ('requested', 'approved', 'received', 'refunded', 'rejected');
+
+CREATE TABLE returns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    status return_status NOT NULL DEFAULT 'requested',
+    reason TEXT NOT NULL,
+    resolution_note TEXT,
+    restocked BOOLEAN NOT NULL DEFAULT FALSE,
+    refund_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_refund FOREIGN KEY (refund_id) REFERENCES refunds (id) ON DELETE SET NULL,
+    CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE returns_variants (
+    return_id UUID NOT NULL,
+    order_id UUID NOT NULL,
+    product_variant_id UUID NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (return_id, product_variant_id),
+    CONSTRAINT fk_return FOREIGN KEY (return_id) REFERENCES returns (id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_variant FOREIGN KEY (order_id, product_variant_id) REFERENCES orders_variants (order_id, product_variant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_returns_order_id ON returns (order_id);
+CREATE INDEX idx_returns_variants_order_id ON returns_variants (order_id);
+
+ALTER TYPE order_event_type ADD VALUE IF NOT EXISTS 'return_created';
+ALTER TYPE order_event_type ADD VALUE IF NOT EXISTS 'return_status_changed';
+
+-- +goose Down
+
+DELETE FROM order_events WHERE event_type IN ('return_created', 'return_status_changed');
+
+ALTER TYPE order_event_type RENAME TO order_event_type_old;
+
+CREATE TYPE order_event_type AS ENUM (
+    'created',
+    'status_changed',
+    'payment_status_changed',
+    'shipment_created',
+    'shipment_updated',
+    'refund_created',
+    'address_updated',
+    'order_edited',
+    'note_added'
+);
+
+ALTER TABLE order_events
+ALTER COLUMN event_type TYPE order_event_type USING event_type::text::order_event_type;
+
+DROP TYPE order_event_type_old;
+
+DROP INDEX IF EXISTS idx_returns_variants_order_id;
+DROP INDEX IF EXISTS idx_returns_order_id;
+DROP TABLE IF EXISTS returns_variants;
+DROP TABLE IF EXISTS returns;
+DROP TYPE IF EXISTS return_status;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE payment_options
+ADD COLUMN provider TEXT NOT NULL DEFAULT 'manual',
+ADD COLUMN provider_config JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE orders
+ADD COLUMN payment_provider TEXT,
+ADD COLUMN payment_reference TEXT;
+
+CREATE INDEX idx_orders_payment_reference ON orders (payment_provider, payment_reference);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_orders_payment_reference;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS payment_reference,
+DROP COLUMN IF EXISTS payment_provider;
+
+ALTER TABLE payment_options
+DROP COLUMN IF EXISTS provider_config,
+DROP COLUMN IF EXISTS provider;
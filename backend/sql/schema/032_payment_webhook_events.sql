@@ -0,0 +1,31 @@
+-- +goose Up
+
+CREATE TYPE payment_webhook_outcome AS ENUM (
+    'received',
+    'processed',
+    'ignored',
+    'unmatched'
+);
+
+CREATE TABLE payment_webhook_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    reference TEXT,
+    order_id UUID,
+    outcome payment_webhook_outcome NOT NULL DEFAULT 'received',
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP,
+    CONSTRAINT uq_payment_webhook_event UNIQUE (provider, event_id),
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_payment_webhook_events_order_id ON payment_webhook_events (order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_payment_webhook_events_order_id;
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TYPE IF EXISTS payment_webhook_outcome;
@@ -0,0 +1,68 @@
+-- +goose Up
+
+CREATE TYPE payment_transaction_type AS ENUM (
+    'authorization',
+    'capture',
+    'void',
+    'refund'
+);
+
+CREATE TYPE payment_transaction_status AS ENUM (
+    'pending',
+    'succeeded',
+    'failed'
+);
+
+CREATE TABLE payment_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL,
+    provider TEXT NOT NULL,
+    type payment_transaction_type NOT NULL,
+    status payment_transaction_status NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount >= 0),
+    currency TEXT NOT NULL,
+    provider_reference TEXT,
+    raw_response JSONB NOT NULL DEFAULT '{}',
+    error_message TEXT,
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT clock_timestamp(),
+    updated_at TIMESTAMP NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_created_by FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_payment_transactions_order_id ON payment_transactions (order_id, created_at);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON payment_transactions
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Seed the ledger from existing orders so derived payment statuses match. Every
+-- order so far was placed in the store currency. Goose fills in STORE_CURRENCY
+-- from the environment (scripts/migrateup.sh exports it from .env); unset, the
+-- application's default of USD applies.
+-- +goose ENVSUB ON
+INSERT INTO payment_transactions (order_id, provider, type, status, amount, currency, provider_reference, created_at, updated_at)
+SELECT id, COALESCE(payment_provider, 'manual'), 'capture', 'succeeded', total_price, COALESCE(NULLIF(upper('${STORE_CURRENCY}'), ''), 'USD'), payment_reference, updated_at, updated_at
+FROM orders
+WHERE payment_status IN ('paid', 'partially_refunded', 'refunded');
+
+INSERT INTO payment_transactions (order_id, provider, type, status, amount, currency, provider_reference, created_at, updated_at)
+SELECT id, COALESCE(payment_provider, 'manual'), 'authorization', 'failed', total_price, COALESCE(NULLIF(upper('${STORE_CURRENCY}'), ''), 'USD'), payment_reference, updated_at, updated_at
+FROM orders
+WHERE payment_status = 'failed';
+
+INSERT INTO payment_transactions (order_id, provider, type, status, amount, currency, provider_reference, created_by, created_at, updated_at)
+SELECT r.order_id, COALESCE(o.payment_provider, 'manual'), 'refund', 'succeeded', r.amount, COALESCE(NULLIF(upper('${STORE_CURRENCY}'), ''), 'USD'), o.payment_reference, r.created_by, r.created_at, r.created_at
+FROM refunds r
+JOIN orders o ON o.id = r.order_id;
+-- +goose ENVSUB OFF
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS set_updated_at ON payment_transactions;
+DROP INDEX IF EXISTS idx_payment_transactions_order_id;
+DROP TABLE IF EXISTS payment_transactions;
+DROP TYPE IF EXISTS payment_transaction_status;
+DROP TYPE IF EXISTS payment_transaction_type;
@@ -0,0 +1,33 @@
+-- +goose Up
+
+ALTER TYPE order_event_type ADD VALUE IF NOT EXISTS 'expired' AFTER 'return_status_changed';
+
+CREATE INDEX idx_orders_unpaid_created_at ON orders (created_at)
+WHERE status = 'pending' AND payment_status IN ('pending', 'failed');
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_orders_unpaid_created_at;
+
+DELETE FROM order_events WHERE event_type = 'expired';
+
+ALTER TYPE order_event_type RENAME TO order_event_type_old;
+
+CREATE TYPE order_event_type AS ENUM (
+    'created',
+    'status_changed',
+    'payment_status_changed',
+    'shipment_created',
+    'shipment_updated',
+    'refund_created',
+    'address_updated',
+    'order_edited',
+    'note_added',
+    'return_created',
+    'return_status_changed'
+);
+
+ALTER TABLE order_events
+ALTER COLUMN event_type TYPE order_event_type USING event_type::text::order_event_type;
+
+DROP TYPE order_event_type_old;
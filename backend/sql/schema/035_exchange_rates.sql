@@ -0,0 +1,43 @@
+-- +goose Up
+
+-- rate is how many units of currency buy one unit of the store currency.
+CREATE TABLE exchange_rates (
+    currency TEXT PRIMARY KEY CHECK (currency ~ '^[A-Z]{3}$'),
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON exchange_rates
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- A NULL currency means the store currency, which is configured outside the
+-- database and was the only currency before this migration.
+ALTER TABLE countries
+ADD COLUMN currency TEXT CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE carts
+ADD COLUMN currency TEXT,
+ADD COLUMN exchange_rate NUMERIC(18, 8) NOT NULL DEFAULT 1 CHECK (exchange_rate > 0);
+
+ALTER TABLE orders
+ADD COLUMN currency TEXT,
+ADD COLUMN exchange_rate NUMERIC(18, 8) NOT NULL DEFAULT 1 CHECK (exchange_rate > 0);
+
+-- +goose Down
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS exchange_rate,
+DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE carts
+DROP COLUMN IF EXISTS exchange_rate,
+DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE countries
+DROP COLUMN IF EXISTS currency;
+
+DROP TRIGGER IF EXISTS set_updated_at ON exchange_rates;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,81 @@
+-- +goose Up
+
+CREATE TABLE tax_classes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON tax_classes
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- A rate without a tax class is the country's standard rate. A rate for a class
+-- replaces it for products in that class.
+CREATE TABLE tax_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    country_id UUID NOT NULL REFERENCES countries(id) ON DELETE CASCADE,
+    tax_class_id UUID REFERENCES tax_classes(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    rate NUMERIC(7, 4) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX tax_rates_country_class_key
+ON tax_rates (country_id, COALESCE(tax_class_id, '00000000-0000-0000-0000-000000000000'));
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON tax_rates
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- A product's own class wins over its category's.
+ALTER TABLE categories
+ADD COLUMN tax_class_id UUID REFERENCES tax_classes(id) ON DELETE SET NULL;
+
+ALTER TABLE products
+ADD COLUMN tax_class_id UUID REFERENCES tax_classes(id) ON DELETE SET NULL;
+
+-- total_price includes tax_total either way; prices_include_tax records whether
+-- the line and shipping prices already did.
+ALTER TABLE orders
+ADD COLUMN tax_total NUMERIC(10, 2) NOT NULL DEFAULT 0,
+ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE order_tax_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    rate NUMERIC(7, 4) NOT NULL,
+    is_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    taxable_amount NUMERIC(10, 2) NOT NULL,
+    tax_amount NUMERIC(10, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_tax_lines_order_id ON order_tax_lines(order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_order_tax_lines_order_id;
+DROP TABLE IF EXISTS order_tax_lines;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS prices_include_tax,
+DROP COLUMN IF EXISTS tax_total;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS tax_class_id;
+
+ALTER TABLE categories
+DROP COLUMN IF EXISTS tax_class_id;
+
+DROP TRIGGER IF EXISTS set_updated_at ON tax_rates;
+DROP INDEX IF EXISTS tax_rates_country_class_key;
+DROP TABLE IF EXISTS tax_rates;
+
+DROP TRIGGER IF EXISTS set_updated_at ON tax_classes;
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,19 @@
+-- +goose Up
+
+ALTER TABLE users_addresses
+ADD COLUMN vat_id TEXT;
+
+-- reverse_charge marks orders zero-rated because the buyer accounts for VAT in
+-- their own EU member state.
+ALTER TABLE orders
+ADD COLUMN vat_id TEXT,
+ADD COLUMN reverse_charge BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS reverse_charge,
+DROP COLUMN IF EXISTS vat_id;
+
+ALTER TABLE users_addresses
+DROP COLUMN IF EXISTS vat_id;
@@ -0,0 +1,88 @@
+-- +goose Up
+
+CREATE TYPE coupon_type AS ENUM ('percentage', 'fixed_amount', 'free_shipping');
+
+-- amount_off and min_subtotal are in the store currency. A coupon without
+-- products or categories applies to the whole cart.
+CREATE TABLE coupons (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code TEXT NOT NULL,
+    description TEXT,
+    type coupon_type NOT NULL,
+    percent_off NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (percent_off >= 0 AND percent_off <= 100),
+    amount_off NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+    min_subtotal NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (min_subtotal >= 0),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    usage_limit INTEGER CHECK (usage_limit > 0),
+    usage_limit_per_customer INTEGER CHECK (usage_limit_per_customer > 0),
+    times_used INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+-- Codes are matched case-insensitively.
+CREATE UNIQUE INDEX coupons_code_key ON coupons (UPPER(code));
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON coupons
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE coupon_products (
+    coupon_id UUID NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, product_id)
+);
+
+CREATE TABLE coupon_categories (
+    coupon_id UUID NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+ALTER TABLE carts
+ADD COLUMN coupon_id UUID REFERENCES coupons(id) ON DELETE SET NULL;
+
+-- coupon_code keeps the code on the order after the coupon is deleted.
+-- discount_total is included in total_price and covers goods and shipping.
+ALTER TABLE orders
+ADD COLUMN coupon_id UUID REFERENCES coupons(id) ON DELETE SET NULL,
+ADD COLUMN coupon_code TEXT,
+ADD COLUMN discount_total NUMERIC(10, 2) NOT NULL DEFAULT 0;
+
+-- One row per order that used a coupon, for the per-customer limit.
+CREATE TABLE coupon_redemptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    coupon_id UUID NOT NULL REFERENCES coupons(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    customer_email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_coupon_redemptions_coupon_id ON coupon_redemptions(coupon_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_coupon_redemptions_coupon_id;
+DROP TABLE IF EXISTS coupon_redemptions;
+
+ALTER TABLE orders
+DROP COLUMN IF EXISTS discount_total,
+DROP COLUMN IF EXISTS coupon_code,
+DROP COLUMN IF EXISTS coupon_id;
+
+ALTER TABLE carts
+DROP COLUMN IF EXISTS coupon_id;
+
+DROP TABLE IF EXISTS coupon_categories;
+DROP TABLE IF EXISTS coupon_products;
+
+DROP TRIGGER IF EXISTS set_updated_at ON coupons;
+DROP INDEX IF EXISTS coupons_code_key;
+DROP TABLE IF EXISTS coupons;
+
+DROP TYPE IF EXISTS coupon_type;
@@ -0,0 +1,86 @@
+-- +goose Up
+
+CREATE TYPE promotion_type AS ENUM ('buy_x_get_y', 'spend_threshold', 'bundle_price');
+
+-- Which side of a buy_x_get_y deal a product or category is on. Bundles and
+-- spend thresholds only use 'buy'.
+CREATE TYPE promotion_target_role AS ENUM ('buy', 'get');
+
+-- Promotions apply to every cart without a code, highest priority first.
+--   buy_x_get_y:     for every buy_quantity targeted items, get_quantity items
+--                    are percent_off cheaper; 3-for-2 is buy 2, get 1 at 100%.
+--   spend_threshold: percent_off or amount_off the targeted items once they
+--                    reach min_subtotal.
+--   bundle_price:    the targeted products, in their quantities, sell together
+--                    for bundle_price.
+-- Amounts are in the store currency. Without targets a promotion covers every
+-- item.
+CREATE TABLE promotions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    description TEXT,
+    type promotion_type NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    percent_off NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (percent_off >= 0 AND percent_off <= 100),
+    amount_off NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+    min_subtotal NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (min_subtotal >= 0),
+    buy_quantity INTEGER NOT NULL DEFAULT 0 CHECK (buy_quantity >= 0),
+    get_quantity INTEGER NOT NULL DEFAULT 0 CHECK (get_quantity >= 0),
+    bundle_price NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (bundle_price >= 0),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON promotions
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- quantity is how many of the product a bundle contains.
+CREATE TABLE promotion_products (
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    role promotion_target_role NOT NULL DEFAULT 'buy',
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    PRIMARY KEY (promotion_id, product_id, role)
+);
+
+CREATE TABLE promotion_categories (
+    promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    role promotion_target_role NOT NULL DEFAULT 'buy',
+    PRIMARY KEY (promotion_id, category_id, role)
+);
+
+-- The discounts an order received, one line per promotion plus one for its
+-- coupon. Their amounts add up to orders.discount_total.
+CREATE TABLE order_discount_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id UUID REFERENCES promotions(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    explanation TEXT NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_discount_lines_order_id ON order_discount_lines(order_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_order_discount_lines_order_id;
+DROP TABLE IF EXISTS order_discount_lines;
+
+DROP TABLE IF EXISTS promotion_categories;
+DROP TABLE IF EXISTS promotion_products;
+
+DROP TRIGGER IF EXISTS set_updated_at ON promotions;
+DROP TABLE IF EXISTS promotions;
+
+DROP TYPE IF EXISTS promotion_target_role;
+DROP TYPE IF EXISTS promotion_type;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- Voids and refunds are put on the ledger as pending before the provider is
+-- called. The idempotency key is sent with every attempt, so an operation that
+-- is retried after a crash or timeout is carried out once.
+ALTER TABLE payment_transactions
+ADD COLUMN idempotency_key TEXT UNIQUE;
+
+CREATE INDEX idx_payment_transactions_pending ON payment_transactions (created_at)
+WHERE status = 'pending' AND idempotency_key IS NOT NULL;
+
+ALTER TABLE refunds
+ADD COLUMN payment_transaction_id UUID REFERENCES payment_transactions (id) ON DELETE SET NULL;
+
+-- +goose Down
+
+ALTER TABLE refunds
+DROP COLUMN IF EXISTS payment_transaction_id;
+
+DROP INDEX IF EXISTS idx_payment_transactions_pending;
+
+ALTER TABLE payment_transactions
+DROP COLUMN IF EXISTS idempotency_key;
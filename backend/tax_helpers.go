@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+const reverseChargeTaxName = "Reverse charge"
+
+// TaxLine adds up everything taxed at one rate, keeping shipping apart from
+// goods. TaxableAmount is always the net amount the tax was charged on.
+type TaxLine struct {
+	Name          string        `json:"name"`
+	Rate          money.Percent `json:"rate"`
+	IsShipping    bool          `json:"is_shipping"`
+	TaxableAmount money.Amount  `json:"taxable_amount"`
+	TaxAmount     money.Amount  `json:"tax_amount"`
+}
+
+type taxableItem struct {
+	TaxClassID uuid.NullUUID
+	Amount     money.Amount
+}
+
+// taxQuote is the tax on a cart or order for one destination. Waived is the
+// tax a reverse charge took out of tax-inclusive prices.
+type taxQuote struct {
+	PricesIncludeTax bool
+	ReverseCharge    bool
+	Lines            []TaxLine
+	Total            money.Amount
+	Waived           money.Amount
+}
+
+// added is what tax adds to the prices: the tax itself when prices are net, or
+// minus any waived tax when they already include it.
+func (q taxQuote) added() money.Amount {
+	if q.PricesIncludeTax {
+		return -q.Waived
+	}
+	return q.Total
+}
+
+// reverseCharged zero-rates a quote for a business buyer who accounts for the
+// VAT in their own member state. Goods and shipping keep one line each so the
+// invoice still shows what was zero-rated.
+func (q taxQuote) reverseCharged() taxQuote {
+	rc := taxQuote{PricesIncludeTax: q.PricesIncludeTax, ReverseCharge: true, Lines: []TaxLine{}}
+
+	goods := TaxLine{Name: reverseChargeTaxName}
+	shipping := TaxLine{Name: reverseChargeTaxName, IsShipping: true}
+	for _, line := range q.Lines {
+		if line.IsShipping {
+			shipping.TaxableAmount += line.TaxableAmount
+		} else {
+			goods.TaxableAmount += line.TaxableAmount
+		}
+		if q.PricesIncludeTax {
+			rc.Waived += line.TaxAmount
+		}
+	}
+
+	for _, line := range []TaxLine{goods, shipping} {
+		if !line.TaxableAmount.IsZero() {
+			rc.Lines = append(rc.Lines, line)
+		}
+	}
+
+	return rc
+}
+
+// countryTaxRates holds a country's standard rate and its per-class overrides.
+type countryTaxRates struct {
+	standard *database.TaxRate
+	classes  map[uuid.UUID]database.TaxRate
+}
+
+func loadCountryTaxRates(ctx context.Context, q *database.Queries, countryID uuid.UUID) (countryTaxRates, error) {
+	rows, err := q.GetTaxRatesByCountryId(ctx, countryID)
+	if err != nil {
+		return countryTaxRates{}, fmt.Errorf("failed to load tax rates: %w", err)
+	}
+
+	rates := countryTaxRates{classes: make(map[uuid.UUID]database.TaxRate, len(rows))}
+	for _, row := range rows {
+		if !row.TaxClassID.Valid {
+			rate := row
+			rates.standard = &rate
+			continue
+		}
+		rates.classes[row.TaxClassID.UUID] = row
+	}
+	return rates, nil
+}
+
+// lookup returns the rate for a tax class, falling back to the standard rate.
+// Countries without any rate are not taxed.
+func (r countryTaxRates) lookup(class uuid.NullUUID) (database.TaxRate, bool) {
+	if class.Valid {
+		if rate, ok := r.classes[class.UUID]; ok {
+			return rate, true
+		}
+	}
+	if r.standard != nil {
+		return *r.standard, true
+	}
+	return database.TaxRate{}, false
+}
+
+// calculateTax groups items and shipping by rate and taxes each group once, so
+// rounding happens per rate rather than per line.
+func calculateTax(rates countryTaxRates, items []taxableItem, shipping money.Amount, taxShipping, pricesIncludeTax bool) taxQuote {
+	type group struct {
+		rateID     uuid.UUID
+		isShipping bool
+	}
+	amounts := map[group]money.Amount{}
+	byID := map[uuid.UUID]database.TaxRate{}
+
+	add := func(rate database.TaxRate, isShipping bool, amount money.Amount) {
+		byID[rate.ID] = rate
+		amounts[group{rateID: rate.ID, isShipping: isShipping}] += amount
+	}
+
+	for _, item := range items {
+		if rate, ok := rates.lookup(item.TaxClassID); ok {
+			add(rate, false, item.Amount)
+		}
+	}
+	if taxShipping && !shipping.IsZero() {
+		if rate, ok := rates.lookup(uuid.NullUUID{}); ok {
+			add(rate, true, shipping)
+		}
+	}
+
+	quote := taxQuote{PricesIncludeTax: pricesIncludeTax, Lines: []TaxLine{}}
+	for g, amount := range amounts {
+		if amount.IsZero() {
+			continue
+		}
+
+		rate := byID[g.rateID]
+		line := TaxLine{Name: rate.Name, Rate: rate.Rate, IsShipping: g.isShipping}
+		if pricesIncludeTax {
+			line.TaxAmount = amount.PercentIncluded(rate.Rate)
+			line.TaxableAmount = amount - line.TaxAmount
+		} else {
+			line.TaxAmount = amount.Percent(rate.Rate)
+			line.TaxableAmount = amount
+		}
+
+		quote.Lines = append(quote.Lines, line)
+		quote.Total += line.TaxAmount
+	}
+
+	sort.Slice(quote.Lines, func(i, j int) bool {
+		a, b := quote.Lines[i], quote.Lines[j]
+		if a.IsShipping != b.IsShipping {
+			return !a.IsShipping
+		}
+		if a.Rate != b.Rate {
+			return a.Rate > b.Rate
+		}
+		return a.Name < b.Name
+	})
+
+	return quote
+}
+
+// untaxedQuote is used where the destination is not known yet.
+func (cfg *apiConfig) untaxedQuote() taxQuote {
+	return taxQuote{PricesIncludeTax: cfg.pricesIncludeTax, Lines: []TaxLine{}}
+}
+
+// pricedLine is a cart or order line with what tax and discounts need to know
+// about it. Amount is the line total.
+type pricedLine struct {
+	VariantID  uuid.UUID
+	ProductID  uuid.UUID
+	CategoryID uuid.UUID
+	TaxClassID uuid.NullUUID
+	Quantity   int32
+	UnitPrice  money.Amount
+	Amount     money.Amount
+}
+
+func loadCartLines(ctx context.Context, q *database.Queries, cartID uuid.UUID) ([]pricedLine, error) {
+	rows, err := q.GetCartTaxItems(ctx, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cart items: %w", err)
+	}
+
+	lines := make([]pricedLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, pricedLine{
+			VariantID:  row.ProductVariantID,
+			ProductID:  row.ProductID,
+			CategoryID: row.CategoryID,
+			TaxClassID: row.TaxClassID,
+			Quantity:   row.Quantity,
+			UnitPrice:  row.PricePerItem,
+			Amount:     row.TotalPrice,
+		})
+	}
+	return lines, nil
+}
+
+func loadOrderLines(ctx context.Context, q *database.Queries, orderID uuid.UUID) ([]pricedLine, error) {
+	rows, err := q.GetOrderTaxItems(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	lines := make([]pricedLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, pricedLine{
+			VariantID:  row.ProductVariantID,
+			ProductID:  row.ProductID,
+			CategoryID: row.CategoryID,
+			TaxClassID: row.TaxClassID,
+			Quantity:   row.Quantity,
+			UnitPrice:  row.PricePerItem,
+			Amount:     row.TotalPrice,
+		})
+	}
+	return lines, nil
+}
+
+// quoteTax charges tax on what is left of the lines and shipping after the
+// discount.
+func (cfg *apiConfig) quoteTax(ctx context.Context, q *database.Queries, countryID uuid.UUID, lines []pricedLine, shipping money.Amount, discount discountQuote, pricesIncludeTax bool) (taxQuote, error) {
+	rates, err := loadCountryTaxRates(ctx, q, countryID)
+	if err != nil {
+		return taxQuote{}, err
+	}
+
+	items := make([]taxableItem, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, taxableItem{TaxClassID: line.TaxClassID, Amount: line.Amount - discount.Items[line.VariantID]})
+	}
+
+	return calculateTax(rates, items, shipping-discount.Shipping, cfg.taxShipping, pricesIncludeTax), nil
+}
+
+// quoteCartTax prices the tax on a cart shipped to a country at today's rates.
+func (cfg *apiConfig) quoteCartTax(ctx context.Context, q *database.Queries, countryID uuid.UUID, lines []pricedLine, shipping money.Amount, discount discountQuote) (taxQuote, error) {
+	return cfg.quoteTax(ctx, q, countryID, lines, shipping, discount, cfg.pricesIncludeTax)
+}
+
+// quoteOrderTax reprices the tax on an order's current lines. The order keeps the
+// tax-inclusive and reverse charge settings it was placed with.
+func (cfg *apiConfig) quoteOrderTax(ctx context.Context, q *database.Queries, order database.Order, countryID uuid.UUID, lines []pricedLine, shipping money.Amount, discount discountQuote) (taxQuote, error) {
+	quote, err := cfg.quoteTax(ctx, q, countryID, lines, shipping, discount, order.PricesIncludeTax)
+	if err != nil {
+		return taxQuote{}, err
+	}
+	if order.ReverseCharge {
+		return quote.reverseCharged(), nil
+	}
+	return quote, nil
+}
+
+// saveOrderTaxLines replaces the tax lines stored on an order.
+func saveOrderTaxLines(ctx context.Context, qtx *database.Queries, orderID uuid.UUID, quote taxQuote) error {
+	if err := qtx.DeleteOrderTaxLines(ctx, orderID); err != nil {
+		return fmt.Errorf("failed to clear tax lines: %w", err)
+	}
+
+	for _, line := range quote.Lines {
+		_, err := qtx.CreateOrderTaxLine(ctx, database.CreateOrderTaxLineParams{
+			OrderID:       orderID,
+			Name:          line.Name,
+			Rate:          line.Rate,
+			IsShipping:    line.IsShipping,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save tax line: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getOrderTaxLines loads the stored tax lines of an order for a response.
+func getOrderTaxLines(ctx context.Context, q *database.Queries, orderID uuid.UUID) ([]TaxLine, error) {
+	rows, err := q.GetOrderTaxLines(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tax lines: %w", err)
+	}
+
+	lines := make([]TaxLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, TaxLine{
+			Name:          row.Name,
+			Rate:          row.Rate,
+			IsShipping:    row.IsShipping,
+			TaxableAmount: row.TaxableAmount,
+			TaxAmount:     row.TaxAmount,
+		})
+	}
+	return lines, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
+	"github.com/google/uuid"
+)
+
+func TestCalculateTax(t *testing.T) {
+	reducedClass := uuid.MustParse("00000000-0000-0000-0000-0000000000c1")
+	standard := database.TaxRate{ID: uuid.New(), Name: "VAT", Rate: 230000}
+	reduced := database.TaxRate{ID: uuid.New(), Name: "Reduced VAT", Rate: 80000, TaxClassID: uuid.NullUUID{UUID: reducedClass, Valid: true}}
+	rates := countryTaxRates{standard: &standard, classes: map[uuid.UUID]database.TaxRate{reducedClass: reduced}}
+
+	standardItem := func(amount money.Amount) taxableItem {
+		return taxableItem{Amount: amount}
+	}
+	reducedItem := func(amount money.Amount) taxableItem {
+		return taxableItem{TaxClassID: uuid.NullUUID{UUID: reducedClass, Valid: true}, Amount: amount}
+	}
+
+	tests := []struct {
+		name             string
+		rates            countryTaxRates
+		items            []taxableItem
+		shipping         money.Amount
+		taxShipping      bool
+		pricesIncludeTax bool
+		want             []TaxLine
+		wantTotal        money.Amount
+	}{
+		{
+			name:        "net prices by rate with shipping last",
+			rates:       rates,
+			items:       []taxableItem{reducedItem(5000), standardItem(10000)},
+			shipping:    1000,
+			taxShipping: true,
+			want: []TaxLine{
+				{Name: "VAT", Rate: 230000, TaxableAmount: 10000, TaxAmount: 2300},
+				{Name: "Reduced VAT", Rate: 80000, TaxableAmount: 5000, TaxAmount: 400},
+				{Name: "VAT", Rate: 230000, IsShipping: true, TaxableAmount: 1000, TaxAmount: 230},
+			},
+			wantTotal: 2930,
+		},
+		{
+			name:             "inclusive prices without taxed shipping",
+			rates:            rates,
+			items:            []taxableItem{standardItem(12300)},
+			shipping:         1000,
+			pricesIncludeTax: true,
+			want: []TaxLine{
+				{Name: "VAT", Rate: 230000, TaxableAmount: 10000, TaxAmount: 2300},
+			},
+			wantTotal: 2300,
+		},
+		{
+			name:  "rounds once per rate",
+			rates: rates,
+			items: []taxableItem{standardItem(2), standardItem(2), standardItem(2)},
+			want: []TaxLine{
+				{Name: "VAT", Rate: 230000, TaxableAmount: 6, TaxAmount: 1},
+			},
+			wantTotal: 1,
+		},
+		{
+			name:  "unknown class falls back to the standard rate",
+			rates: rates,
+			items: []taxableItem{{TaxClassID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, Amount: 1000}},
+			want: []TaxLine{
+				{Name: "VAT", Rate: 230000, TaxableAmount: 1000, TaxAmount: 230},
+			},
+			wantTotal: 230,
+		},
+		{
+			name:        "country without rates",
+			rates:       countryTaxRates{},
+			items:       []taxableItem{standardItem(1000)},
+			shipping:    500,
+			taxShipping: true,
+			want:        []TaxLine{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateTax(tt.rates, tt.items, tt.shipping, tt.taxShipping, tt.pricesIncludeTax)
+			if !slices.Equal(got.Lines, tt.want) {
+				t.Errorf("Lines = %+v, want %+v", got.Lines, tt.want)
+			}
+			if got.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", got.Total, tt.wantTotal)
+			}
+			if got.PricesIncludeTax != tt.pricesIncludeTax {
+				t.Errorf("PricesIncludeTax = %v, want %v", got.PricesIncludeTax, tt.pricesIncludeTax)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -69,8 +70,8 @@ func getUserIDFromContext(ctx context.Context) uuid.UUID {
 func parsePageTemplate(filename string) *template.Template {
 	return template.Must(template.New("").Funcs(template.FuncMap{
 		"sub": func(a, b int) int { return a - b },
-		"mulf": func(a int32, b float64) float64 {
-			return float64(a) * b
+		"mul": func(a int32, b money.Amount) money.Amount {
+			return b.Mul(int64(a))
 		},
 	}).ParseFiles("templates/base.html", filename))
 }
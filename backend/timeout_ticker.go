@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"time"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
 )
 
 func (cfg *apiConfig) startCartExpirationWorker() {
@@ -24,3 +27,83 @@ func (cfg *apiConfig) expireOldCarts() {
 	}
 	log.Printf("clearing abandoned carts completed")
 }
+
+func (cfg *apiConfig) startOrderExpirationWorker() {
+	if cfg.unpaidOrderTimeout <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		for range ticker.C {
+			cfg.expireUnpaidOrders()
+		}
+	}()
+}
+
+// expireUnpaidOrders cancels orders that are still unpaid after the timeout, which
+// returns their stock. A payment still in flight is voided first. Orders with an
+// open authorization are left for an admin to capture or void.
+func (cfg *apiConfig) expireUnpaidOrders() {
+	ctx := context.Background()
+	threshold := time.Now().Add(-cfg.unpaidOrderTimeout)
+
+	ids, err := cfg.db.GetUnpaidOrderIdsCreatedBefore(ctx, threshold)
+	if err != nil {
+		log.Printf("failed to load unpaid orders: %v", err)
+		return
+	}
+
+	expired := 0
+	for _, id := range ids {
+		// A payment that cannot be voided is left to the next run rather than
+		// cancelling an order whose payment might still complete.
+		voided, err := cfg.voidExpiringPayment(ctx, id, threshold)
+		if err != nil {
+			if !errors.Is(err, errOrderNotExpirable) {
+				log.Printf("failed to void payment for expiring order %s: %v", id, err)
+			}
+			continue
+		}
+
+		_, err = cfg.updateOrderInTx(ctx, id, func(qtx *database.Queries, order database.Order) (database.Order, error) {
+			return cfg.expireOrder(ctx, qtx, order, threshold, voided)
+		})
+		if err != nil {
+			if errors.Is(err, errOrderNotExpirable) {
+				continue
+			}
+			log.Printf("failed to expire order %s: %v", id, err)
+			continue
+		}
+		expired++
+	}
+	log.Printf("expiring unpaid orders completed: %d expired", expired)
+}
+
+func (cfg *apiConfig) startPaymentOperationWorker() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			cfg.retryPaymentOperations()
+		}
+	}()
+}
+
+// retryPaymentOperations resends voids and refunds still pending a while after
+// they were queued, because the process stopped before sending them or the
+// provider did not give a clear answer.
+func (cfg *apiConfig) retryPaymentOperations() {
+	ctx := context.Background()
+
+	txns, err := cfg.db.GetPendingPaymentOperations(ctx, time.Now().Add(-paymentOperationRetryDelay))
+	if err != nil {
+		log.Printf("failed to load pending payment operations: %v", err)
+		return
+	}
+
+	for _, txn := range txns {
+		if _, err := cfg.runPaymentOperation(ctx, txn); err != nil {
+			log.Printf("failed to send %s %s for order %s: %v", txn.Type, txn.ID, txn.OrderID, err)
+		}
+	}
+}
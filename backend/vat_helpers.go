@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bzelaznicki/bzCommerce/internal/database"
+	"github.com/bzelaznicki/bzCommerce/internal/vat"
+	"github.com/google/uuid"
+)
+
+// buyerVAT is a buyer's VAT ID after checking, and whether it makes the sale
+// zero-rated under the EU reverse charge.
+type buyerVAT struct {
+	ID            string
+	ReverseCharge bool
+}
+
+// crossBorderEU reports whether a buyer billed in the country is in another EU
+// member state than the store.
+func (cfg *apiConfig) crossBorderEU(countryCode string) bool {
+	return vat.IsEU(cfg.storeCountry) && vat.IsEU(countryCode) && !strings.EqualFold(countryCode, cfg.storeCountry)
+}
+
+// checkBuyerVAT validates a VAT ID against the buyer's billing country. IDs from
+// outside the EU are kept for the invoice as entered. When the configured
+// registry cannot be reached the sale goes ahead with tax charged as usual.
+func (cfg *apiConfig) checkBuyerVAT(ctx context.Context, billingCountry database.Country, vatID string) (buyerVAT, error) {
+	vatID = strings.TrimSpace(vatID)
+	if vatID == "" {
+		return buyerVAT{}, nil
+	}
+	if !vat.IsEU(billingCountry.IsoCode) {
+		return buyerVAT{ID: vatID}, nil
+	}
+
+	id := vat.Normalize(billingCountry.IsoCode, vatID)
+	if err := vat.CheckFormat(billingCountry.IsoCode, id); err != nil {
+		return buyerVAT{}, validationErrorf("invalid VAT ID for %s", billingCountry.Name)
+	}
+
+	if !cfg.crossBorderEU(billingCountry.IsoCode) {
+		return buyerVAT{ID: id}, nil
+	}
+
+	if cfg.vatValidator != nil {
+		if err := cfg.vatValidator.Validate(ctx, id); err != nil {
+			if errors.Is(err, vat.ErrNotRegistered) {
+				return buyerVAT{}, validationError("VAT ID is not registered")
+			}
+			log.Printf("VAT ID %s could not be validated: %v", id, err)
+			return buyerVAT{ID: id}, nil
+		}
+	}
+
+	return buyerVAT{ID: id, ReverseCharge: true}, nil
+}
+
+// defaultBillingVATID returns the VAT ID saved on a user's default billing
+// address when it is in the country being billed.
+func (cfg *apiConfig) defaultBillingVATID(ctx context.Context, userID, countryID uuid.UUID) string {
+	addresses, err := cfg.db.GetUserAddresses(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load addresses for user %s: %v", userID, err)
+		return ""
+	}
+
+	for _, address := range addresses {
+		if address.IsBillingDefault && address.CountryID == countryID {
+			return address.VatID.String
+		}
+	}
+	return ""
+}
+
+// recheckReverseCharge re-evaluates the reverse charge after an order's billing
+// country changed. The VAT ID is only format checked against the new country.
+func (cfg *apiConfig) recheckReverseCharge(ctx context.Context, qtx *database.Queries, order database.Order) (database.Order, error) {
+	if !order.VatID.Valid {
+		return order, nil
+	}
+
+	country, err := qtx.GetCountryById(ctx, order.BillingCountryID)
+	if err != nil {
+		return order, fmt.Errorf("failed to load country: %w", err)
+	}
+
+	reverseCharge := cfg.crossBorderEU(country.IsoCode) && vat.CheckFormat(country.IsoCode, order.VatID.String) == nil
+	if reverseCharge == order.ReverseCharge {
+		return order, nil
+	}
+
+	updated, err := qtx.UpdateOrderReverseCharge(ctx, database.UpdateOrderReverseChargeParams{
+		ReverseCharge: reverseCharge,
+		ID:            order.ID,
+	})
+	if err != nil {
+		return order, fmt.Errorf("failed to update reverse charge: %w", err)
+	}
+	return updated, nil
+}
+
+// applyTo zero-rates a quote when the buyer's VAT ID allows it.
+func (b buyerVAT) applyTo(quote taxQuote) taxQuote {
+	if b.ReverseCharge {
+		return quote.reverseCharged()
+	}
+	return quote
+}